@@ -0,0 +1,45 @@
+// Package universityv1 — клиент и сообщения gRPC-сервиса University. Файлы *.pb.go
+// генерируются из proto/university/v1/university.proto:
+//
+//	protoc -I proto --go_out=. --go_opt=module=hw_5_jwt \
+//		--go-grpc_out=. --go-grpc_opt=module=hw_5_jwt university/v1/university.proto
+//
+// Пример:
+//
+//	conn, err := grpc.NewClient("localhost:9090",
+//		grpc.WithTransportCredentials(insecure.NewCredentials()),
+//		grpc.WithPerRPCCredentials(universityv1.APIKey(key)))
+//	client := universityv1.NewUniversityClient(conn)
+package universityv1
+
+import (
+	"context"
+
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	AuthorizationHeader = "authorization"
+	APIKeyHeader        = "x-api-key"
+)
+
+type metadataCredentials map[string]string
+
+func (m metadataCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return m, nil
+}
+
+// RequireTransportSecurity разрешает передачу без TLS: сервис слушает внутреннюю сеть.
+func (m metadataCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// BearerToken передаёт JWT пользователя, выданный /api/auth/login.
+func BearerToken(token string) credentials.PerRPCCredentials {
+	return metadataCredentials{AuthorizationHeader: "Bearer " + token}
+}
+
+// APIKey передаёт ключ сервиса из GRPC_API_KEYS.
+func APIKey(key string) credentials.PerRPCCredentials {
+	return metadataCredentials{APIKeyHeader: key}
+}
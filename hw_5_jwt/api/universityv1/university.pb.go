@@ -0,0 +1,1575 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: university/v1/university.proto
+
+// Доступ внутренних сервисов к студентам, группам, расписанию и посещаемости.
+// Авторизация — в метаданных: authorization: Bearer <JWT> или x-api-key: <ключ>.
+// Ошибки несут google.rpc.ErrorInfo, reason — тот же код, что в problem+json REST API.
+
+package universityv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AttendanceStatus int32
+
+const (
+	AttendanceStatus_ATTENDANCE_STATUS_UNSPECIFIED AttendanceStatus = 0
+	AttendanceStatus_ATTENDANCE_STATUS_PRESENT     AttendanceStatus = 1
+	AttendanceStatus_ATTENDANCE_STATUS_LATE        AttendanceStatus = 2
+	AttendanceStatus_ATTENDANCE_STATUS_ABSENT      AttendanceStatus = 3
+	AttendanceStatus_ATTENDANCE_STATUS_EXCUSED     AttendanceStatus = 4
+	AttendanceStatus_ATTENDANCE_STATUS_REMOTE      AttendanceStatus = 5
+)
+
+// Enum value maps for AttendanceStatus.
+var (
+	AttendanceStatus_name = map[int32]string{
+		0: "ATTENDANCE_STATUS_UNSPECIFIED",
+		1: "ATTENDANCE_STATUS_PRESENT",
+		2: "ATTENDANCE_STATUS_LATE",
+		3: "ATTENDANCE_STATUS_ABSENT",
+		4: "ATTENDANCE_STATUS_EXCUSED",
+		5: "ATTENDANCE_STATUS_REMOTE",
+	}
+	AttendanceStatus_value = map[string]int32{
+		"ATTENDANCE_STATUS_UNSPECIFIED": 0,
+		"ATTENDANCE_STATUS_PRESENT":     1,
+		"ATTENDANCE_STATUS_LATE":        2,
+		"ATTENDANCE_STATUS_ABSENT":      3,
+		"ATTENDANCE_STATUS_EXCUSED":     4,
+		"ATTENDANCE_STATUS_REMOTE":      5,
+	}
+)
+
+func (x AttendanceStatus) Enum() *AttendanceStatus {
+	p := new(AttendanceStatus)
+	*p = x
+	return p
+}
+
+func (x AttendanceStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AttendanceStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_university_v1_university_proto_enumTypes[0].Descriptor()
+}
+
+func (AttendanceStatus) Type() protoreflect.EnumType {
+	return &file_university_v1_university_proto_enumTypes[0]
+}
+
+func (x AttendanceStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AttendanceStatus.Descriptor instead.
+func (AttendanceStatus) EnumDescriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{0}
+}
+
+type Student struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name    string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Surname string                 `protobuf:"bytes,3,opt,name=surname,proto3" json:"surname,omitempty"`
+	Gender  string                 `protobuf:"bytes,4,opt,name=gender,proto3" json:"gender,omitempty"`
+	// DD.MM.YYYY, пусто, если не указан
+	Birthday      string `protobuf:"bytes,5,opt,name=birthday,proto3" json:"birthday,omitempty"`
+	GroupId       int32  `protobuf:"varint,6,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	UserId        int32  `protobuf:"varint,7,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Student) Reset() {
+	*x = Student{}
+	mi := &file_university_v1_university_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Student) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Student) ProtoMessage() {}
+
+func (x *Student) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Student.ProtoReflect.Descriptor instead.
+func (*Student) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Student) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Student) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Student) GetSurname() string {
+	if x != nil {
+		return x.Surname
+	}
+	return ""
+}
+
+func (x *Student) GetGender() string {
+	if x != nil {
+		return x.Gender
+	}
+	return ""
+}
+
+func (x *Student) GetBirthday() string {
+	if x != nil {
+		return x.Birthday
+	}
+	return ""
+}
+
+func (x *Student) GetGroupId() int32 {
+	if x != nil {
+		return x.GroupId
+	}
+	return 0
+}
+
+func (x *Student) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type Group struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Faculty       string                 `protobuf:"bytes,3,opt,name=faculty,proto3" json:"faculty,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Group) Reset() {
+	*x = Group{}
+	mi := &file_university_v1_university_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Group) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Group) ProtoMessage() {}
+
+func (x *Group) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Group.ProtoReflect.Descriptor instead.
+func (*Group) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Group) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Group) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Group) GetFaculty() string {
+	if x != nil {
+		return x.Faculty
+	}
+	return ""
+}
+
+type Teacher struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId  int32                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name    string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Surname string                 `protobuf:"bytes,4,opt,name=surname,proto3" json:"surname,omitempty"`
+	Gender  string                 `protobuf:"bytes,5,opt,name=gender,proto3" json:"gender,omitempty"`
+	// предметы из назначений через запятую
+	Subjects      string `protobuf:"bytes,6,opt,name=subjects,proto3" json:"subjects,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Teacher) Reset() {
+	*x = Teacher{}
+	mi := &file_university_v1_university_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Teacher) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Teacher) ProtoMessage() {}
+
+func (x *Teacher) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Teacher.ProtoReflect.Descriptor instead.
+func (*Teacher) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Teacher) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Teacher) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Teacher) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Teacher) GetSurname() string {
+	if x != nil {
+		return x.Surname
+	}
+	return ""
+}
+
+func (x *Teacher) GetGender() string {
+	if x != nil {
+		return x.Gender
+	}
+	return ""
+}
+
+func (x *Teacher) GetSubjects() string {
+	if x != nil {
+		return x.Subjects
+	}
+	return ""
+}
+
+type Lesson struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	GroupId     int32                  `protobuf:"varint,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	SubjectId   int32                  `protobuf:"varint,3,opt,name=subject_id,json=subjectId,proto3" json:"subject_id,omitempty"`
+	SubjectName string                 `protobuf:"bytes,4,opt,name=subject_name,json=subjectName,proto3" json:"subject_name,omitempty"`
+	Name        string                 `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	DayOfWeek   int32                  `protobuf:"varint,6,opt,name=day_of_week,json=dayOfWeek,proto3" json:"day_of_week,omitempty"`
+	// HH:MM
+	StartTime     string `protobuf:"bytes,7,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       string `protobuf:"bytes,8,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	RoomId        *int32 `protobuf:"varint,9,opt,name=room_id,json=roomId,proto3,oneof" json:"room_id,omitempty"`
+	RoomName      string `protobuf:"bytes,10,opt,name=room_name,json=roomName,proto3" json:"room_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Lesson) Reset() {
+	*x = Lesson{}
+	mi := &file_university_v1_university_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Lesson) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Lesson) ProtoMessage() {}
+
+func (x *Lesson) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Lesson.ProtoReflect.Descriptor instead.
+func (*Lesson) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Lesson) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Lesson) GetGroupId() int32 {
+	if x != nil {
+		return x.GroupId
+	}
+	return 0
+}
+
+func (x *Lesson) GetSubjectId() int32 {
+	if x != nil {
+		return x.SubjectId
+	}
+	return 0
+}
+
+func (x *Lesson) GetSubjectName() string {
+	if x != nil {
+		return x.SubjectName
+	}
+	return ""
+}
+
+func (x *Lesson) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Lesson) GetDayOfWeek() int32 {
+	if x != nil {
+		return x.DayOfWeek
+	}
+	return 0
+}
+
+func (x *Lesson) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *Lesson) GetEndTime() string {
+	if x != nil {
+		return x.EndTime
+	}
+	return ""
+}
+
+func (x *Lesson) GetRoomId() int32 {
+	if x != nil && x.RoomId != nil {
+		return *x.RoomId
+	}
+	return 0
+}
+
+func (x *Lesson) GetRoomName() string {
+	if x != nil {
+		return x.RoomName
+	}
+	return ""
+}
+
+type AttendanceRecord struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	StudentId  int32                  `protobuf:"varint,2,opt,name=student_id,json=studentId,proto3" json:"student_id,omitempty"`
+	ScheduleId int32                  `protobuf:"varint,3,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	SubjectId  int32                  `protobuf:"varint,4,opt,name=subject_id,json=subjectId,proto3" json:"subject_id,omitempty"`
+	// DD.MM.YYYY
+	VisitDay      string           `protobuf:"bytes,5,opt,name=visit_day,json=visitDay,proto3" json:"visit_day,omitempty"`
+	Visited       bool             `protobuf:"varint,6,opt,name=visited,proto3" json:"visited,omitempty"`
+	Status        AttendanceStatus `protobuf:"varint,7,opt,name=status,proto3,enum=university.v1.AttendanceStatus" json:"status,omitempty"`
+	MinutesLate   *int32           `protobuf:"varint,8,opt,name=minutes_late,json=minutesLate,proto3,oneof" json:"minutes_late,omitempty"`
+	Note          string           `protobuf:"bytes,9,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttendanceRecord) Reset() {
+	*x = AttendanceRecord{}
+	mi := &file_university_v1_university_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttendanceRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttendanceRecord) ProtoMessage() {}
+
+func (x *AttendanceRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttendanceRecord.ProtoReflect.Descriptor instead.
+func (*AttendanceRecord) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AttendanceRecord) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *AttendanceRecord) GetStudentId() int32 {
+	if x != nil {
+		return x.StudentId
+	}
+	return 0
+}
+
+func (x *AttendanceRecord) GetScheduleId() int32 {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return 0
+}
+
+func (x *AttendanceRecord) GetSubjectId() int32 {
+	if x != nil {
+		return x.SubjectId
+	}
+	return 0
+}
+
+func (x *AttendanceRecord) GetVisitDay() string {
+	if x != nil {
+		return x.VisitDay
+	}
+	return ""
+}
+
+func (x *AttendanceRecord) GetVisited() bool {
+	if x != nil {
+		return x.Visited
+	}
+	return false
+}
+
+func (x *AttendanceRecord) GetStatus() AttendanceStatus {
+	if x != nil {
+		return x.Status
+	}
+	return AttendanceStatus_ATTENDANCE_STATUS_UNSPECIFIED
+}
+
+func (x *AttendanceRecord) GetMinutesLate() int32 {
+	if x != nil && x.MinutesLate != nil {
+		return *x.MinutesLate
+	}
+	return 0
+}
+
+func (x *AttendanceRecord) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type AttendanceEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	StudentId     int32                  `protobuf:"varint,2,opt,name=student_id,json=studentId,proto3" json:"student_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Surname       string                 `protobuf:"bytes,4,opt,name=surname,proto3" json:"surname,omitempty"`
+	ScheduleId    int32                  `protobuf:"varint,5,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	SubjectId     int32                  `protobuf:"varint,6,opt,name=subject_id,json=subjectId,proto3" json:"subject_id,omitempty"`
+	GroupId       int32                  `protobuf:"varint,7,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	VisitDay      string                 `protobuf:"bytes,8,opt,name=visit_day,json=visitDay,proto3" json:"visit_day,omitempty"`
+	Visited       bool                   `protobuf:"varint,9,opt,name=visited,proto3" json:"visited,omitempty"`
+	Status        AttendanceStatus       `protobuf:"varint,10,opt,name=status,proto3,enum=university.v1.AttendanceStatus" json:"status,omitempty"`
+	MinutesLate   *int32                 `protobuf:"varint,11,opt,name=minutes_late,json=minutesLate,proto3,oneof" json:"minutes_late,omitempty"`
+	RecordedAt    *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=recorded_at,json=recordedAt,proto3" json:"recorded_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttendanceEvent) Reset() {
+	*x = AttendanceEvent{}
+	mi := &file_university_v1_university_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttendanceEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttendanceEvent) ProtoMessage() {}
+
+func (x *AttendanceEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttendanceEvent.ProtoReflect.Descriptor instead.
+func (*AttendanceEvent) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AttendanceEvent) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *AttendanceEvent) GetStudentId() int32 {
+	if x != nil {
+		return x.StudentId
+	}
+	return 0
+}
+
+func (x *AttendanceEvent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AttendanceEvent) GetSurname() string {
+	if x != nil {
+		return x.Surname
+	}
+	return ""
+}
+
+func (x *AttendanceEvent) GetScheduleId() int32 {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return 0
+}
+
+func (x *AttendanceEvent) GetSubjectId() int32 {
+	if x != nil {
+		return x.SubjectId
+	}
+	return 0
+}
+
+func (x *AttendanceEvent) GetGroupId() int32 {
+	if x != nil {
+		return x.GroupId
+	}
+	return 0
+}
+
+func (x *AttendanceEvent) GetVisitDay() string {
+	if x != nil {
+		return x.VisitDay
+	}
+	return ""
+}
+
+func (x *AttendanceEvent) GetVisited() bool {
+	if x != nil {
+		return x.Visited
+	}
+	return false
+}
+
+func (x *AttendanceEvent) GetStatus() AttendanceStatus {
+	if x != nil {
+		return x.Status
+	}
+	return AttendanceStatus_ATTENDANCE_STATUS_UNSPECIFIED
+}
+
+func (x *AttendanceEvent) GetMinutesLate() int32 {
+	if x != nil && x.MinutesLate != nil {
+		return *x.MinutesLate
+	}
+	return 0
+}
+
+func (x *AttendanceEvent) GetRecordedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RecordedAt
+	}
+	return nil
+}
+
+type GetStudentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStudentRequest) Reset() {
+	*x = GetStudentRequest{}
+	mi := &file_university_v1_university_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStudentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStudentRequest) ProtoMessage() {}
+
+func (x *GetStudentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStudentRequest.ProtoReflect.Descriptor instead.
+func (*GetStudentRequest) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetStudentRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListStudentsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 0 — все студенты
+	GroupId       int32 `protobuf:"varint,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStudentsRequest) Reset() {
+	*x = ListStudentsRequest{}
+	mi := &file_university_v1_university_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStudentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStudentsRequest) ProtoMessage() {}
+
+func (x *ListStudentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStudentsRequest.ProtoReflect.Descriptor instead.
+func (*ListStudentsRequest) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListStudentsRequest) GetGroupId() int32 {
+	if x != nil {
+		return x.GroupId
+	}
+	return 0
+}
+
+type ListStudentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Students      []*Student             `protobuf:"bytes,1,rep,name=students,proto3" json:"students,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStudentsResponse) Reset() {
+	*x = ListStudentsResponse{}
+	mi := &file_university_v1_university_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStudentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStudentsResponse) ProtoMessage() {}
+
+func (x *ListStudentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStudentsResponse.ProtoReflect.Descriptor instead.
+func (*ListStudentsResponse) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListStudentsResponse) GetStudents() []*Student {
+	if x != nil {
+		return x.Students
+	}
+	return nil
+}
+
+type GetGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGroupRequest) Reset() {
+	*x = GetGroupRequest{}
+	mi := &file_university_v1_university_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGroupRequest) ProtoMessage() {}
+
+func (x *GetGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGroupRequest.ProtoReflect.Descriptor instead.
+func (*GetGroupRequest) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetGroupRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListGroupsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupsRequest) Reset() {
+	*x = ListGroupsRequest{}
+	mi := &file_university_v1_university_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupsRequest) ProtoMessage() {}
+
+func (x *ListGroupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupsRequest.ProtoReflect.Descriptor instead.
+func (*ListGroupsRequest) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{10}
+}
+
+type ListGroupsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []*Group               `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupsResponse) Reset() {
+	*x = ListGroupsResponse{}
+	mi := &file_university_v1_university_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupsResponse) ProtoMessage() {}
+
+func (x *ListGroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupsResponse.ProtoReflect.Descriptor instead.
+func (*ListGroupsResponse) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListGroupsResponse) GetGroups() []*Group {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type ListTeachersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTeachersRequest) Reset() {
+	*x = ListTeachersRequest{}
+	mi := &file_university_v1_university_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTeachersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTeachersRequest) ProtoMessage() {}
+
+func (x *ListTeachersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTeachersRequest.ProtoReflect.Descriptor instead.
+func (*ListTeachersRequest) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{12}
+}
+
+type ListTeachersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Teachers      []*Teacher             `protobuf:"bytes,1,rep,name=teachers,proto3" json:"teachers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTeachersResponse) Reset() {
+	*x = ListTeachersResponse{}
+	mi := &file_university_v1_university_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTeachersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTeachersResponse) ProtoMessage() {}
+
+func (x *ListTeachersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTeachersResponse.ProtoReflect.Descriptor instead.
+func (*ListTeachersResponse) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListTeachersResponse) GetTeachers() []*Teacher {
+	if x != nil {
+		return x.Teachers
+	}
+	return nil
+}
+
+type ListLessonsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       int32                  `protobuf:"varint,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	SubjectId     int32                  `protobuf:"varint,2,opt,name=subject_id,json=subjectId,proto3" json:"subject_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLessonsRequest) Reset() {
+	*x = ListLessonsRequest{}
+	mi := &file_university_v1_university_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLessonsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLessonsRequest) ProtoMessage() {}
+
+func (x *ListLessonsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLessonsRequest.ProtoReflect.Descriptor instead.
+func (*ListLessonsRequest) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListLessonsRequest) GetGroupId() int32 {
+	if x != nil {
+		return x.GroupId
+	}
+	return 0
+}
+
+func (x *ListLessonsRequest) GetSubjectId() int32 {
+	if x != nil {
+		return x.SubjectId
+	}
+	return 0
+}
+
+type ListLessonsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lessons       []*Lesson              `protobuf:"bytes,1,rep,name=lessons,proto3" json:"lessons,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLessonsResponse) Reset() {
+	*x = ListLessonsResponse{}
+	mi := &file_university_v1_university_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLessonsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLessonsResponse) ProtoMessage() {}
+
+func (x *ListLessonsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLessonsResponse.ProtoReflect.Descriptor instead.
+func (*ListLessonsResponse) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListLessonsResponse) GetLessons() []*Lesson {
+	if x != nil {
+		return x.Lessons
+	}
+	return nil
+}
+
+type ListStudentAttendanceRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	StudentId int32                  `protobuf:"varint,1,opt,name=student_id,json=studentId,proto3" json:"student_id,omitempty"`
+	// 0 — по всем предметам
+	SubjectId     int32 `protobuf:"varint,2,opt,name=subject_id,json=subjectId,proto3" json:"subject_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStudentAttendanceRequest) Reset() {
+	*x = ListStudentAttendanceRequest{}
+	mi := &file_university_v1_university_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStudentAttendanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStudentAttendanceRequest) ProtoMessage() {}
+
+func (x *ListStudentAttendanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStudentAttendanceRequest.ProtoReflect.Descriptor instead.
+func (*ListStudentAttendanceRequest) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListStudentAttendanceRequest) GetStudentId() int32 {
+	if x != nil {
+		return x.StudentId
+	}
+	return 0
+}
+
+func (x *ListStudentAttendanceRequest) GetSubjectId() int32 {
+	if x != nil {
+		return x.SubjectId
+	}
+	return 0
+}
+
+type ListLessonAttendanceRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	ScheduleId int32                  `protobuf:"varint,1,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	// DD.MM.YYYY; пусто — за все дни
+	Date          string `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLessonAttendanceRequest) Reset() {
+	*x = ListLessonAttendanceRequest{}
+	mi := &file_university_v1_university_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLessonAttendanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLessonAttendanceRequest) ProtoMessage() {}
+
+func (x *ListLessonAttendanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLessonAttendanceRequest.ProtoReflect.Descriptor instead.
+func (*ListLessonAttendanceRequest) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListLessonAttendanceRequest) GetScheduleId() int32 {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return 0
+}
+
+func (x *ListLessonAttendanceRequest) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+type ListAttendanceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*AttendanceRecord    `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAttendanceResponse) Reset() {
+	*x = ListAttendanceResponse{}
+	mi := &file_university_v1_university_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAttendanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAttendanceResponse) ProtoMessage() {}
+
+func (x *ListAttendanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAttendanceResponse.ProtoReflect.Descriptor instead.
+func (*ListAttendanceResponse) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListAttendanceResponse) GetRecords() []*AttendanceRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+type StreamAttendanceRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	SubjectId  int32                  `protobuf:"varint,1,opt,name=subject_id,json=subjectId,proto3" json:"subject_id,omitempty"`
+	GroupId    int32                  `protobuf:"varint,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	ScheduleId int32                  `protobuf:"varint,3,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	// DD.MM.YYYY
+	Date string `protobuf:"bytes,4,opt,name=date,proto3" json:"date,omitempty"`
+	// дослать события после этого event_id
+	LastEventId   int64 `protobuf:"varint,5,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamAttendanceRequest) Reset() {
+	*x = StreamAttendanceRequest{}
+	mi := &file_university_v1_university_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamAttendanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamAttendanceRequest) ProtoMessage() {}
+
+func (x *StreamAttendanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_university_v1_university_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamAttendanceRequest.ProtoReflect.Descriptor instead.
+func (*StreamAttendanceRequest) Descriptor() ([]byte, []int) {
+	return file_university_v1_university_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *StreamAttendanceRequest) GetSubjectId() int32 {
+	if x != nil {
+		return x.SubjectId
+	}
+	return 0
+}
+
+func (x *StreamAttendanceRequest) GetGroupId() int32 {
+	if x != nil {
+		return x.GroupId
+	}
+	return 0
+}
+
+func (x *StreamAttendanceRequest) GetScheduleId() int32 {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return 0
+}
+
+func (x *StreamAttendanceRequest) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *StreamAttendanceRequest) GetLastEventId() int64 {
+	if x != nil {
+		return x.LastEventId
+	}
+	return 0
+}
+
+var File_university_v1_university_proto protoreflect.FileDescriptor
+
+const file_university_v1_university_proto_rawDesc = "" +
+	"\n" +
+	"\x1euniversity/v1/university.proto\x12\runiversity.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xaf\x01\n" +
+	"\aStudent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\asurname\x18\x03 \x01(\tR\asurname\x12\x16\n" +
+	"\x06gender\x18\x04 \x01(\tR\x06gender\x12\x1a\n" +
+	"\bbirthday\x18\x05 \x01(\tR\bbirthday\x12\x19\n" +
+	"\bgroup_id\x18\x06 \x01(\x05R\agroupId\x12\x17\n" +
+	"\auser_id\x18\a \x01(\x05R\x06userId\"E\n" +
+	"\x05Group\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\afaculty\x18\x03 \x01(\tR\afaculty\"\x94\x01\n" +
+	"\aTeacher\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x05R\x06userId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
+	"\asurname\x18\x04 \x01(\tR\asurname\x12\x16\n" +
+	"\x06gender\x18\x05 \x01(\tR\x06gender\x12\x1a\n" +
+	"\bsubjects\x18\x06 \x01(\tR\bsubjects\"\xaa\x02\n" +
+	"\x06Lesson\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x19\n" +
+	"\bgroup_id\x18\x02 \x01(\x05R\agroupId\x12\x1d\n" +
+	"\n" +
+	"subject_id\x18\x03 \x01(\x05R\tsubjectId\x12!\n" +
+	"\fsubject_name\x18\x04 \x01(\tR\vsubjectName\x12\x12\n" +
+	"\x04name\x18\x05 \x01(\tR\x04name\x12\x1e\n" +
+	"\vday_of_week\x18\x06 \x01(\x05R\tdayOfWeek\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\a \x01(\tR\tstartTime\x12\x19\n" +
+	"\bend_time\x18\b \x01(\tR\aendTime\x12\x1c\n" +
+	"\aroom_id\x18\t \x01(\x05H\x00R\x06roomId\x88\x01\x01\x12\x1b\n" +
+	"\troom_name\x18\n" +
+	" \x01(\tR\broomNameB\n" +
+	"\n" +
+	"\b_room_id\"\xbe\x02\n" +
+	"\x10AttendanceRecord\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x1d\n" +
+	"\n" +
+	"student_id\x18\x02 \x01(\x05R\tstudentId\x12\x1f\n" +
+	"\vschedule_id\x18\x03 \x01(\x05R\n" +
+	"scheduleId\x12\x1d\n" +
+	"\n" +
+	"subject_id\x18\x04 \x01(\x05R\tsubjectId\x12\x1b\n" +
+	"\tvisit_day\x18\x05 \x01(\tR\bvisitDay\x12\x18\n" +
+	"\avisited\x18\x06 \x01(\bR\avisited\x127\n" +
+	"\x06status\x18\a \x01(\x0e2\x1f.university.v1.AttendanceStatusR\x06status\x12&\n" +
+	"\fminutes_late\x18\b \x01(\x05H\x00R\vminutesLate\x88\x01\x01\x12\x12\n" +
+	"\x04note\x18\t \x01(\tR\x04noteB\x0f\n" +
+	"\r_minutes_late\"\xba\x03\n" +
+	"\x0fAttendanceEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\x03R\aeventId\x12\x1d\n" +
+	"\n" +
+	"student_id\x18\x02 \x01(\x05R\tstudentId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
+	"\asurname\x18\x04 \x01(\tR\asurname\x12\x1f\n" +
+	"\vschedule_id\x18\x05 \x01(\x05R\n" +
+	"scheduleId\x12\x1d\n" +
+	"\n" +
+	"subject_id\x18\x06 \x01(\x05R\tsubjectId\x12\x19\n" +
+	"\bgroup_id\x18\a \x01(\x05R\agroupId\x12\x1b\n" +
+	"\tvisit_day\x18\b \x01(\tR\bvisitDay\x12\x18\n" +
+	"\avisited\x18\t \x01(\bR\avisited\x127\n" +
+	"\x06status\x18\n" +
+	" \x01(\x0e2\x1f.university.v1.AttendanceStatusR\x06status\x12&\n" +
+	"\fminutes_late\x18\v \x01(\x05H\x00R\vminutesLate\x88\x01\x01\x12;\n" +
+	"\vrecorded_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"recordedAtB\x0f\n" +
+	"\r_minutes_late\"#\n" +
+	"\x11GetStudentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"0\n" +
+	"\x13ListStudentsRequest\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\x05R\agroupId\"J\n" +
+	"\x14ListStudentsResponse\x122\n" +
+	"\bstudents\x18\x01 \x03(\v2\x16.university.v1.StudentR\bstudents\"!\n" +
+	"\x0fGetGroupRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"\x13\n" +
+	"\x11ListGroupsRequest\"B\n" +
+	"\x12ListGroupsResponse\x12,\n" +
+	"\x06groups\x18\x01 \x03(\v2\x14.university.v1.GroupR\x06groups\"\x15\n" +
+	"\x13ListTeachersRequest\"J\n" +
+	"\x14ListTeachersResponse\x122\n" +
+	"\bteachers\x18\x01 \x03(\v2\x16.university.v1.TeacherR\bteachers\"N\n" +
+	"\x12ListLessonsRequest\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\x05R\agroupId\x12\x1d\n" +
+	"\n" +
+	"subject_id\x18\x02 \x01(\x05R\tsubjectId\"F\n" +
+	"\x13ListLessonsResponse\x12/\n" +
+	"\alessons\x18\x01 \x03(\v2\x15.university.v1.LessonR\alessons\"\\\n" +
+	"\x1cListStudentAttendanceRequest\x12\x1d\n" +
+	"\n" +
+	"student_id\x18\x01 \x01(\x05R\tstudentId\x12\x1d\n" +
+	"\n" +
+	"subject_id\x18\x02 \x01(\x05R\tsubjectId\"R\n" +
+	"\x1bListLessonAttendanceRequest\x12\x1f\n" +
+	"\vschedule_id\x18\x01 \x01(\x05R\n" +
+	"scheduleId\x12\x12\n" +
+	"\x04date\x18\x02 \x01(\tR\x04date\"S\n" +
+	"\x16ListAttendanceResponse\x129\n" +
+	"\arecords\x18\x01 \x03(\v2\x1f.university.v1.AttendanceRecordR\arecords\"\xac\x01\n" +
+	"\x17StreamAttendanceRequest\x12\x1d\n" +
+	"\n" +
+	"subject_id\x18\x01 \x01(\x05R\tsubjectId\x12\x19\n" +
+	"\bgroup_id\x18\x02 \x01(\x05R\agroupId\x12\x1f\n" +
+	"\vschedule_id\x18\x03 \x01(\x05R\n" +
+	"scheduleId\x12\x12\n" +
+	"\x04date\x18\x04 \x01(\tR\x04date\x12\"\n" +
+	"\rlast_event_id\x18\x05 \x01(\x03R\vlastEventId*\xcb\x01\n" +
+	"\x10AttendanceStatus\x12!\n" +
+	"\x1dATTENDANCE_STATUS_UNSPECIFIED\x10\x00\x12\x1d\n" +
+	"\x19ATTENDANCE_STATUS_PRESENT\x10\x01\x12\x1a\n" +
+	"\x16ATTENDANCE_STATUS_LATE\x10\x02\x12\x1c\n" +
+	"\x18ATTENDANCE_STATUS_ABSENT\x10\x03\x12\x1d\n" +
+	"\x19ATTENDANCE_STATUS_EXCUSED\x10\x04\x12\x1c\n" +
+	"\x18ATTENDANCE_STATUS_REMOTE\x10\x052\xa7\x06\n" +
+	"\n" +
+	"University\x12F\n" +
+	"\n" +
+	"GetStudent\x12 .university.v1.GetStudentRequest\x1a\x16.university.v1.Student\x12W\n" +
+	"\fListStudents\x12\".university.v1.ListStudentsRequest\x1a#.university.v1.ListStudentsResponse\x12@\n" +
+	"\bGetGroup\x12\x1e.university.v1.GetGroupRequest\x1a\x14.university.v1.Group\x12Q\n" +
+	"\n" +
+	"ListGroups\x12 .university.v1.ListGroupsRequest\x1a!.university.v1.ListGroupsResponse\x12W\n" +
+	"\fListTeachers\x12\".university.v1.ListTeachersRequest\x1a#.university.v1.ListTeachersResponse\x12T\n" +
+	"\vListLessons\x12!.university.v1.ListLessonsRequest\x1a\".university.v1.ListLessonsResponse\x12k\n" +
+	"\x15ListStudentAttendance\x12+.university.v1.ListStudentAttendanceRequest\x1a%.university.v1.ListAttendanceResponse\x12i\n" +
+	"\x14ListLessonAttendance\x12*.university.v1.ListLessonAttendanceRequest\x1a%.university.v1.ListAttendanceResponse\x12\\\n" +
+	"\x10StreamAttendance\x12&.university.v1.StreamAttendanceRequest\x1a\x1e.university.v1.AttendanceEvent0\x01B(Z&hw_5_jwt/api/universityv1;universityv1b\x06proto3"
+
+var (
+	file_university_v1_university_proto_rawDescOnce sync.Once
+	file_university_v1_university_proto_rawDescData []byte
+)
+
+func file_university_v1_university_proto_rawDescGZIP() []byte {
+	file_university_v1_university_proto_rawDescOnce.Do(func() {
+		file_university_v1_university_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_university_v1_university_proto_rawDesc), len(file_university_v1_university_proto_rawDesc)))
+	})
+	return file_university_v1_university_proto_rawDescData
+}
+
+var file_university_v1_university_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_university_v1_university_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_university_v1_university_proto_goTypes = []any{
+	(AttendanceStatus)(0),                // 0: university.v1.AttendanceStatus
+	(*Student)(nil),                      // 1: university.v1.Student
+	(*Group)(nil),                        // 2: university.v1.Group
+	(*Teacher)(nil),                      // 3: university.v1.Teacher
+	(*Lesson)(nil),                       // 4: university.v1.Lesson
+	(*AttendanceRecord)(nil),             // 5: university.v1.AttendanceRecord
+	(*AttendanceEvent)(nil),              // 6: university.v1.AttendanceEvent
+	(*GetStudentRequest)(nil),            // 7: university.v1.GetStudentRequest
+	(*ListStudentsRequest)(nil),          // 8: university.v1.ListStudentsRequest
+	(*ListStudentsResponse)(nil),         // 9: university.v1.ListStudentsResponse
+	(*GetGroupRequest)(nil),              // 10: university.v1.GetGroupRequest
+	(*ListGroupsRequest)(nil),            // 11: university.v1.ListGroupsRequest
+	(*ListGroupsResponse)(nil),           // 12: university.v1.ListGroupsResponse
+	(*ListTeachersRequest)(nil),          // 13: university.v1.ListTeachersRequest
+	(*ListTeachersResponse)(nil),         // 14: university.v1.ListTeachersResponse
+	(*ListLessonsRequest)(nil),           // 15: university.v1.ListLessonsRequest
+	(*ListLessonsResponse)(nil),          // 16: university.v1.ListLessonsResponse
+	(*ListStudentAttendanceRequest)(nil), // 17: university.v1.ListStudentAttendanceRequest
+	(*ListLessonAttendanceRequest)(nil),  // 18: university.v1.ListLessonAttendanceRequest
+	(*ListAttendanceResponse)(nil),       // 19: university.v1.ListAttendanceResponse
+	(*StreamAttendanceRequest)(nil),      // 20: university.v1.StreamAttendanceRequest
+	(*timestamppb.Timestamp)(nil),        // 21: google.protobuf.Timestamp
+}
+var file_university_v1_university_proto_depIdxs = []int32{
+	0,  // 0: university.v1.AttendanceRecord.status:type_name -> university.v1.AttendanceStatus
+	0,  // 1: university.v1.AttendanceEvent.status:type_name -> university.v1.AttendanceStatus
+	21, // 2: university.v1.AttendanceEvent.recorded_at:type_name -> google.protobuf.Timestamp
+	1,  // 3: university.v1.ListStudentsResponse.students:type_name -> university.v1.Student
+	2,  // 4: university.v1.ListGroupsResponse.groups:type_name -> university.v1.Group
+	3,  // 5: university.v1.ListTeachersResponse.teachers:type_name -> university.v1.Teacher
+	4,  // 6: university.v1.ListLessonsResponse.lessons:type_name -> university.v1.Lesson
+	5,  // 7: university.v1.ListAttendanceResponse.records:type_name -> university.v1.AttendanceRecord
+	7,  // 8: university.v1.University.GetStudent:input_type -> university.v1.GetStudentRequest
+	8,  // 9: university.v1.University.ListStudents:input_type -> university.v1.ListStudentsRequest
+	10, // 10: university.v1.University.GetGroup:input_type -> university.v1.GetGroupRequest
+	11, // 11: university.v1.University.ListGroups:input_type -> university.v1.ListGroupsRequest
+	13, // 12: university.v1.University.ListTeachers:input_type -> university.v1.ListTeachersRequest
+	15, // 13: university.v1.University.ListLessons:input_type -> university.v1.ListLessonsRequest
+	17, // 14: university.v1.University.ListStudentAttendance:input_type -> university.v1.ListStudentAttendanceRequest
+	18, // 15: university.v1.University.ListLessonAttendance:input_type -> university.v1.ListLessonAttendanceRequest
+	20, // 16: university.v1.University.StreamAttendance:input_type -> university.v1.StreamAttendanceRequest
+	1,  // 17: university.v1.University.GetStudent:output_type -> university.v1.Student
+	9,  // 18: university.v1.University.ListStudents:output_type -> university.v1.ListStudentsResponse
+	2,  // 19: university.v1.University.GetGroup:output_type -> university.v1.Group
+	12, // 20: university.v1.University.ListGroups:output_type -> university.v1.ListGroupsResponse
+	14, // 21: university.v1.University.ListTeachers:output_type -> university.v1.ListTeachersResponse
+	16, // 22: university.v1.University.ListLessons:output_type -> university.v1.ListLessonsResponse
+	19, // 23: university.v1.University.ListStudentAttendance:output_type -> university.v1.ListAttendanceResponse
+	19, // 24: university.v1.University.ListLessonAttendance:output_type -> university.v1.ListAttendanceResponse
+	6,  // 25: university.v1.University.StreamAttendance:output_type -> university.v1.AttendanceEvent
+	17, // [17:26] is the sub-list for method output_type
+	8,  // [8:17] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_university_v1_university_proto_init() }
+func file_university_v1_university_proto_init() {
+	if File_university_v1_university_proto != nil {
+		return
+	}
+	file_university_v1_university_proto_msgTypes[3].OneofWrappers = []any{}
+	file_university_v1_university_proto_msgTypes[4].OneofWrappers = []any{}
+	file_university_v1_university_proto_msgTypes[5].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_university_v1_university_proto_rawDesc), len(file_university_v1_university_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   20,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_university_v1_university_proto_goTypes,
+		DependencyIndexes: file_university_v1_university_proto_depIdxs,
+		EnumInfos:         file_university_v1_university_proto_enumTypes,
+		MessageInfos:      file_university_v1_university_proto_msgTypes,
+	}.Build()
+	File_university_v1_university_proto = out.File
+	file_university_v1_university_proto_goTypes = nil
+	file_university_v1_university_proto_depIdxs = nil
+}
@@ -0,0 +1,441 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: university/v1/university.proto
+
+// Доступ внутренних сервисов к студентам, группам, расписанию и посещаемости.
+// Авторизация — в метаданных: authorization: Bearer <JWT> или x-api-key: <ключ>.
+// Ошибки несут google.rpc.ErrorInfo, reason — тот же код, что в problem+json REST API.
+
+package universityv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	University_GetStudent_FullMethodName            = "/university.v1.University/GetStudent"
+	University_ListStudents_FullMethodName          = "/university.v1.University/ListStudents"
+	University_GetGroup_FullMethodName              = "/university.v1.University/GetGroup"
+	University_ListGroups_FullMethodName            = "/university.v1.University/ListGroups"
+	University_ListTeachers_FullMethodName          = "/university.v1.University/ListTeachers"
+	University_ListLessons_FullMethodName           = "/university.v1.University/ListLessons"
+	University_ListStudentAttendance_FullMethodName = "/university.v1.University/ListStudentAttendance"
+	University_ListLessonAttendance_FullMethodName  = "/university.v1.University/ListLessonAttendance"
+	University_StreamAttendance_FullMethodName      = "/university.v1.University/StreamAttendance"
+)
+
+// UniversityClient is the client API for University service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type UniversityClient interface {
+	GetStudent(ctx context.Context, in *GetStudentRequest, opts ...grpc.CallOption) (*Student, error)
+	ListStudents(ctx context.Context, in *ListStudentsRequest, opts ...grpc.CallOption) (*ListStudentsResponse, error)
+	GetGroup(ctx context.Context, in *GetGroupRequest, opts ...grpc.CallOption) (*Group, error)
+	ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (*ListGroupsResponse, error)
+	ListTeachers(ctx context.Context, in *ListTeachersRequest, opts ...grpc.CallOption) (*ListTeachersResponse, error)
+	// Недельное расписание группы или предмета: нужен group_id или subject_id.
+	ListLessons(ctx context.Context, in *ListLessonsRequest, opts ...grpc.CallOption) (*ListLessonsResponse, error)
+	ListStudentAttendance(ctx context.Context, in *ListStudentAttendanceRequest, opts ...grpc.CallOption) (*ListAttendanceResponse, error)
+	ListLessonAttendance(ctx context.Context, in *ListLessonAttendanceRequest, opts ...grpc.CallOption) (*ListAttendanceResponse, error)
+	// Отметки по мере записи. Видимость как у /api/v2/attendance/stream: студент — свои
+	// отметки, учитель — назначенные предметы и курируемые группы, администратор и
+	// сервис с API-ключом — все. При обрыве переподключайтесь с last_event_id.
+	StreamAttendance(ctx context.Context, in *StreamAttendanceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AttendanceEvent], error)
+}
+
+type universityClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUniversityClient(cc grpc.ClientConnInterface) UniversityClient {
+	return &universityClient{cc}
+}
+
+func (c *universityClient) GetStudent(ctx context.Context, in *GetStudentRequest, opts ...grpc.CallOption) (*Student, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Student)
+	err := c.cc.Invoke(ctx, University_GetStudent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *universityClient) ListStudents(ctx context.Context, in *ListStudentsRequest, opts ...grpc.CallOption) (*ListStudentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListStudentsResponse)
+	err := c.cc.Invoke(ctx, University_ListStudents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *universityClient) GetGroup(ctx context.Context, in *GetGroupRequest, opts ...grpc.CallOption) (*Group, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Group)
+	err := c.cc.Invoke(ctx, University_GetGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *universityClient) ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (*ListGroupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListGroupsResponse)
+	err := c.cc.Invoke(ctx, University_ListGroups_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *universityClient) ListTeachers(ctx context.Context, in *ListTeachersRequest, opts ...grpc.CallOption) (*ListTeachersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTeachersResponse)
+	err := c.cc.Invoke(ctx, University_ListTeachers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *universityClient) ListLessons(ctx context.Context, in *ListLessonsRequest, opts ...grpc.CallOption) (*ListLessonsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLessonsResponse)
+	err := c.cc.Invoke(ctx, University_ListLessons_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *universityClient) ListStudentAttendance(ctx context.Context, in *ListStudentAttendanceRequest, opts ...grpc.CallOption) (*ListAttendanceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAttendanceResponse)
+	err := c.cc.Invoke(ctx, University_ListStudentAttendance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *universityClient) ListLessonAttendance(ctx context.Context, in *ListLessonAttendanceRequest, opts ...grpc.CallOption) (*ListAttendanceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAttendanceResponse)
+	err := c.cc.Invoke(ctx, University_ListLessonAttendance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *universityClient) StreamAttendance(ctx context.Context, in *StreamAttendanceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AttendanceEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &University_ServiceDesc.Streams[0], University_StreamAttendance_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamAttendanceRequest, AttendanceEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type University_StreamAttendanceClient = grpc.ServerStreamingClient[AttendanceEvent]
+
+// UniversityServer is the server API for University service.
+// All implementations must embed UnimplementedUniversityServer
+// for forward compatibility.
+type UniversityServer interface {
+	GetStudent(context.Context, *GetStudentRequest) (*Student, error)
+	ListStudents(context.Context, *ListStudentsRequest) (*ListStudentsResponse, error)
+	GetGroup(context.Context, *GetGroupRequest) (*Group, error)
+	ListGroups(context.Context, *ListGroupsRequest) (*ListGroupsResponse, error)
+	ListTeachers(context.Context, *ListTeachersRequest) (*ListTeachersResponse, error)
+	// Недельное расписание группы или предмета: нужен group_id или subject_id.
+	ListLessons(context.Context, *ListLessonsRequest) (*ListLessonsResponse, error)
+	ListStudentAttendance(context.Context, *ListStudentAttendanceRequest) (*ListAttendanceResponse, error)
+	ListLessonAttendance(context.Context, *ListLessonAttendanceRequest) (*ListAttendanceResponse, error)
+	// Отметки по мере записи. Видимость как у /api/v2/attendance/stream: студент — свои
+	// отметки, учитель — назначенные предметы и курируемые группы, администратор и
+	// сервис с API-ключом — все. При обрыве переподключайтесь с last_event_id.
+	StreamAttendance(*StreamAttendanceRequest, grpc.ServerStreamingServer[AttendanceEvent]) error
+	mustEmbedUnimplementedUniversityServer()
+}
+
+// UnimplementedUniversityServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedUniversityServer struct{}
+
+func (UnimplementedUniversityServer) GetStudent(context.Context, *GetStudentRequest) (*Student, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStudent not implemented")
+}
+func (UnimplementedUniversityServer) ListStudents(context.Context, *ListStudentsRequest) (*ListStudentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStudents not implemented")
+}
+func (UnimplementedUniversityServer) GetGroup(context.Context, *GetGroupRequest) (*Group, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGroup not implemented")
+}
+func (UnimplementedUniversityServer) ListGroups(context.Context, *ListGroupsRequest) (*ListGroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGroups not implemented")
+}
+func (UnimplementedUniversityServer) ListTeachers(context.Context, *ListTeachersRequest) (*ListTeachersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTeachers not implemented")
+}
+func (UnimplementedUniversityServer) ListLessons(context.Context, *ListLessonsRequest) (*ListLessonsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLessons not implemented")
+}
+func (UnimplementedUniversityServer) ListStudentAttendance(context.Context, *ListStudentAttendanceRequest) (*ListAttendanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStudentAttendance not implemented")
+}
+func (UnimplementedUniversityServer) ListLessonAttendance(context.Context, *ListLessonAttendanceRequest) (*ListAttendanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLessonAttendance not implemented")
+}
+func (UnimplementedUniversityServer) StreamAttendance(*StreamAttendanceRequest, grpc.ServerStreamingServer[AttendanceEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamAttendance not implemented")
+}
+func (UnimplementedUniversityServer) mustEmbedUnimplementedUniversityServer() {}
+func (UnimplementedUniversityServer) testEmbeddedByValue()                    {}
+
+// UnsafeUniversityServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UniversityServer will
+// result in compilation errors.
+type UnsafeUniversityServer interface {
+	mustEmbedUnimplementedUniversityServer()
+}
+
+func RegisterUniversityServer(s grpc.ServiceRegistrar, srv UniversityServer) {
+	// If the following call pancis, it indicates UnimplementedUniversityServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&University_ServiceDesc, srv)
+}
+
+func _University_GetStudent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStudentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UniversityServer).GetStudent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: University_GetStudent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UniversityServer).GetStudent(ctx, req.(*GetStudentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _University_ListStudents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStudentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UniversityServer).ListStudents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: University_ListStudents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UniversityServer).ListStudents(ctx, req.(*ListStudentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _University_GetGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UniversityServer).GetGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: University_GetGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UniversityServer).GetGroup(ctx, req.(*GetGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _University_ListGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UniversityServer).ListGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: University_ListGroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UniversityServer).ListGroups(ctx, req.(*ListGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _University_ListTeachers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTeachersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UniversityServer).ListTeachers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: University_ListTeachers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UniversityServer).ListTeachers(ctx, req.(*ListTeachersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _University_ListLessons_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLessonsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UniversityServer).ListLessons(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: University_ListLessons_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UniversityServer).ListLessons(ctx, req.(*ListLessonsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _University_ListStudentAttendance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStudentAttendanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UniversityServer).ListStudentAttendance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: University_ListStudentAttendance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UniversityServer).ListStudentAttendance(ctx, req.(*ListStudentAttendanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _University_ListLessonAttendance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLessonAttendanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UniversityServer).ListLessonAttendance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: University_ListLessonAttendance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UniversityServer).ListLessonAttendance(ctx, req.(*ListLessonAttendanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _University_StreamAttendance_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamAttendanceRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UniversityServer).StreamAttendance(m, &grpc.GenericServerStream[StreamAttendanceRequest, AttendanceEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type University_StreamAttendanceServer = grpc.ServerStreamingServer[AttendanceEvent]
+
+// University_ServiceDesc is the grpc.ServiceDesc for University service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var University_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "university.v1.University",
+	HandlerType: (*UniversityServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStudent",
+			Handler:    _University_GetStudent_Handler,
+		},
+		{
+			MethodName: "ListStudents",
+			Handler:    _University_ListStudents_Handler,
+		},
+		{
+			MethodName: "GetGroup",
+			Handler:    _University_GetGroup_Handler,
+		},
+		{
+			MethodName: "ListGroups",
+			Handler:    _University_ListGroups_Handler,
+		},
+		{
+			MethodName: "ListTeachers",
+			Handler:    _University_ListTeachers_Handler,
+		},
+		{
+			MethodName: "ListLessons",
+			Handler:    _University_ListLessons_Handler,
+		},
+		{
+			MethodName: "ListStudentAttendance",
+			Handler:    _University_ListStudentAttendance_Handler,
+		},
+		{
+			MethodName: "ListLessonAttendance",
+			Handler:    _University_ListLessonAttendance_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamAttendance",
+			Handler:       _University_StreamAttendance_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "university/v1/university.proto",
+}
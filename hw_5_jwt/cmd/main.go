@@ -4,27 +4,42 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
+	"hw_5_jwt/internal/grpcapi"
 	"hw_5_jwt/internal/handlers"
+	"hw_5_jwt/internal/i18n"
+	"hw_5_jwt/internal/metrics"
+	"hw_5_jwt/internal/notify"
 	"hw_5_jwt/internal/postgres"
+	"hw_5_jwt/internal/realtime"
+	"hw_5_jwt/internal/webhooks"
 )
 
 func main() {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	// язык журнала задаётся отдельно от языка ответов API: LOG_LANG=en для англоязычной поддержки
+	logLang := os.Getenv("LOG_LANG")
+	if logLang == "" {
+		logLang = i18n.Default
+	}
+	logger := slog.New(i18n.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelDebug,
-	}))
+	}), logLang))
 	slog.SetDefault(logger)
 
 	logger.Info("запуск университета")
+	if !i18n.Supported(logLang) {
+		logger.Warn("неподдерживаемый LOG_LANG, журнал на русском", "log_lang", logLang)
+	}
 
 	connStr := os.Getenv("DATABASE_URL")
 	if connStr == "" {
@@ -33,12 +48,20 @@ func main() {
 
 	logger.Info("подключение к базе данных", "dsn", connStr)
 
-	conn, err := pgx.Connect(context.Background(), connStr)
+	poolConfig, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		logger.Error("ошибка подключения к базе данных", "error", err)
+		os.Exit(1)
+	}
+	poolConfig.ConnConfig.Tracer = metrics.QueryTracer{}
+
+	conn, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		logger.Error("ошибка подключения к базе данных", "error", err)
 		os.Exit(1)
 	}
-	defer conn.Close(context.Background())
+	defer conn.Close()
+	metrics.RegisterPool(conn)
 
 	if err := conn.Ping(context.Background()); err != nil {
 		logger.Error("ошибка ping базы данных", "error", err)
@@ -51,15 +74,30 @@ func main() {
 
 	}
 
+	if email, password := os.Getenv("ADMIN_EMAIL"), os.Getenv("ADMIN_PASSWORD"); email != "" && password != "" {
+		created, err := handlers.EnsureAdmin(context.Background(), postgres.NewRepository(conn), email, password)
+		switch {
+		case err != nil:
+			logger.Error("ошибка создания администратора", "email", email, "error", err)
+		case created:
+			logger.Info("создан администратор", "email", email)
+		}
+	}
+
 	e := echo.New()
+	// X-Request-ID возвращается клиенту и попадает в тело ошибок, по нему ищется запись в журнале
+	e.Use(middleware.RequestID())
+	e.Use(metrics.Middleware())
 	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
-		LogStatus:   true,
-		LogURI:      true,
-		LogError:    true,
-		HandleError: true,
+		LogStatus:    true,
+		LogURI:       true,
+		LogError:     true,
+		LogRequestID: true,
+		HandleError:  true,
 		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
 			if v.Error == nil {
 				logger.Info("запрос",
+					"request_id", v.RequestID,
 					"method", v.Method,
 					"uri", v.URI,
 					"status", v.Status,
@@ -67,6 +105,7 @@ func main() {
 				)
 			} else {
 				logger.Error("ошибка запроса",
+					"request_id", v.RequestID,
 					"method", v.Method,
 					"uri", v.URI,
 					"status", v.Status,
@@ -84,29 +123,117 @@ func main() {
 	e.Use(middleware.Secure())
 
 	repo := postgres.NewRepository(conn)
-	h := handlers.NewHandler(repo, logger)
+
+	channels := []notify.Channel{notify.NewInboxChannel(repo)}
+	if mailer := notify.NewMailerFromEnv(); mailer != nil {
+		channels = append(channels, notify.NewEmailChannel(mailer))
+	} else {
+		logger.Warn("SMTP_HOST не задан, оповещения по почте отключены")
+	}
+	if url := os.Getenv("ALERT_WEBHOOK_URL"); url != "" {
+		channels = append(channels, notify.NewWebhookChannel(url))
+	}
+	alerts := notify.NewEngine(repo, logger, channels...)
+
+	alertsInterval := time.Hour
+	if value := os.Getenv("ALERTS_INTERVAL"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			alertsInterval = parsed
+		} else {
+			logger.Warn("неверный ALERTS_INTERVAL, используется 1h", "value", value)
+		}
+	}
+
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go alerts.Run(bgCtx, alertsInterval)
+	go webhooks.NewDispatcher(repo, logger).Run(bgCtx, 5*time.Second)
+	hub := realtime.NewHub(repo, logger)
+	go hub.Run(bgCtx)
+
+	h := handlers.NewHandler(repo, alerts, hub, logger)
 
 	h.RegisterRoutes(e)
+	for _, problem := range handlers.CheckOpenAPI(e.Routes()) {
+		logger.Warn("спецификация OpenAPI расходится с маршрутами", "problem", problem)
+	}
+	for _, problem := range i18n.Check() {
+		logger.Warn("каталоги сообщений расходятся", "problem", problem)
+	}
+
+	// /metrics — служебный маршрут вне API и спецификации: на отдельном порту
+	// METRICS_ADDR, закрытом от внешней сети, или на основном с токеном METRICS_TOKEN
+	metricsHandler := metrics.Handler(os.Getenv("METRICS_TOKEN"))
+	var metricsServer *http.Server
+	if addr := os.Getenv("METRICS_ADDR"); addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", metricsHandler)
+		metricsServer = &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	} else {
+		if os.Getenv("METRICS_TOKEN") == "" {
+			logger.Warn("METRICS_TOKEN не задан, /metrics на основном порту открыт всем")
+		}
+		e.GET("/metrics", echo.WrapHandler(metricsHandler))
+	}
+
+	apiKeys, err := grpcapi.ParseAPIKeys(os.Getenv("GRPC_API_KEYS"))
+	if err != nil {
+		logger.Error("ошибка разбора GRPC_API_KEYS", "error", err)
+		os.Exit(1)
+	}
+	if len(apiKeys) == 0 {
+		logger.Warn("GRPC_API_KEYS не задан, gRPC доступен только по JWT")
+	}
+	grpcServer := grpcapi.New(repo, hub, apiKeys, logger)
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
-		logger.Info("сервер запущен на :8080")
 		port := os.Getenv("PORT")
 		if port == "" {
 			port = "8080"
 		}
 
 		addr := ":" + port
-		logger.Info("сервер запущен на " + addr)
+		logger.Info("сервер запущен", "addr", addr)
 		if err := e.Start(addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logger.Error("ошибка запуска сервера", "error", err)
 		}
 	}()
 
+	go func() {
+		port := os.Getenv("GRPC_PORT")
+		if port == "" {
+			port = "9090"
+		}
+
+		addr := ":" + port
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			logger.Error("ошибка запуска gRPC-сервера", "error", err)
+			return
+		}
+		logger.Info("gRPC-сервер запущен", "addr", addr)
+		if err := grpcServer.Serve(lis); err != nil {
+			logger.Error("ошибка запуска gRPC-сервера", "error", err)
+		}
+	}()
+
+	if metricsServer != nil {
+		go func() {
+			logger.Info("сервер метрик запущен", "addr", metricsServer.Addr)
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("ошибка запуска сервера метрик", "error", err)
+			}
+		}()
+	}
+
 	<-quit
 	logger.Info("получен сигнал завершения")
+	stopBackground()
+	// потоки посещаемости не завершаются сами, Shutdown ждал бы их до таймаута
+	hub.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -115,5 +242,23 @@ func main() {
 		logger.Error("ошибка graceful shutdown", "error", err)
 	}
 
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			logger.Error("ошибка остановки сервера метрик", "error", err)
+		}
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		logger.Warn("gRPC-сервер не остановился вовремя, вызовы прерваны")
+		grpcServer.Stop()
+	}
+
 	logger.Info("сервер остановлен")
 }
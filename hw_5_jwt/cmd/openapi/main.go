@@ -0,0 +1,53 @@
+// Команда openapi печатает спецификацию API и проверяет, что она описывает
+// все маршруты RegisterRoutes. В CI запускается с -check: ненулевой код выхода,
+// если маршрут добавлен без описания или описание осталось от удалённого маршрута.
+//
+//	go run ./cmd/openapi -check > openapi.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+
+	"github.com/labstack/echo/v4"
+
+	"hw_5_jwt/internal/handlers"
+)
+
+func main() {
+	check := flag.Bool("check", false, "завершиться с ошибкой при расхождении спецификации и маршрутов")
+	flag.Parse()
+
+	// обработчики не вызываются, поэтому репозиторий, уведомления и поток событий не нужны
+	e := echo.New()
+	h := handlers.NewHandler(nil, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	h.RegisterRoutes(e)
+
+	problems := handlers.CheckOpenAPI(e.Routes())
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+
+	rec := httptest.NewRecorder()
+	if err := h.GetOpenAPI(e.NewContext(httptest.NewRequest("GET", "/openapi.json", nil), rec)); err != nil {
+		fmt.Fprintln(os.Stderr, "ошибка построения спецификации:", err)
+		os.Exit(1)
+	}
+	var doc any
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		fmt.Fprintln(os.Stderr, "спецификация не является JSON:", err)
+		os.Exit(1)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(doc)
+
+	if *check && len(problems) > 0 {
+		os.Exit(1)
+	}
+}
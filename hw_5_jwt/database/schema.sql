@@ -66,7 +66,406 @@ CREATE TABLE IF NOT EXISTS attendance (
 );
 
 
-CREATE INDEX idx_users_email ON users(email);
-CREATE INDEX idx_attendance_student ON attendance(student_id);
-CREATE INDEX idx_attendance_schedule ON attendance(schedule_id);
-CREATE INDEX idx_schedule_group ON schedule(group_id);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_attendance_student ON attendance(student_id);
+CREATE INDEX IF NOT EXISTS idx_attendance_schedule ON attendance(schedule_id);
+CREATE INDEX IF NOT EXISTS idx_schedule_group ON schedule(group_id);
+ALTER TABLE attendance ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'absent'
+    CHECK (status IN ('present', 'late', 'absent', 'excused', 'remote'));
+ALTER TABLE attendance ADD COLUMN IF NOT EXISTS minutes_late INTEGER CHECK (minutes_late >= 0);
+ALTER TABLE attendance ADD COLUMN IF NOT EXISTS note TEXT;
+
+-- перенос старых данных: is_present = true -> present
+UPDATE attendance SET status = 'present' WHERE is_present AND status = 'absent';
+
+
+CREATE TABLE IF NOT EXISTS excuses (
+    excuse_id SERIAL PRIMARY KEY,
+    student_id INTEGER NOT NULL REFERENCES students(student_id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected')),
+    document_name VARCHAR(255),
+    document_type VARCHAR(100),
+    document BYTEA,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+
+CREATE TABLE IF NOT EXISTS excuse_attendance (
+    excuse_id INTEGER REFERENCES excuses(excuse_id) ON DELETE CASCADE,
+    attendance_id INTEGER REFERENCES attendance(attendance_id) ON DELETE CASCADE,
+    PRIMARY KEY (excuse_id, attendance_id)
+);
+
+
+CREATE TABLE IF NOT EXISTS excuse_history (
+    history_id SERIAL PRIMARY KEY,
+    excuse_id INTEGER NOT NULL REFERENCES excuses(excuse_id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    changed_by INTEGER REFERENCES users(id),
+    comment TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_excuses_student ON excuses(student_id);
+CREATE INDEX IF NOT EXISTS idx_excuse_history_excuse ON excuse_history(excuse_id);
+
+
+CREATE TABLE IF NOT EXISTS checkin_windows (
+    window_id SERIAL PRIMARY KEY,
+    schedule_id INTEGER NOT NULL REFERENCES schedule(schedule_id) ON DELETE CASCADE,
+    attendance_date DATE NOT NULL,
+    secret BYTEA NOT NULL,
+    opened_by INTEGER REFERENCES users(id),
+    opens_at TIMESTAMPTZ NOT NULL,
+    late_after TIMESTAMPTZ NOT NULL,
+    closes_at TIMESTAMPTZ NOT NULL,
+    closed_at TIMESTAMPTZ
+);
+
+
+CREATE TABLE IF NOT EXISTS checkin_submissions (
+    window_id INTEGER REFERENCES checkin_windows(window_id) ON DELETE CASCADE,
+    student_id INTEGER REFERENCES students(student_id) ON DELETE CASCADE,
+    code_step BIGINT NOT NULL,
+    submitted_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (window_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attendance_date ON attendance(attendance_date);
+
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS curator_user_id INTEGER REFERENCES users(id);
+
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+    rule_id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    threshold NUMERIC(4, 3) NOT NULL CHECK (threshold > 0 AND threshold <= 1),
+    min_lessons INTEGER NOT NULL DEFAULT 4,
+    channels TEXT[] NOT NULL DEFAULT ARRAY['inbox'],
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO alert_rules (name, threshold, channels)
+SELECT 'Пропущено 25% занятий по предмету', 0.25, ARRAY['inbox', 'email']
+WHERE NOT EXISTS (SELECT 1 FROM alert_rules);
+
+
+CREATE TABLE IF NOT EXISTS alert_events (
+    event_id SERIAL PRIMARY KEY,
+    rule_id INTEGER NOT NULL REFERENCES alert_rules(rule_id) ON DELETE CASCADE,
+    student_id INTEGER NOT NULL REFERENCES students(student_id) ON DELETE CASCADE,
+    subject_id INTEGER NOT NULL REFERENCES subjects(subject_id) ON DELETE CASCADE,
+    term VARCHAR(20) NOT NULL,
+    missed_rate DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (rule_id, student_id, subject_id, term)
+);
+
+
+CREATE TABLE IF NOT EXISTS notifications (
+    notification_id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, created_at DESC);
+
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    dispatched_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox_events(event_id) WHERE dispatched_at IS NULL;
+
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    subscription_id SERIAL PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret VARCHAR(100) NOT NULL,
+    events TEXT[] NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    delivery_id BIGSERIAL PRIMARY KEY,
+    event_id BIGINT NOT NULL REFERENCES outbox_events(event_id) ON DELETE CASCADE,
+    subscription_id INTEGER NOT NULL REFERENCES webhook_subscriptions(subscription_id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'succeeded', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INTEGER,
+    last_error TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (event_id, subscription_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    attempt_id BIGSERIAL PRIMARY KEY,
+    delivery_id BIGINT NOT NULL REFERENCES webhook_deliveries(delivery_id) ON DELETE CASCADE,
+    status_code INTEGER,
+    error TEXT,
+    duration_ms INTEGER NOT NULL,
+    attempted_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+
+CREATE TABLE IF NOT EXISTS assessments (
+    assessment_id SERIAL PRIMARY KEY,
+    subject_id INTEGER NOT NULL REFERENCES subjects(subject_id) ON DELETE CASCADE,
+    group_id INTEGER NOT NULL REFERENCES groups(group_id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('exam', 'midterm', 'lab', 'quiz')),
+    title VARCHAR(255) NOT NULL,
+    term VARCHAR(20) NOT NULL,
+    weight NUMERIC(6, 2) NOT NULL CHECK (weight > 0),
+    max_score NUMERIC(6, 2) NOT NULL CHECK (max_score > 0),
+    held_on DATE,
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_assessments_subject_group ON assessments(subject_id, group_id, term);
+
+
+CREATE TABLE IF NOT EXISTS grades (
+    grade_id SERIAL PRIMARY KEY,
+    assessment_id INTEGER NOT NULL REFERENCES assessments(assessment_id) ON DELETE CASCADE,
+    student_id INTEGER NOT NULL REFERENCES students(student_id) ON DELETE CASCADE,
+    score NUMERIC(6, 2) NOT NULL CHECK (score >= 0),
+    comment TEXT,
+    graded_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (assessment_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_grades_student ON grades(student_id);
+
+
+CREATE TABLE IF NOT EXISTS grading_scale (
+    letter VARCHAR(3) PRIMARY KEY,
+    min_percent NUMERIC(5, 2) NOT NULL UNIQUE CHECK (min_percent >= 0 AND min_percent <= 100),
+    gpa_points NUMERIC(3, 2) NOT NULL CHECK (gpa_points >= 0)
+);
+
+INSERT INTO grading_scale (letter, min_percent, gpa_points)
+SELECT letter, min_percent, gpa_points
+FROM (VALUES
+    ('A', 95, 4.00), ('A-', 90, 3.67),
+    ('B+', 85, 3.33), ('B', 80, 3.00), ('B-', 75, 2.67),
+    ('C+', 70, 2.33), ('C', 65, 2.00), ('C-', 60, 1.67),
+    ('D+', 55, 1.33), ('D', 50, 1.00),
+    ('F', 0, 0.00)
+) AS s(letter, min_percent, gpa_points)
+WHERE NOT EXISTS (SELECT 1 FROM grading_scale);
+
+ALTER TABLE subjects ADD COLUMN IF NOT EXISTS credits INTEGER NOT NULL DEFAULT 3 CHECK (credits > 0);
+
+
+-- семестр по дате, как terms.For в Go: сентябрь–январь — осенний, остальное — весенний
+CREATE OR REPLACE FUNCTION term_of(d DATE) RETURNS VARCHAR AS $$
+    SELECT CASE
+        WHEN EXTRACT(MONTH FROM d) >= 9 THEN EXTRACT(YEAR FROM d)::int || '-fall'
+        WHEN EXTRACT(MONTH FROM d) = 1 THEN (EXTRACT(YEAR FROM d)::int - 1) || '-fall'
+        ELSE EXTRACT(YEAR FROM d)::int || '-spring'
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+
+
+-- предложение предмета на семестр: занятия берутся из расписания группы group_id,
+-- а состав слушателей — из enrollments, а не из students.group_id
+CREATE TABLE IF NOT EXISTS subject_offerings (
+    offering_id SERIAL PRIMARY KEY,
+    subject_id INTEGER NOT NULL REFERENCES subjects(subject_id) ON DELETE CASCADE,
+    group_id INTEGER NOT NULL REFERENCES groups(group_id) ON DELETE CASCADE,
+    term VARCHAR(20) NOT NULL,
+    capacity INTEGER CHECK (capacity > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (subject_id, group_id, term)
+);
+
+
+CREATE TABLE IF NOT EXISTS enrollments (
+    enrollment_id SERIAL PRIMARY KEY,
+    offering_id INTEGER NOT NULL REFERENCES subject_offerings(offering_id) ON DELETE CASCADE,
+    student_id INTEGER NOT NULL REFERENCES students(student_id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('enrolled', 'waitlisted', 'dropped')),
+    requested_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (offering_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_enrollments_student ON enrollments(student_id, status);
+CREATE INDEX IF NOT EXISTS idx_enrollments_waitlist ON enrollments(offering_id, requested_at) WHERE status = 'waitlisted';
+
+-- первичный перенос: группы записываются на предметы своего расписания за семестры
+-- с посещаемостью или оценками и за текущий семестр. Выполняется один раз, пока
+-- enrollments пуста; новых студентов записывает импорт (ImportStudents)
+INSERT INTO subject_offerings (subject_id, group_id, term)
+SELECT DISTINCT sch.subject_id, sch.group_id, t.term
+FROM schedule sch
+JOIN LATERAL (
+    SELECT term_of(CURRENT_DATE) AS term
+    UNION
+    SELECT DISTINCT term_of(a.attendance_date) FROM attendance a WHERE a.schedule_id = sch.schedule_id
+    UNION
+    SELECT DISTINCT ass.term FROM assessments ass
+    WHERE ass.subject_id = sch.subject_id AND ass.group_id = sch.group_id
+) t ON true
+WHERE sch.subject_id IS NOT NULL AND sch.group_id IS NOT NULL
+    AND NOT EXISTS (SELECT 1 FROM enrollments)
+ON CONFLICT (subject_id, group_id, term) DO NOTHING;
+
+INSERT INTO enrollments (offering_id, student_id, status)
+SELECT o.offering_id, s.student_id, 'enrolled'
+FROM subject_offerings o
+JOIN students s ON s.group_id = o.group_id
+WHERE NOT EXISTS (SELECT 1 FROM enrollments)
+ON CONFLICT (offering_id, student_id) DO NOTHING;
+
+
+-- назначения преподавателей заменяют единственный teachers.subject_id
+CREATE TABLE IF NOT EXISTS teacher_assignments (
+    assignment_id SERIAL PRIMARY KEY,
+    teacher_id INTEGER NOT NULL REFERENCES teachers(id) ON DELETE CASCADE,
+    subject_id INTEGER NOT NULL REFERENCES subjects(subject_id) ON DELETE CASCADE,
+    group_id INTEGER NOT NULL REFERENCES groups(group_id) ON DELETE CASCADE,
+    term VARCHAR(20) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('lecturer', 'lab_assistant')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (teacher_id, subject_id, group_id, term, role)
+);
+
+CREATE INDEX IF NOT EXISTS idx_teacher_assignments_subject ON teacher_assignments(subject_id, group_id, term);
+
+ALTER TABLE teachers ADD COLUMN IF NOT EXISTS max_weekly_hours NUMERIC(5, 2) NOT NULL DEFAULT 20
+    CHECK (max_weekly_hours > 0);
+
+-- перенос teachers.subject_id: лектор во всех группах с этим предметом в расписании, текущий семестр
+INSERT INTO teacher_assignments (teacher_id, subject_id, group_id, term, role)
+SELECT DISTINCT t.id, t.subject_id, sch.group_id, term_of(CURRENT_DATE), 'lecturer'
+FROM teachers t
+JOIN schedule sch ON sch.subject_id = t.subject_id
+WHERE sch.group_id IS NOT NULL
+    AND NOT EXISTS (SELECT 1 FROM teacher_assignments)
+ON CONFLICT DO NOTHING;
+
+
+CREATE TABLE IF NOT EXISTS rooms (
+    room_id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    building VARCHAR(100) NOT NULL,
+    capacity INTEGER NOT NULL CHECK (capacity > 0),
+    equipment TEXT[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_rooms_equipment ON rooms USING GIN (equipment);
+
+ALTER TABLE schedule ADD COLUMN IF NOT EXISTS room_id INTEGER REFERENCES rooms(room_id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_schedule_room ON schedule(room_id, day_of_week);
+
+
+CREATE TABLE IF NOT EXISTS teacher_availability (
+    availability_id SERIAL PRIMARY KEY,
+    teacher_id INTEGER NOT NULL REFERENCES teachers(id) ON DELETE CASCADE,
+    day_of_week INTEGER NOT NULL CHECK (day_of_week BETWEEN 1 AND 7),
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    preferred BOOLEAN NOT NULL DEFAULT false,
+    CHECK (start_time < end_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_teacher_availability_teacher ON teacher_availability(teacher_id);
+
+CREATE TABLE IF NOT EXISTS timetable_drafts (
+    draft_id SERIAL PRIMARY KEY,
+    term VARCHAR(20) NOT NULL,
+    seed BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'published', 'discarded')),
+    penalty INTEGER NOT NULL DEFAULT 0,
+    steps INTEGER NOT NULL DEFAULT 0,
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS timetable_draft_lessons (
+    draft_lesson_id SERIAL PRIMARY KEY,
+    draft_id INTEGER NOT NULL REFERENCES timetable_drafts(draft_id) ON DELETE CASCADE,
+    group_id INTEGER NOT NULL REFERENCES groups(group_id),
+    subject_id INTEGER NOT NULL REFERENCES subjects(subject_id),
+    teacher_id INTEGER NOT NULL REFERENCES teachers(id),
+    lesson_name VARCHAR(100) NOT NULL,
+    day_of_week INTEGER NOT NULL,
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    room_id INTEGER REFERENCES rooms(room_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_timetable_draft_lessons_draft ON timetable_draft_lessons(draft_id);
+
+
+CREATE TABLE IF NOT EXISTS holidays (
+    holiday_date DATE PRIMARY KEY,
+    name VARCHAR(100) NOT NULL
+);
+
+-- хранится только SHA-256 секрета ссылки на календарь
+ALTER TABLE users ADD COLUMN IF NOT EXISTS feed_token_hash VARCHAR(64);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_feed_token ON users(feed_token_hash);
+
+-- язык сообщений API из профиля; NULL — по Accept-Language
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language VARCHAR(5);
+
+
+-- событие посещаемости для трансляции: данные outbox с предметом, группой и именем
+-- студента, чтобы экземпляры API фильтровали подписчиков без запросов к базе
+CREATE OR REPLACE FUNCTION attendance_stream_event(e outbox_events) RETURNS JSONB AS $$
+    SELECT e.payload || jsonb_build_object(
+        'event_id', e.event_id,
+        'subject_id', sc.subject_id,
+        'group_id', sc.group_id,
+        'name', st.name,
+        'surname', st.surname,
+        'recorded_at', e.created_at
+    )
+    FROM (SELECT 1) AS one
+    LEFT JOIN schedule sc ON sc.schedule_id = (e.payload->>'schedule_id')::int
+    LEFT JOIN students st ON st.student_id = (e.payload->>'student_id')::int
+$$ LANGUAGE SQL STABLE;
+
+-- NOTIFY доставляется слушателям при фиксации транзакции, откаченные отметки не видны
+CREATE OR REPLACE FUNCTION notify_attendance_event() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('attendance_events', attendance_stream_event(NEW)::text);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS outbox_attendance_notify ON outbox_events;
+CREATE TRIGGER outbox_attendance_notify
+    AFTER INSERT ON outbox_events
+    FOR EACH ROW WHEN (NEW.event_type = 'attendance.recorded')
+    EXECUTE FUNCTION notify_attendance_event();
@@ -0,0 +1,60 @@
+// Package export пишет табличные выгрузки построчно в CSV, XLSX и PDF,
+// не собирая таблицу в памяти.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatPDF  = "pdf"
+)
+
+// ContentTypes — MIME-тип ответа для каждого формата.
+var ContentTypes = map[string]string{
+	FormatCSV:  "text/csv; charset=utf-8",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatPDF:  "application/pdf",
+}
+
+// Writer принимает строку заголовка и строки данных; Close дописывает файл.
+// Числа передаются строками: XLSX сам определяет числовые ячейки.
+type Writer interface {
+	Header(cells []string) error
+	Row(cells []string) error
+	Close() error
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// NewCSV пишет CSV с разделителем ';' и BOM — так файл сразу открывается в Excel с кириллицей.
+func NewCSV(w io.Writer) (Writer, error) {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return nil, fmt.Errorf("ошибка записи CSV: %w", err)
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = ';'
+	return &csvWriter{w: cw}, nil
+}
+
+func (c *csvWriter) Header(cells []string) error {
+	return c.Row(cells)
+}
+
+func (c *csvWriter) Row(cells []string) error {
+	if err := c.w.Write(cells); err != nil {
+		return fmt.Errorf("ошибка записи CSV: %w", err)
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,98 @@
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"hw_5_jwt/internal/pdf"
+)
+
+const pdfFontSize = 7.0
+
+// PDF — печатная таблица на альбомных страницах A4. Каждый Header начинает новую
+// страницу; шапка повторяется на всех страницах, куда перешла таблица.
+type PDF struct {
+	s      *pdf.Stream
+	title  []string
+	widths []float64
+	xs     []float64
+	header []string
+}
+
+func NewPDF(w io.Writer, title ...string) *PDF {
+	p := &PDF{s: pdf.NewStream(w, pdf.PageHeight, pdf.PageWidth), title: title}
+	p.s.OnPage = p.pageHeader
+	return p
+}
+
+// Width — ширина области печати в пунктах.
+func (p *PDF) Width() float64 {
+	return p.s.Width() - 2*pdf.Margin
+}
+
+// SetWidths задаёт ширины колонок для следующего Header.
+func (p *PDF) SetWidths(widths []float64) {
+	p.widths = widths
+}
+
+func (p *PDF) pageHeader() {
+	for i, line := range p.title {
+		size, bold := 9.0, false
+		if i == 0 {
+			size, bold = 12, true
+		}
+		p.s.Row([]float64{pdf.Margin}, size, bold, line)
+	}
+	p.s.Space(4)
+	p.s.Row(p.xs, pdfFontSize, true, p.fit(p.header, true)...)
+	p.s.Rule()
+}
+
+func (p *PDF) Header(cells []string) error {
+	p.header = cells
+	p.xs = make([]float64, len(cells))
+	x := pdf.Margin
+	for i := range cells {
+		p.xs[i] = x
+		x += p.width(i)
+	}
+	p.s.AddPage()
+	return nil
+}
+
+func (p *PDF) Row(cells []string) error {
+	p.s.Row(p.xs, pdfFontSize, false, p.fit(cells, false)...)
+	return nil
+}
+
+func (p *PDF) Close() error {
+	if err := p.s.Close(); err != nil {
+		return fmt.Errorf("ошибка записи PDF: %w", err)
+	}
+	return nil
+}
+
+func (p *PDF) width(i int) float64 {
+	if i < len(p.widths) {
+		return p.widths[i]
+	}
+	return 30
+}
+
+// fit обрезает ячейки по ширине колонки. Ширина символа Helvetica оценивается
+// средним значением — точные метрики для таблицы не нужны.
+func (p *PDF) fit(cells []string, bold bool) []string {
+	charWidth := pdfFontSize * 0.52
+	if bold {
+		charWidth = pdfFontSize * 0.58
+	}
+	out := make([]string, len(cells))
+	for i, cell := range cells {
+		limit := int((p.width(i) - 2) / charWidth)
+		if r := []rune(cell); len(r) > limit && limit > 0 {
+			cell = string(r[:limit-1]) + "."
+		}
+		out[i] = cell
+	}
+	return out
+}
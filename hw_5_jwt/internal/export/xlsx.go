@@ -0,0 +1,165 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const xlsxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// статические части книги; единственный лист пишется построчно
+var xlsxParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	// стиль 1 — жирный шрифт для заголовка
+	{"xl/styles.xml", `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`},
+}
+
+type xlsxWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	row   int
+}
+
+// NewXLSX начинает книгу с одним листом. Строки сразу сжимаются в w,
+// поэтому размер выгрузки не ограничен памятью.
+func NewXLSX(w io.Writer, sheetName string) (Writer, error) {
+	zw := zip.NewWriter(w)
+
+	parts := append(xlsxParts, struct{ name, body string }{"xl/workbook.xml",
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + escapeXML(sheetTitle(sheetName)) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`})
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка записи XLSX: %w", err)
+		}
+		if _, err := io.WriteString(f, xlsxHeader+part.body); err != nil {
+			return nil, fmt.Errorf("ошибка записи XLSX: %w", err)
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка записи XLSX: %w", err)
+	}
+	sheet := bufio.NewWriter(f)
+	sheet.WriteString(xlsxHeader)
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	// первая строка закреплена, чтобы заголовок оставался на экране при прокрутке
+	sheet.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	sheet.WriteString(`<sheetData>`)
+
+	return &xlsxWriter{zw: zw, sheet: sheet}, nil
+}
+
+func (x *xlsxWriter) Header(cells []string) error {
+	return x.write(cells, 1)
+}
+
+func (x *xlsxWriter) Row(cells []string) error {
+	return x.write(cells, 0)
+}
+
+func (x *xlsxWriter) write(cells []string, style int) error {
+	x.row++
+	fmt.Fprintf(x.sheet, `<row r="%d">`, x.row)
+	for i, cell := range cells {
+		if cell == "" {
+			continue
+		}
+		ref := columnName(i) + strconv.Itoa(x.row)
+		styleAttr := ""
+		if style != 0 {
+			styleAttr = fmt.Sprintf(` s="%d"`, style)
+		}
+		// заголовок всегда текстовый: подписи вроде "01.09" Excel превратил бы в число
+		if style == 0 && isNumber(cell) {
+			fmt.Fprintf(x.sheet, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr, cell)
+			continue
+		}
+		fmt.Fprintf(x.sheet, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, styleAttr, escapeXML(cell))
+	}
+	if _, err := x.sheet.WriteString(`</row>`); err != nil {
+		return fmt.Errorf("ошибка записи XLSX: %w", err)
+	}
+	return nil
+}
+
+func (x *xlsxWriter) Close() error {
+	x.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := x.sheet.Flush(); err != nil {
+		return fmt.Errorf("ошибка записи XLSX: %w", err)
+	}
+	if err := x.zw.Close(); err != nil {
+		return fmt.Errorf("ошибка записи XLSX: %w", err)
+	}
+	return nil
+}
+
+// columnName переводит индекс колонки в буквенное имя: 0 → A, 26 → AA.
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// isNumber отсекает "NaN", "Inf" и строки вроде "+", которые ParseFloat считает
+// числами или которые должны остаться текстом.
+func isNumber(s string) bool {
+	digits := strings.TrimPrefix(s, "-")
+	if digits == "" || digits[0] < '0' || digits[0] > '9' {
+		return false
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// sheetTitle приводит название к ограничениям Excel: до 31 символа, без []:*?/\.
+func sheetTitle(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	if name == "" {
+		name = "Sheet1"
+	}
+	return name
+}
@@ -0,0 +1,63 @@
+package graphql
+
+import "context"
+
+// Root — резолвер поля корневого типа: у Query ровно один родитель.
+func Root(resolve func(ctx context.Context, args Args) (any, error)) ResolveFunc {
+	return func(ctx context.Context, parents []any, args Args) ([]any, error) {
+		value, err := resolve(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]any, len(parents))
+		for i := range out {
+			out[i] = value
+		}
+		return out, nil
+	}
+}
+
+// Prop — поле, которое берётся из самого родителя без обращения к базе.
+func Prop[P any](get func(P) any) ResolveFunc {
+	return func(_ context.Context, parents []any, _ Args) ([]any, error) {
+		out := make([]any, len(parents))
+		for i, parent := range parents {
+			out[i] = get(parent.(P))
+		}
+		return out, nil
+	}
+}
+
+// Load загружает связанные объекты всех родителей одним вызовом load по их ключам
+// без повторов. Родитель с нулевым ключом или без найденного значения получает nil:
+// null для объектного поля и пустой список для поля [T]!.
+func Load[P any, K comparable, V any](key func(P) K, load func(ctx context.Context, keys []K, args Args) (map[K]V, error)) ResolveFunc {
+	return func(ctx context.Context, parents []any, args Args) ([]any, error) {
+		var zero K
+		keys := make([]K, 0, len(parents))
+		seen := make(map[K]struct{}, len(parents))
+		for _, parent := range parents {
+			k := key(parent.(P))
+			if _, dup := seen[k]; dup || k == zero {
+				continue
+			}
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+
+		out := make([]any, len(parents))
+		if len(keys) == 0 {
+			return out, nil
+		}
+		values, err := load(ctx, keys, args)
+		if err != nil {
+			return nil, err
+		}
+		for i, parent := range parents {
+			if v, ok := values[key(parent.(P))]; ok {
+				out[i] = v
+			}
+		}
+		return out, nil
+	}
+}
@@ -0,0 +1,688 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+
+	"hw_5_jwt/internal/i18n"
+)
+
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type Response struct {
+	Data   any      `json:"data,omitempty"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Error — ошибка в формате GraphQL. Err — исходная ошибка: для сообщений каталога
+// i18n вызывающий заполняет Message на языке запроса.
+type Error struct {
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+	Err        error          `json:"-"`
+}
+
+func (e *Error) Error() string {
+	if e.Message != "" || e.Err == nil {
+		return e.Message
+	}
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func toError(err error, loc Location, path []any) *Error {
+	var gqlErr *Error
+	if errors.As(err, &gqlErr) {
+		return gqlErr
+	}
+	return &Error{Err: err, Locations: []Location{loc}, Path: path}
+}
+
+// Execute разбирает, проверяет и выполняет запрос. Ошибки разбора и проверки
+// возвращаются без data; ошибки отдельных полей — вместе с остальными данными,
+// а на месте поля будет null.
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{toError(err, Location{}, nil)}}
+	}
+	if err := checkFragmentCycles(doc); err != nil {
+		return &Response{Errors: []*Error{err}}
+	}
+
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{toError(err, Location{}, nil)}}
+	}
+	if op.Kind != "query" {
+		return &Response{Errors: []*Error{{Err: i18n.Errorf("graphql.operation_unsupported", op.Kind), Locations: []Location{op.Loc}}}}
+	}
+
+	p := &planner{schema: s, doc: doc, defs: map[string]*VariableDefinition{}}
+	p.coerceVariables(op, req.Variables)
+	if len(p.errors) > 0 {
+		return &Response{Errors: p.errors}
+	}
+
+	fields := p.plan(s.Query, op.SelectionSet, 1)
+	if len(p.errors) > 0 {
+		return &Response{Errors: p.errors}
+	}
+	if cost := complexity(fields); cost > s.MaxComplexity {
+		return &Response{Errors: []*Error{{Err: i18n.Errorf("graphql.too_complex", cost, s.MaxComplexity), Locations: []Location{op.Loc}}}}
+	}
+
+	ex := &executor{}
+	data := ex.resolve(ctx, s.Query, fields, []any{nil}, nil)
+	if data[0] == nil {
+		return &Response{Data: nullData, Errors: ex.errors}
+	}
+	return &Response{Data: data[0], Errors: ex.errors}
+}
+
+// nullData — data: null в ответе: запрос выполнялся, но null поднялся до корня.
+// Без data остаются только ответы с ошибками разбора и проверки.
+var nullData = json.RawMessage("null")
+
+func selectOperation(doc *Document, name string) (*Operation, error) {
+	if name == "" {
+		if len(doc.Operations) != 1 {
+			return nil, i18n.Errorf("graphql.operation_required")
+		}
+		return doc.Operations[0], nil
+	}
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, i18n.Errorf("graphql.operation_not_found", name)
+}
+
+// checkFragmentCycles находит фрагменты, которые через вложенные поля ссылаются
+// сами на себя: такой запрос бесконечен.
+func checkFragmentCycles(doc *Document) *Error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+
+	var visit func(name string) *Error
+	var walk func(set []Selection) *Error
+	walk = func(set []Selection) *Error {
+		for _, sel := range set {
+			switch sel := sel.(type) {
+			case *Field:
+				if err := walk(sel.SelectionSet); err != nil {
+					return err
+				}
+			case *InlineFragment:
+				if err := walk(sel.SelectionSet); err != nil {
+					return err
+				}
+			case *FragmentSpread:
+				if state[sel.Name] == visiting {
+					return &Error{Err: i18n.Errorf("graphql.fragment_cycle", sel.Name), Locations: []Location{sel.Loc}}
+				}
+				if err := visit(sel.Name); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	visit = func(name string) *Error {
+		fragment, ok := doc.Fragments[name]
+		if !ok || state[name] == done {
+			return nil
+		}
+		state[name] = visiting
+		if err := walk(fragment.SelectionSet); err != nil {
+			return err
+		}
+		state[name] = done
+		return nil
+	}
+
+	for name := range doc.Fragments {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// planned — поле после раскрытия фрагментов и директив с вычисленными аргументами.
+type planned struct {
+	key      string
+	def      *FieldDef
+	args     Args
+	object   *Object
+	children []*planned
+	loc      Location
+}
+
+type planner struct {
+	schema  *Schema
+	doc     *Document
+	defs    map[string]*VariableDefinition
+	vars    map[string]any
+	errors  []*Error
+	tooDeep bool
+}
+
+func (p *planner) fail(loc Location, id string, args ...any) {
+	p.errors = append(p.errors, &Error{Err: i18n.Errorf(id, args...), Locations: []Location{loc}})
+}
+
+func (p *planner) coerceVariables(op *Operation, input map[string]any) {
+	p.vars = map[string]any{}
+	for _, def := range op.Variables {
+		p.defs[def.Name] = def
+		if _, ok := scalars[def.Type.Named()]; !ok {
+			p.fail(def.Loc, "graphql.unknown_type", def.Type.Named())
+			continue
+		}
+
+		raw, provided := input[def.Name]
+		switch {
+		case provided:
+			value, ok := coerceInput(raw, def.Type)
+			if !ok {
+				p.fail(def.Loc, "graphql.invalid_variable", def.Name, def.Type.String())
+				continue
+			}
+			p.vars[def.Name] = value
+		case def.Default != nil:
+			value, ok := p.coerceLiteral(def.Default, def.Type)
+			if !ok {
+				p.fail(def.Loc, "graphql.invalid_variable", def.Name, def.Type.String())
+				continue
+			}
+			p.vars[def.Name] = value
+		case def.Type.NonNull:
+			p.fail(def.Loc, "graphql.missing_variable", def.Name)
+		}
+	}
+}
+
+// plan проверяет выборку полей типа obj и раскрывает её в дерево planned.
+func (p *planner) plan(obj *Object, set []Selection, depth int) []*planned {
+	if depth > p.schema.MaxDepth {
+		if !p.tooDeep {
+			p.tooDeep = true
+			p.fail(set[0].location(), "graphql.too_deep", p.schema.MaxDepth)
+		}
+		return nil
+	}
+
+	var keys []string
+	groups := map[string][]*Field{}
+	p.collect(obj, set, &keys, groups)
+
+	fields := make([]*planned, 0, len(keys))
+	for _, key := range keys {
+		group := groups[key]
+		first := group[0]
+		for _, f := range group[1:] {
+			if f.Name != first.Name {
+				p.fail(f.Loc, "graphql.field_conflict", key)
+			}
+		}
+
+		if first.Name == "__typename" {
+			if first.SelectionSet != nil {
+				p.fail(first.Loc, "graphql.selection_not_allowed", first.Name)
+			}
+			fields = append(fields, &planned{key: key, loc: first.Loc})
+			continue
+		}
+
+		def := obj.byName[first.Name]
+		if def == nil {
+			p.fail(first.Loc, "graphql.unknown_field", first.Name, obj.Name)
+			continue
+		}
+
+		f := &planned{key: key, def: def, args: p.arguments(def, first), loc: first.Loc}
+		var set []Selection
+		for _, g := range group {
+			set = append(set, g.SelectionSet...)
+		}
+		if child, ok := p.schema.types[def.typ.Named()]; ok {
+			if len(set) == 0 {
+				p.fail(first.Loc, "graphql.selection_required", first.Name, def.Type)
+				continue
+			}
+			f.object = child
+			f.children = p.plan(child, set, depth+1)
+		} else if len(set) > 0 {
+			p.fail(first.Loc, "graphql.selection_not_allowed", first.Name)
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// collect собирает поля выборки по ключам ответа в порядке появления, раскрывая
+// фрагменты и учитывая @include и @skip.
+func (p *planner) collect(obj *Object, set []Selection, keys *[]string, groups map[string][]*Field) {
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *Field:
+			if !p.included(sel.Directives) {
+				continue
+			}
+			key := sel.ResponseKey()
+			if _, seen := groups[key]; !seen {
+				*keys = append(*keys, key)
+			}
+			groups[key] = append(groups[key], sel)
+		case *InlineFragment:
+			if p.included(sel.Directives) && p.applies(obj, sel.TypeCondition, sel.Loc) {
+				p.collect(obj, sel.SelectionSet, keys, groups)
+			}
+		case *FragmentSpread:
+			if !p.included(sel.Directives) {
+				continue
+			}
+			fragment, ok := p.doc.Fragments[sel.Name]
+			if !ok {
+				p.fail(sel.Loc, "graphql.unknown_fragment", sel.Name)
+				continue
+			}
+			if p.applies(obj, fragment.TypeCondition, fragment.Loc) {
+				p.collect(obj, fragment.SelectionSet, keys, groups)
+			}
+		}
+	}
+}
+
+// applies сообщает, относится ли фрагмент с условием типа к объекту obj. В схеме
+// нет интерфейсов и объединений, поэтому условие — имя самого типа.
+func (p *planner) applies(obj *Object, condition string, loc Location) bool {
+	if condition == "" || condition == obj.Name {
+		return true
+	}
+	if _, ok := p.schema.types[condition]; !ok {
+		p.fail(loc, "graphql.unknown_type", condition)
+	}
+	return false
+}
+
+func (p *planner) included(directives []*Directive) bool {
+	for _, d := range directives {
+		if d.Name != "include" && d.Name != "skip" {
+			p.fail(d.Loc, "graphql.unknown_directive", d.Name)
+			continue
+		}
+		if len(d.Arguments) != 1 || d.Arguments[0].Name != "if" {
+			p.fail(d.Loc, "graphql.missing_argument", "if", "@"+d.Name)
+			continue
+		}
+		value, ok := p.coerceLiteral(d.Arguments[0].Value, &TypeRef{Name: "Boolean", NonNull: true})
+		if !ok {
+			p.fail(d.Arguments[0].Loc, "graphql.invalid_argument", "if", "Boolean!")
+			continue
+		}
+		if value.(bool) == (d.Name == "skip") {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *planner) arguments(def *FieldDef, f *Field) Args {
+	args := Args{}
+	for _, a := range f.Arguments {
+		var argDef *ArgDef
+		for _, candidate := range def.Args {
+			if candidate.Name == a.Name {
+				argDef = candidate
+			}
+		}
+		if argDef == nil {
+			p.fail(a.Loc, "graphql.unknown_argument", a.Name, def.Name)
+			continue
+		}
+
+		if a.Value.Kind == VariableValue && p.defs[a.Value.Raw] == nil {
+			p.fail(a.Value.Loc, "graphql.undefined_variable", a.Value.Raw)
+			continue
+		}
+		value, ok := p.coerceLiteral(a.Value, argDef.typ)
+		if !ok {
+			p.fail(a.Loc, "graphql.invalid_argument", a.Name, argDef.Type)
+			continue
+		}
+		if value != nil {
+			args[a.Name] = value
+		}
+	}
+
+	for _, argDef := range def.Args {
+		if argDef.typ.NonNull && args[argDef.Name] == nil {
+			p.fail(f.Loc, "graphql.missing_argument", argDef.Name, def.Name)
+		}
+	}
+	return args
+}
+
+// coerceLiteral приводит значение из текста запроса к типу t.
+func (p *planner) coerceLiteral(v *Value, t *TypeRef) (any, bool) {
+	switch v.Kind {
+	case VariableValue:
+		if p.defs[v.Raw] == nil {
+			return nil, false
+		}
+		return coerceInput(p.vars[v.Raw], t)
+	case NullValue:
+		return nil, !t.NonNull
+	case ListValue:
+		if t.Elem == nil {
+			return nil, false
+		}
+		list := make([]any, len(v.List))
+		for i, item := range v.List {
+			value, ok := p.coerceLiteral(item, t.Elem)
+			if !ok {
+				return nil, false
+			}
+			list[i] = value
+		}
+		return list, true
+	}
+
+	if t.Elem != nil {
+		value, ok := p.coerceLiteral(v, t.Elem)
+		return []any{value}, ok
+	}
+
+	switch {
+	case t.Name == "Int" && v.Kind == IntValue:
+		n, err := strconv.ParseInt(v.Raw, 10, 32)
+		return int(n), err == nil
+	case t.Name == "Float" && (v.Kind == IntValue || v.Kind == FloatValue):
+		f, err := strconv.ParseFloat(v.Raw, 64)
+		return f, err == nil
+	case t.Name == "String" && v.Kind == StringValue,
+		t.Name == "ID" && (v.Kind == StringValue || v.Kind == IntValue):
+		return v.Raw, true
+	case t.Name == "Boolean" && v.Kind == BooleanValue:
+		return v.Raw == "true", true
+	}
+	return nil, false
+}
+
+// coerceInput приводит значение переменной из JSON к типу t.
+func coerceInput(raw any, t *TypeRef) (any, bool) {
+	if raw == nil {
+		return nil, !t.NonNull
+	}
+	if t.Elem != nil {
+		items, ok := raw.([]any)
+		if !ok {
+			value, ok := coerceInput(raw, t.Elem)
+			return []any{value}, ok
+		}
+		list := make([]any, len(items))
+		for i, item := range items {
+			value, ok := coerceInput(item, t.Elem)
+			if !ok {
+				return nil, false
+			}
+			list[i] = value
+		}
+		return list, true
+	}
+
+	switch t.Name {
+	case "Int":
+		switch n := raw.(type) {
+		case int:
+			return n, n >= math.MinInt32 && n <= math.MaxInt32
+		case float64:
+			return int(n), n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32
+		}
+	case "Float":
+		switch n := raw.(type) {
+		case int:
+			return float64(n), true
+		case float64:
+			return n, true
+		}
+	case "String":
+		s, ok := raw.(string)
+		return s, ok
+	case "ID":
+		switch id := raw.(type) {
+		case string:
+			return id, true
+		case int:
+			return strconv.Itoa(id), true
+		case float64:
+			return strconv.FormatFloat(id, 'f', -1, 64), id == math.Trunc(id)
+		}
+	case "Boolean":
+		b, ok := raw.(bool)
+		return b, ok
+	}
+	return nil, false
+}
+
+func isList(t *TypeRef) bool {
+	return t.Elem != nil
+}
+
+// complexity — сумма весов полей; поля внутри списков считаются listFactor раз.
+func complexity(fields []*planned) int {
+	total := 0
+	for _, f := range fields {
+		if f.def == nil {
+			continue
+		}
+		cost := max(f.def.Cost, 1)
+		children := complexity(f.children)
+		if isList(f.def.typ) {
+			children *= listFactor
+		}
+		total += cost + children
+	}
+	return total
+}
+
+type executor struct {
+	errors []*Error
+}
+
+type entry struct {
+	key   string
+	value any
+}
+
+// object — объект ответа с полями в порядке запроса.
+type object []entry
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, e := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(e.key)
+		b.Write(key)
+		b.WriteByte(':')
+		value, err := json.Marshal(e.value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// resolve вычисляет поля сразу для всех родителей: каждый резолвер вызывается один
+// раз на уровень, дочерние объекты всех родителей обрабатываются вместе.
+func (ex *executor) resolve(ctx context.Context, obj *Object, fields []*planned, parents []any, path []any) []object {
+	out := make([]object, len(parents))
+	for i := range out {
+		out[i] = make(object, len(fields))
+		for j, f := range fields {
+			out[i][j].key = f.key
+		}
+	}
+
+	// reported[i][j] — null в поле уже объяснён ошибкой самого поля или вложенного
+	reported := make([][]bool, len(parents))
+	for i := range reported {
+		reported[i] = make([]bool, len(fields))
+	}
+	paths := make([][]any, len(fields))
+
+	for j, f := range fields {
+		fieldPath := append(path[:len(path):len(path)], f.key)
+		paths[j] = fieldPath
+		if f.def == nil {
+			for i := range out {
+				out[i][j].value = obj.Name
+			}
+			continue
+		}
+
+		values, err := f.def.Resolve(ctx, parents, f.args)
+		if err == nil && len(values) != len(parents) {
+			err = errors.New("graphql: резолвер " + obj.Name + "." + f.def.Name + " вернул не столько значений, сколько родителей")
+		}
+		if err != nil {
+			ex.errors = append(ex.errors, toError(err, f.loc, fieldPath))
+			for i := range reported {
+				reported[i][j] = true
+			}
+			continue
+		}
+
+		if f.object == nil {
+			for i := range out {
+				out[i][j].value = values[i]
+			}
+			continue
+		}
+
+		// дочерние объекты всех родителей подряд; span помнит, где чьи
+		type span struct {
+			start, n int
+			null     bool
+		}
+		spans := make([]span, len(values))
+		var children []any
+		for i, v := range values {
+			switch {
+			case isList(f.def.typ):
+				items := listItems(v)
+				if items == nil && !f.def.typ.NonNull {
+					spans[i].null = true
+					continue
+				}
+				spans[i] = span{start: len(children), n: len(items)}
+				children = append(children, items...)
+			case isNull(v):
+				spans[i].null = true
+			default:
+				spans[i] = span{start: len(children), n: 1}
+				children = append(children, v)
+			}
+		}
+
+		results := ex.resolve(ctx, f.object, f.children, children, fieldPath)
+		for i, s := range spans {
+			switch {
+			case s.null:
+			case isList(f.def.typ):
+				list := make([]any, s.n)
+				for k := range list {
+					if item := results[s.start+k]; item != nil {
+						list[k] = item
+					} else if f.def.typ.Elem.NonNull {
+						list = nil
+						break
+					}
+				}
+				if list != nil {
+					out[i][j].value = list
+				} else {
+					reported[i][j] = true
+				}
+			case results[s.start] != nil:
+				out[i][j].value = results[s.start]
+			default:
+				reported[i][j] = true
+			}
+		}
+	}
+
+	// null в поле с ! делает null весь объект, дальше null поднимается к ближайшему
+	// полю, которое может быть null. Если резолвер сам вернул null без ошибки, ошибка
+	// добавляется здесь, иначе ответ остался бы без объяснения
+	for i := range out {
+		for j, f := range fields {
+			if f.def == nil || !f.def.typ.NonNull || !isNull(out[i][j].value) {
+				continue
+			}
+			if !reported[i][j] {
+				ex.errors = append(ex.errors, &Error{
+					Err:       i18n.Errorf("graphql.null_non_null", f.def.Name, f.def.Type),
+					Locations: []Location{f.loc},
+					Path:      paths[j],
+				})
+			}
+			out[i] = nil
+			break
+		}
+	}
+	return out
+}
+
+// listItems раскладывает срез любого типа; nil — для null.
+func listItems(v any) []any {
+	if isNull(v) {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return []any{v}
+	}
+	items := make([]any, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items
+}
+
+func isNull(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
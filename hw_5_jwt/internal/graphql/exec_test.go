@@ -0,0 +1,237 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"hw_5_jwt/internal/i18n"
+)
+
+type testNode struct {
+	ID   int
+	Name string
+}
+
+func perParent(parents []any, value func(p *testNode) any) []any {
+	out := make([]any, len(parents))
+	for i, p := range parents {
+		out[i] = value(p.(*testNode))
+	}
+	return out
+}
+
+// testSchema — Query с полями на все случаи: one: Node! и maybe: Node всегда
+// возвращают null, broken: Int! у Node всегда падает.
+func testSchema(t *testing.T) *Schema {
+	t.Helper()
+
+	node := &Object{Name: "Node", Fields: []*FieldDef{
+		{Name: "id", Type: "Int!", Resolve: func(_ context.Context, parents []any, _ Args) ([]any, error) {
+			return perParent(parents, func(p *testNode) any { return p.ID }), nil
+		}},
+		{Name: "name", Type: "String", Resolve: func(_ context.Context, parents []any, _ Args) ([]any, error) {
+			return perParent(parents, func(p *testNode) any { return p.Name }), nil
+		}},
+		{Name: "child", Type: "Node", Resolve: func(_ context.Context, parents []any, _ Args) ([]any, error) {
+			return perParent(parents, func(p *testNode) any { return &testNode{ID: p.ID + 1, Name: fmt.Sprintf("n%d", p.ID+1)} }), nil
+		}},
+		{Name: "broken", Type: "Int!", Resolve: func(context.Context, []any, Args) ([]any, error) {
+			return nil, errors.New("boom")
+		}},
+	}}
+
+	query := &Object{Name: "Query", Fields: []*FieldDef{
+		{Name: "node", Type: "Node", Args: []*ArgDef{{Name: "id", Type: "Int!"}}, Resolve: func(_ context.Context, parents []any, args Args) ([]any, error) {
+			id, _ := args.Int("id")
+			out := make([]any, len(parents))
+			for i := range out {
+				out[i] = &testNode{ID: id, Name: fmt.Sprintf("n%d", id)}
+			}
+			return out, nil
+		}},
+		{Name: "nodes", Type: "[Node!]!", Resolve: func(_ context.Context, parents []any, _ Args) ([]any, error) {
+			out := make([]any, len(parents))
+			for i := range out {
+				out[i] = []*testNode{{ID: 1, Name: "n1"}, {ID: 2, Name: "n2"}}
+			}
+			return out, nil
+		}},
+		{Name: "one", Type: "Node!", Resolve: func(_ context.Context, parents []any, _ Args) ([]any, error) {
+			return make([]any, len(parents)), nil
+		}},
+		{Name: "maybe", Type: "Node", Resolve: func(_ context.Context, parents []any, _ Args) ([]any, error) {
+			return make([]any, len(parents)), nil
+		}},
+	}}
+
+	schema, err := NewSchema(query, node)
+	if err != nil {
+		t.Fatalf("NewSchema: %v", err)
+	}
+	schema.MaxDepth = 4
+	schema.MaxComplexity = 30
+	return schema
+}
+
+// errorID — идентификатор сообщения каталога или текст ошибки резолвера.
+func errorID(e *Error) string {
+	var msg *i18n.Message
+	if errors.As(e.Err, &msg) {
+		return msg.ID
+	}
+	return e.Error()
+}
+
+func TestExecute(t *testing.T) {
+	schema := testSchema(t)
+
+	tests := []struct {
+		name      string
+		query     string
+		variables string
+		// data — ожидаемый JSON data; пусто — data в ответе нет
+		data   string
+		errors []string
+		// path — путь первой ошибки
+		path []any
+	}{
+		{
+			name:  "фрагмент",
+			query: `{ node(id: 1) { ...F } } fragment F on Node { id name }`,
+			data:  `{"node":{"id":1,"name":"n1"}}`,
+		},
+		{
+			name:  "встроенный фрагмент и директивы",
+			query: `{ node(id: 1) { id ... on Node @include(if: false) { name } child @skip(if: false) { id } } }`,
+			data:  `{"node":{"id":1,"child":{"id":2}}}`,
+		},
+		{
+			name:  "псевдонимы в порядке запроса",
+			query: `{ b: node(id: 2) { id } a: node(id: 1) { id } }`,
+			data:  `{"b":{"id":2},"a":{"id":1}}`,
+		},
+		{
+			name:   "цикл фрагментов",
+			query:  `{ node(id: 1) { ...A } } fragment A on Node { child { ...A } }`,
+			errors: []string{"graphql.fragment_cycle"},
+		},
+		{
+			name:      "переменная",
+			query:     `query Q($id: Int!) { node(id: $id) { id } }`,
+			variables: `{"id": 7}`,
+			data:      `{"node":{"id":7}}`,
+		},
+		{
+			name:      "значение переменной по умолчанию",
+			query:     `query Q($id: Int = 3) { node(id: $id) { name } }`,
+			variables: `{}`,
+			data:      `{"node":{"name":"n3"}}`,
+		},
+		{
+			name:      "не передана обязательная переменная",
+			query:     `query Q($id: Int!) { node(id: $id) { id } }`,
+			variables: `{}`,
+			errors:    []string{"graphql.missing_variable"},
+		},
+		{
+			name:      "переменная неверного типа",
+			query:     `query Q($id: Int!) { node(id: $id) { id } }`,
+			variables: `{"id": "x"}`,
+			errors:    []string{"graphql.invalid_variable"},
+		},
+		{
+			name:   "необъявленная переменная",
+			query:  `{ node(id: $id) { id } }`,
+			errors: []string{"graphql.undefined_variable", "graphql.missing_argument"},
+		},
+		{
+			name:  "глубина на пределе",
+			query: `{ node(id: 1) { child { child { id } } } }`,
+			data:  `{"node":{"child":{"child":{"id":3}}}}`,
+		},
+		{
+			name:   "слишком глубокий запрос",
+			query:  `{ node(id: 1) { child { child { child { id } } } } }`,
+			errors: []string{"graphql.too_deep"},
+		},
+		{
+			// nodes: 1 + 10 × (id + name) = 21
+			name:  "сложность в пределах",
+			query: `{ nodes { id name } }`,
+			data:  `{"nodes":[{"id":1,"name":"n1"},{"id":2,"name":"n2"}]}`,
+		},
+		{
+			// nodes: 1 + 10 × (id + name + child + child.id) = 41
+			name:   "слишком сложный запрос",
+			query:  `{ nodes { id name child { id } } }`,
+			errors: []string{"graphql.too_complex"},
+		},
+		{
+			name:   "null в поле с ! без ошибки резолвера",
+			query:  `{ one { id } }`,
+			data:   `null`,
+			errors: []string{"graphql.null_non_null"},
+			path:   []any{"one"},
+		},
+		{
+			name:  "null в поле без !",
+			query: `{ maybe { id } }`,
+			data:  `{"maybe":null}`,
+		},
+		{
+			name:   "ошибка в поле с ! поднимается до ближайшего поля без !",
+			query:  `{ node(id: 1) { id child { broken } } }`,
+			data:   `{"node":{"id":1,"child":null}}`,
+			errors: []string{"boom"},
+			path:   []any{"node", "child", "broken"},
+		},
+		{
+			name:   "ошибка в элементе списка с ! поднимается до корня",
+			query:  `{ nodes { broken } maybe { id } }`,
+			data:   `null`,
+			errors: []string{"boom"},
+			path:   []any{"nodes", "broken"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{Query: tt.query}
+			if tt.variables != "" {
+				if err := json.Unmarshal([]byte(tt.variables), &req.Variables); err != nil {
+					t.Fatalf("variables: %v", err)
+				}
+			}
+			resp := schema.Execute(context.Background(), req)
+
+			var ids []string
+			for _, e := range resp.Errors {
+				ids = append(ids, errorID(e))
+			}
+			if !reflect.DeepEqual(ids, tt.errors) {
+				t.Fatalf("ошибки %v, ожидались %v", ids, tt.errors)
+			}
+			if tt.path != nil && !reflect.DeepEqual(resp.Errors[0].Path, tt.path) {
+				t.Fatalf("путь ошибки %v, ожидался %v", resp.Errors[0].Path, tt.path)
+			}
+
+			body, err := json.Marshal(resp)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			var got struct {
+				Data json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if string(got.Data) != tt.data {
+				t.Fatalf("data = %s, ожидалось %s", got.Data, tt.data)
+			}
+		})
+	}
+}
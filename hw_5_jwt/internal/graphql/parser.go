@@ -0,0 +1,842 @@
+package graphql
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"hw_5_jwt/internal/i18n"
+)
+
+// Поддерживается исполняемая часть языка: операции с переменными, поля с
+// псевдонимами и аргументами, фрагменты и директивы. Определения типов (SDL)
+// не разбираются — схема задаётся в Go.
+
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+type Operation struct {
+	Kind         string
+	Name         string
+	Variables    []*VariableDefinition
+	Directives   []*Directive
+	SelectionSet []Selection
+	Loc          Location
+}
+
+type VariableDefinition struct {
+	Name    string
+	Type    *TypeRef
+	Default *Value
+	Loc     Location
+}
+
+type Selection interface {
+	location() Location
+}
+
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*Directive
+	SelectionSet []Selection
+	Loc          Location
+}
+
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Loc        Location
+}
+
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+	Loc           Location
+}
+
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+	Loc           Location
+}
+
+type Argument struct {
+	Name  string
+	Value *Value
+	Loc   Location
+}
+
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Loc       Location
+}
+
+func (f *Field) location() Location          { return f.Loc }
+func (f *FragmentSpread) location() Location { return f.Loc }
+func (f *InlineFragment) location() Location { return f.Loc }
+
+// ResponseKey — ключ поля в ответе: псевдоним или имя.
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+type ValueKind int
+
+const (
+	VariableValue ValueKind = iota
+	IntValue
+	FloatValue
+	StringValue
+	BooleanValue
+	NullValue
+	EnumValue
+	ListValue
+	ObjectValue
+)
+
+type Value struct {
+	Kind   ValueKind
+	Raw    string
+	List   []*Value
+	Fields []*ObjectField
+	Loc    Location
+}
+
+type ObjectField struct {
+	Name  string
+	Value *Value
+}
+
+// TypeRef — ссылка на тип: Name для именованного типа, Elem для списка.
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef
+	NonNull bool
+}
+
+func (t *TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Named — имя типа без списков и обязательности: [Lesson!]! → Lesson.
+func (t *TypeRef) Named() string {
+	for t.Elem != nil {
+		t = t.Elem
+	}
+	return t.Name
+}
+
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	loc   Location
+}
+
+type lexer struct {
+	src       string
+	pos       int
+	line      int
+	lineStart int
+}
+
+func (l *lexer) location() Location {
+	return Location{Line: l.line, Column: utf8.RuneCountInString(l.src[l.lineStart:l.pos]) + 1}
+}
+
+func (l *lexer) syntaxError(loc Location, near string) error {
+	return &Error{Err: i18n.Errorf("graphql.syntax_error", near), Locations: []Location{loc}}
+}
+
+func (l *lexer) newline() {
+	l.line++
+	l.lineStart = l.pos
+}
+
+// skip пропускает пробелы, запятые и комментарии.
+func (l *lexer) skip() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; c {
+		case ' ', '\t', ',':
+			l.pos++
+		case '\n':
+			l.pos++
+			l.newline()
+		case '\r':
+			l.pos++
+			if l.pos < len(l.src) && l.src[l.pos] == '\n' {
+				l.pos++
+			}
+			l.newline()
+		case '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			if strings.HasPrefix(l.src[l.pos:], "\uFEFF") {
+				l.pos += len("\uFEFF")
+				continue
+			}
+			return
+		}
+	}
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (l *lexer) next() (token, error) {
+	l.skip()
+	loc := l.location()
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, loc: loc}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokPunct, value: "...", loc: loc}, nil
+	case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+		l.pos++
+		return token{kind: tokPunct, value: string(c), loc: loc}, nil
+	case isNameStart(c):
+		start := l.pos
+		for l.pos < len(l.src) && (isNameStart(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokName, value: l.src[start:l.pos], loc: loc}, nil
+	case c == '-' || isDigit(c):
+		return l.number(loc)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			return l.blockString(loc)
+		}
+		return l.string(loc)
+	}
+
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return token{}, l.syntaxError(loc, string(r))
+}
+
+func (l *lexer) number(loc Location) (token, error) {
+	start := l.pos
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	digits := func() int {
+		n := 0
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+			n++
+		}
+		return n
+	}
+
+	if digits() == 0 {
+		return token{}, l.syntaxError(loc, l.src[start:l.pos])
+	}
+	kind := tokInt
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		l.pos++
+		kind = tokFloat
+		if digits() == 0 {
+			return token{}, l.syntaxError(loc, l.src[start:l.pos])
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		l.pos++
+		kind = tokFloat
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		if digits() == 0 {
+			return token{}, l.syntaxError(loc, l.src[start:l.pos])
+		}
+	}
+	if l.pos < len(l.src) && (isNameStart(l.src[l.pos]) || l.src[l.pos] == '.') {
+		return token{}, l.syntaxError(loc, l.src[start:l.pos+1])
+	}
+	return token{kind: kind, value: l.src[start:l.pos], loc: loc}, nil
+}
+
+func (l *lexer) string(loc Location) (token, error) {
+	l.pos++
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{kind: tokString, value: b.String(), loc: loc}, nil
+		case c == '\n' || c == '\r':
+			return token{}, l.syntaxError(loc, `"`)
+		case c == '\\':
+			if l.pos+1 >= len(l.src) {
+				return token{}, l.syntaxError(loc, `\`)
+			}
+			esc := l.src[l.pos+1]
+			l.pos += 2
+			switch esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.pos+4 > len(l.src) {
+					return token{}, l.syntaxError(loc, `\u`)
+				}
+				code, err := strconv.ParseUint(l.src[l.pos:l.pos+4], 16, 32)
+				if err != nil {
+					return token{}, l.syntaxError(loc, `\u`+l.src[l.pos:l.pos+4])
+				}
+				b.WriteRune(rune(code))
+				l.pos += 4
+			default:
+				return token{}, l.syntaxError(loc, `\`+string(esc))
+			}
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+	return token{}, l.syntaxError(loc, `"`)
+}
+
+// blockString читает """...""" и убирает общий отступ строк, как требует спецификация.
+func (l *lexer) blockString(loc Location) (token, error) {
+	l.pos += 3
+	start := l.pos
+	for l.pos < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.pos:], `\"""`):
+			l.pos += 4
+		case strings.HasPrefix(l.src[l.pos:], `"""`):
+			raw := strings.ReplaceAll(l.src[start:l.pos], `\"""`, `"""`)
+			l.pos += 3
+			return token{kind: tokString, value: dedentBlock(raw), loc: loc}, nil
+		case l.src[l.pos] == '\n':
+			l.pos++
+			l.newline()
+		default:
+			l.pos++
+		}
+	}
+	return token{}, l.syntaxError(loc, `"""`)
+}
+
+func dedentBlock(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+// Parse разбирает документ запроса. Ошибка — *Error с местом в тексте запроса.
+func Parse(src string) (*Document, error) {
+	p := &parser{lex: &lexer{src: src, line: 1}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{Fragments: map[string]*Fragment{}}
+	if p.tok.kind == tokEOF {
+		return nil, &Error{Err: i18n.Errorf("graphql.query_required")}
+	}
+	for p.tok.kind != tokEOF {
+		switch {
+		case p.peek("{"):
+			loc := p.tok.loc
+			set, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, &Operation{Kind: "query", SelectionSet: set, Loc: loc})
+		case p.tok.kind == tokName && p.tok.value == "fragment":
+			fragment, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := doc.Fragments[fragment.Name]; dup {
+				return nil, &Error{Err: i18n.Errorf("graphql.duplicate_fragment", fragment.Name), Locations: []Location{fragment.Loc}}
+			}
+			doc.Fragments[fragment.Name] = fragment
+		case p.tok.kind == tokName && (p.tok.value == "query" || p.tok.value == "mutation" || p.tok.value == "subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	return doc, nil
+}
+
+// ParseType разбирает ссылку на тип вида [Lesson!]!.
+func ParseType(src string) (*TypeRef, error) {
+	p := &parser{lex: &lexer{src: src, line: 1}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	t, err := p.typeRef()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.unexpected()
+	}
+	return t, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	near := p.tok.value
+	if p.tok.kind == tokEOF {
+		near = "<EOF>"
+	}
+	return p.lex.syntaxError(p.tok.loc, near)
+}
+
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokPunct && p.tok.value == punct
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.peek(punct) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+// skipIf пропускает знак punct, если он следующий.
+func (p *parser) skipIf(punct string) (bool, error) {
+	if !p.peek(punct) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokName {
+		return "", p.unexpected()
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) operation() (*Operation, error) {
+	op := &Operation{Kind: p.tok.value, Loc: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokName {
+		op.Name = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	if ok, err := p.skipIf("("); err != nil {
+		return nil, err
+	} else if ok {
+		for !p.peek(")") {
+			def, err := p.variableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			op.Variables = append(op.Variables, def)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if op.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if op.SelectionSet, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+func (p *parser) variableDefinition() (*VariableDefinition, error) {
+	def := &VariableDefinition{Loc: p.tok.loc}
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if def.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	if def.Type, err = p.typeRef(); err != nil {
+		return nil, err
+	}
+
+	if ok, err := p.skipIf("="); err != nil {
+		return nil, err
+	} else if ok {
+		if def.Default, err = p.value(true); err != nil {
+			return nil, err
+		}
+	}
+
+	// директивы переменных разрешены грамматикой, но ничего не меняют
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+func (p *parser) typeRef() (*TypeRef, error) {
+	var t *TypeRef
+	if ok, err := p.skipIf("["); err != nil {
+		return nil, err
+	} else if ok {
+		elem, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		t = &TypeRef{Elem: elem}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		t = &TypeRef{Name: name}
+	}
+
+	nonNull, err := p.skipIf("!")
+	t.NonNull = nonNull
+	return t, err
+}
+
+func (p *parser) fragment() (*Fragment, error) {
+	f := &Fragment{Loc: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if f.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if f.Name == "on" {
+		return nil, p.lex.syntaxError(f.Loc, "on")
+	}
+	if p.tok.kind != tokName || p.tok.value != "on" {
+		return nil, p.unexpected()
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if f.TypeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if f.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if f.SelectionSet, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (p *parser) selectionSet() ([]Selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var set []Selection
+	for !p.peek("}") {
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, sel)
+	}
+	if len(set) == 0 {
+		return nil, p.unexpected()
+	}
+	return set, p.advance()
+}
+
+func (p *parser) selection() (Selection, error) {
+	loc := p.tok.loc
+	if ok, err := p.skipIf("..."); err != nil {
+		return nil, err
+	} else if ok {
+		return p.fragmentSelection(loc)
+	}
+
+	f := &Field{Loc: loc}
+	var err error
+	if f.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if ok, err := p.skipIf(":"); err != nil {
+		return nil, err
+	} else if ok {
+		f.Alias = f.Name
+		if f.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Arguments, err = p.arguments(false); err != nil {
+		return nil, err
+	}
+	if f.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		if f.SelectionSet, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (p *parser) fragmentSelection(loc Location) (Selection, error) {
+	if p.tok.kind == tokName && p.tok.value != "on" {
+		spread := &FragmentSpread{Name: p.tok.value, Loc: loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		spread.Directives, err = p.directives()
+		return spread, err
+	}
+
+	inline := &InlineFragment{Loc: loc}
+	if p.tok.kind == tokName {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		if inline.TypeCondition, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if inline.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if inline.SelectionSet, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return inline, nil
+}
+
+func (p *parser) arguments(constant bool) ([]*Argument, error) {
+	if ok, err := p.skipIf("("); err != nil || !ok {
+		return nil, err
+	}
+
+	var args []*Argument
+	for !p.peek(")") {
+		arg := &Argument{Loc: p.tok.loc}
+		var err error
+		if arg.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if arg.Value, err = p.value(constant); err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	if len(args) == 0 {
+		return nil, p.unexpected()
+	}
+	return args, p.advance()
+}
+
+func (p *parser) directives() ([]*Directive, error) {
+	var directives []*Directive
+	for p.peek("@") {
+		d := &Directive{Loc: p.tok.loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		if d.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if d.Arguments, err = p.arguments(false); err != nil {
+			return nil, err
+		}
+		directives = append(directives, d)
+	}
+	return directives, nil
+}
+
+// value разбирает значение; constant запрещает переменные (значения по умолчанию).
+func (p *parser) value(constant bool) (*Value, error) {
+	v := &Value{Loc: p.tok.loc, Raw: p.tok.value}
+
+	switch p.tok.kind {
+	case tokInt:
+		v.Kind = IntValue
+	case tokFloat:
+		v.Kind = FloatValue
+	case tokString:
+		v.Kind = StringValue
+	case tokName:
+		switch p.tok.value {
+		case "true", "false":
+			v.Kind = BooleanValue
+		case "null":
+			v.Kind = NullValue
+		default:
+			v.Kind = EnumValue
+		}
+	case tokPunct:
+		switch p.tok.value {
+		case "$":
+			if constant {
+				return nil, p.unexpected()
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if p.tok.kind != tokName {
+				return nil, p.unexpected()
+			}
+			v.Kind, v.Raw = VariableValue, p.tok.value
+		case "[":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			v.Kind = ListValue
+			for !p.peek("]") {
+				item, err := p.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				v.List = append(v.List, item)
+			}
+		case "{":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			v.Kind = ObjectValue
+			for !p.peek("}") {
+				name, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				item, err := p.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				v.Fields = append(v.Fields, &ObjectField{Name: name, Value: item})
+			}
+		default:
+			return nil, p.unexpected()
+		}
+	default:
+		return nil, p.unexpected()
+	}
+
+	return v, p.advance()
+}
@@ -0,0 +1,170 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResolveFunc получает всех родителей поля на текущем уровне запроса и возвращает
+// значение для каждого из них в том же порядке. Поэтому связанные объекты
+// загружаются одним запросом к базе на уровень, а не запросом на каждого родителя.
+// Для списочных полей значение — срез, для объектных — объект или nil.
+type ResolveFunc func(ctx context.Context, parents []any, args Args) ([]any, error)
+
+type Args map[string]any
+
+// Int возвращает целочисленный аргумент; ok = false, если он не передан или null.
+func (a Args) Int(name string) (int, bool) {
+	v, ok := a[name].(int)
+	return v, ok
+}
+
+func (a Args) String(name string) (string, bool) {
+	v, ok := a[name].(string)
+	return v, ok
+}
+
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*FieldDef
+
+	byName map[string]*FieldDef
+}
+
+type FieldDef struct {
+	Name        string
+	Description string
+	// Type в нотации SDL: Int!, [Lesson!]!, Student
+	Type    string
+	Args    []*ArgDef
+	Resolve ResolveFunc
+	// Cost — вес поля при подсчёте сложности запроса, по умолчанию 1
+	Cost int
+
+	typ *TypeRef
+}
+
+type ArgDef struct {
+	Name        string
+	Type        string
+	Description string
+
+	typ *TypeRef
+}
+
+var scalars = map[string]string{
+	"Int":     "32-битное целое",
+	"Float":   "Число с плавающей точкой",
+	"String":  "Строка UTF-8",
+	"Boolean": "true или false",
+	"ID":      "Идентификатор",
+}
+
+// Schema — схема только для чтения: корневой тип Query и объектные типы.
+type Schema struct {
+	Query *Object
+	// MaxDepth — наибольшая вложенность полей, MaxComplexity — наибольшая сложность:
+	// сумма весов полей, где поля внутри списков умножаются на listFactor
+	MaxDepth      int
+	MaxComplexity int
+
+	types map[string]*Object
+}
+
+// listFactor — сколько элементов в среднем ожидается в списке при подсчёте сложности.
+const listFactor = 10
+
+// NewSchema проверяет, что типы полей и аргументов известны, и строит схему.
+func NewSchema(query *Object, types ...*Object) (*Schema, error) {
+	s := &Schema{Query: query, MaxDepth: 10, MaxComplexity: 5000, types: map[string]*Object{}}
+	for _, obj := range append([]*Object{query}, types...) {
+		if _, dup := s.types[obj.Name]; dup {
+			return nil, fmt.Errorf("graphql: тип %s объявлен дважды", obj.Name)
+		}
+		s.types[obj.Name] = obj
+	}
+
+	for _, obj := range s.types {
+		obj.byName = make(map[string]*FieldDef, len(obj.Fields))
+		for _, f := range obj.Fields {
+			t, err := ParseType(f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("graphql: тип поля %s.%s: %w", obj.Name, f.Name, err)
+			}
+			if !s.known(t.Named()) {
+				return nil, fmt.Errorf("graphql: неизвестный тип %s поля %s.%s", t.Named(), obj.Name, f.Name)
+			}
+			if f.Resolve == nil {
+				return nil, fmt.Errorf("graphql: у поля %s.%s нет Resolve", obj.Name, f.Name)
+			}
+			f.typ = t
+			obj.byName[f.Name] = f
+
+			for _, a := range f.Args {
+				at, err := ParseType(a.Type)
+				if err != nil {
+					return nil, fmt.Errorf("graphql: тип аргумента %s.%s(%s): %w", obj.Name, f.Name, a.Name, err)
+				}
+				if _, ok := scalars[at.Named()]; !ok {
+					return nil, fmt.Errorf("graphql: аргумент %s.%s(%s) должен быть скаляром", obj.Name, f.Name, a.Name)
+				}
+				a.typ = at
+			}
+		}
+	}
+	return s, nil
+}
+
+func (s *Schema) known(name string) bool {
+	_, scalar := scalars[name]
+	_, object := s.types[name]
+	return scalar || object
+}
+
+// SDL описывает схему на языке определения типов GraphQL — для генераторов клиентов.
+func (s *Schema) SDL() string {
+	var b strings.Builder
+
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		if name != s.Query.Name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	writeDescription(&b, "", s.Query.Description)
+	writeObject(&b, s.Query)
+	for _, name := range names {
+		b.WriteString("\n")
+		writeDescription(&b, "", s.types[name].Description)
+		writeObject(&b, s.types[name])
+	}
+	return b.String()
+}
+
+func writeObject(b *strings.Builder, obj *Object) {
+	fmt.Fprintf(b, "type %s {\n", obj.Name)
+	for _, f := range obj.Fields {
+		writeDescription(b, "  ", f.Description)
+		b.WriteString("  " + f.Name)
+		if len(f.Args) > 0 {
+			args := make([]string, len(f.Args))
+			for i, a := range f.Args {
+				args[i] = a.Name + ": " + a.Type
+			}
+			b.WriteString("(" + strings.Join(args, ", ") + ")")
+		}
+		b.WriteString(": " + f.Type + "\n")
+	}
+	b.WriteString("}\n")
+}
+
+func writeDescription(b *strings.Builder, indent, text string) {
+	if text != "" {
+		fmt.Fprintf(b, "%s\"\"\"%s\"\"\"\n", indent, strings.ReplaceAll(text, `"""`, `\"""`))
+	}
+}
@@ -0,0 +1,187 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"hw_5_jwt/api/universityv1"
+	"hw_5_jwt/internal/handlers"
+	"hw_5_jwt/internal/i18n"
+	"hw_5_jwt/internal/models"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const errorDomain = "university.v1"
+
+// principal — кто вызывает метод: пользователь по JWT или сервис по API-ключу.
+type principal struct {
+	user    *models.User
+	service string
+}
+
+type principalKey struct{}
+
+func principalFrom(ctx context.Context) principal {
+	p, _ := ctx.Value(principalKey{}).(principal)
+	return p
+}
+
+// ParseAPIKeys разбирает GRPC_API_KEYS вида "reports:ключ1,billing:ключ2" в
+// отображение ключ → имя сервиса.
+func ParseAPIKeys(value string) (map[string]string, error) {
+	keys := map[string]string{}
+	for i, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, key, ok := strings.Cut(pair, ":")
+		if !ok || name == "" || key == "" {
+			return nil, fmt.Errorf("GRPC_API_KEYS: элемент %d не в формате сервис:ключ", i+1)
+		}
+		keys[key] = name
+	}
+	return keys, nil
+}
+
+func metadataValue(ctx context.Context, name string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// lang — язык сообщений об ошибках: из профиля пользователя, иначе из accept-language.
+func lang(ctx context.Context) string {
+	if user := principalFrom(ctx).user; user != nil && i18n.Supported(user.Language) {
+		return user.Language
+	}
+	if lang := i18n.Negotiate(metadataValue(ctx, "accept-language")); lang != "" {
+		return lang
+	}
+	return i18n.Default
+}
+
+// fail возвращает статус с сообщением code на языке вызова; сам code клиент
+// получает в ErrorInfo.Reason.
+func fail(ctx context.Context, c codes.Code, code string, args ...any) error {
+	st := status.New(c, i18n.T(lang(ctx), code, args...))
+	if detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: code, Domain: errorDomain}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// authenticate проверяет x-api-key или authorization: Bearer <JWT> так же, как
+// AuthMiddleware REST API, и кладёт вызывающего в контекст.
+func (s *Server) authenticate(ctx context.Context) (context.Context, error) {
+	if key := metadataValue(ctx, universityv1.APIKeyHeader); key != "" {
+		for candidate, service := range s.keys {
+			if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+				return context.WithValue(ctx, principalKey{}, principal{service: service}), nil
+			}
+		}
+		s.logger.Warn("неверный API-ключ gRPC")
+		return nil, fail(ctx, codes.Unauthenticated, "auth.invalid_token")
+	}
+
+	header := metadataValue(ctx, universityv1.AuthorizationHeader)
+	if header == "" {
+		return nil, fail(ctx, codes.Unauthenticated, "auth.header_required")
+	}
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		return nil, fail(ctx, codes.Unauthenticated, "auth.invalid_format")
+	}
+	claims, err := handlers.ValidateToken(token)
+	if err != nil {
+		s.logger.Warn("невалидный токен", "error", err)
+		return nil, fail(ctx, codes.Unauthenticated, "auth.invalid_token")
+	}
+
+	user, err := s.repo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		s.logger.Error("ошибка получения пользователя", "error", err)
+		return nil, fail(ctx, codes.Internal, "common.server_error")
+	}
+	if user == nil {
+		return nil, fail(ctx, codes.Unauthenticated, "auth.unauthenticated")
+	}
+	user.Password = ""
+	return context.WithValue(ctx, principalKey{}, principal{user: user}), nil
+}
+
+func (s *Server) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	started := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("паника в обработчике", "method", info.FullMethod, "panic", r, "stack", string(debug.Stack()))
+			err = fail(ctx, codes.Internal, "common.internal_error")
+		}
+		s.logCall(ctx, info.FullMethod, started, err)
+	}()
+
+	authed, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = authed
+	return handler(ctx, req)
+}
+
+// authStream подменяет контекст потока контекстом с вызывающим.
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *Server) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	started := time.Now()
+	ctx := ss.Context()
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("паника в обработчике", "method", info.FullMethod, "panic", r, "stack", string(debug.Stack()))
+			err = fail(ctx, codes.Internal, "common.internal_error")
+		}
+		s.logCall(ctx, info.FullMethod, started, err)
+	}()
+
+	authed, err := s.authenticate(ctx)
+	if err != nil {
+		return err
+	}
+	ctx = authed
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+}
+
+// logCall пишет в журнал вызов так же, как RequestLogger пишет HTTP-запрос.
+func (s *Server) logCall(ctx context.Context, method string, started time.Time, err error) {
+	code := status.Code(err)
+	attrs := []any{"method", method, "code", code.String(), "latency", time.Since(started)}
+	if p := principalFrom(ctx); p.user != nil {
+		attrs = append(attrs, "user_id", p.user.ID)
+	} else if p.service != "" {
+		attrs = append(attrs, "service", p.service)
+	}
+
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss:
+		s.logger.Error("ошибка вызова gRPC", append(attrs, "error", err)...)
+	default:
+		s.logger.Info("вызов gRPC", attrs...)
+	}
+}
@@ -0,0 +1,108 @@
+package grpcapi
+
+import (
+	"strings"
+
+	"hw_5_jwt/api/universityv1"
+	"hw_5_jwt/internal/models"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func studentMessage(s models.Student) *universityv1.Student {
+	msg := &universityv1.Student{
+		Id:      int32(s.StudentID),
+		Name:    s.Name,
+		Surname: s.Surname,
+		Gender:  s.Gender,
+		GroupId: int32(s.GroupID),
+		UserId:  int32(s.UserId),
+	}
+	if s.Birthday.Year() > 1 {
+		msg.Birthday = s.Birthday.Format("02.01.2006")
+	}
+	return msg
+}
+
+func groupMessage(g models.Group) *universityv1.Group {
+	return &universityv1.Group{Id: int32(g.GroupID), Name: g.GroupName, Faculty: g.Faculty}
+}
+
+func teacherMessage(t models.Teacher) *universityv1.Teacher {
+	return &universityv1.Teacher{
+		Id:       int32(t.ID),
+		UserId:   int32(t.UserId),
+		Name:     t.Name.String,
+		Surname:  t.Surname.String,
+		Gender:   t.Gender.String,
+		Subjects: t.Subject.String,
+	}
+}
+
+func lessonMessage(l models.ScheduleEntry) *universityv1.Lesson {
+	msg := &universityv1.Lesson{
+		Id:          int32(l.ScheduleID),
+		GroupId:     int32(l.GroupID),
+		SubjectId:   int32(l.SubjectID),
+		SubjectName: l.SubjectName,
+		Name:        l.LessonName,
+		DayOfWeek:   int32(l.DayOfWeek),
+		StartTime:   l.StartTime,
+		EndTime:     l.EndTime,
+		RoomId:      optionalInt32(l.RoomID),
+	}
+	if l.RoomName != nil {
+		msg.RoomName = *l.RoomName
+	}
+	return msg
+}
+
+func attendanceResponse(records []models.AttendanceRecord) *universityv1.ListAttendanceResponse {
+	resp := &universityv1.ListAttendanceResponse{Records: make([]*universityv1.AttendanceRecord, len(records))}
+	for i, r := range records {
+		resp.Records[i] = &universityv1.AttendanceRecord{
+			Id:          int32(r.AttendanceID),
+			StudentId:   int32(r.StudentID),
+			ScheduleId:  int32(r.ScheduleID),
+			SubjectId:   int32(r.SubjectID),
+			VisitDay:    r.VisitDay,
+			Visited:     r.Visited,
+			Status:      attendanceStatus(r.Status),
+			MinutesLate: optionalInt32(r.MinutesLate),
+		}
+		if r.Note != nil {
+			resp.Records[i].Note = *r.Note
+		}
+	}
+	return resp
+}
+
+func eventMessage(e models.AttendanceStreamEvent) *universityv1.AttendanceEvent {
+	return &universityv1.AttendanceEvent{
+		EventId:     e.EventID,
+		StudentId:   int32(e.StudentID),
+		Name:        e.Name,
+		Surname:     e.Surname,
+		ScheduleId:  int32(e.ScheduleID),
+		SubjectId:   int32(e.SubjectID),
+		GroupId:     int32(e.GroupID),
+		VisitDay:    e.VisitDay,
+		Visited:     e.Visited,
+		Status:      attendanceStatus(e.Status),
+		MinutesLate: optionalInt32(e.MinutesLate),
+		RecordedAt:  timestamppb.New(e.RecordedAt),
+	}
+}
+
+// attendanceStatus переводит статус из БД (present, late, ...) в значение перечисления.
+func attendanceStatus(status string) universityv1.AttendanceStatus {
+	return universityv1.AttendanceStatus(universityv1.AttendanceStatus_value["ATTENDANCE_STATUS_"+strings.ToUpper(status)])
+}
+
+func optionalInt32(v *int) *int32 {
+	if v == nil {
+		return nil
+	}
+	n := int32(*v)
+	return &n
+}
@@ -0,0 +1,278 @@
+// Package grpcapi — gRPC-сервер для внутренних сервисов: те же данные и права,
+// что у REST API, в сообщениях proto/university/v1/university.proto.
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"hw_5_jwt/api/universityv1"
+	"hw_5_jwt/internal/handlers"
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/postgres"
+	"hw_5_jwt/internal/realtime"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+)
+
+// Store — запросы к базе, которые выполняет сервер; *postgres.Repository.
+type Store interface {
+	handlers.ScopeStore
+
+	GetUserByID(ctx context.Context, id int) (*models.User, error)
+	GetStudentsByIDs(ctx context.Context, ids []int) ([]models.Student, error)
+	GetStudentsByGroupIDs(ctx context.Context, groupIDs []int) ([]models.Student, error)
+	GetAllStudents(ctx context.Context) ([]models.Student, error)
+	GetGroupsByIDs(ctx context.Context, ids []int) ([]models.Group, error)
+	GetGroups(ctx context.Context) ([]models.Group, error)
+	GetAllTeachers(ctx context.Context) ([]models.Teacher, error)
+	GetScheduleEntriesByGroupIDs(ctx context.Context, groupIDs []int) ([]models.ScheduleEntry, error)
+	GetScheduleEntriesBySubjectIDs(ctx context.Context, subjectIDs []int) ([]models.ScheduleEntry, error)
+	GetAttendanceByStudentIDs(ctx context.Context, studentIDs []int, subjectID int) ([]models.AttendanceRecord, error)
+	GetAttendanceByScheduleIDs(ctx context.Context, scheduleIDs []int, date *time.Time) ([]models.AttendanceRecord, error)
+}
+
+var _ Store = (*postgres.Repository)(nil)
+
+type Server struct {
+	universityv1.UnimplementedUniversityServer
+
+	repo Store
+	hub  *realtime.Hub
+	// keys — API-ключи сервисов: ключ → имя сервиса
+	keys   map[string]string
+	logger *slog.Logger
+}
+
+// New создаёт gRPC-сервер: каждый вызов проверяется по JWT или API-ключу из
+// метаданных. Reflection включён, чтобы сервис можно было вызывать из grpcurl.
+func New(repo Store, hub *realtime.Hub, keys map[string]string, logger *slog.Logger) *grpc.Server {
+	s := &Server{repo: repo, hub: hub, keys: keys, logger: logger}
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unary),
+		grpc.ChainStreamInterceptor(s.stream),
+	)
+	universityv1.RegisterUniversityServer(srv, s)
+	reflection.Register(srv)
+	return srv
+}
+
+// internal пишет ошибку в журнал и отвечает кодом code без подробностей.
+func (s *Server) internal(ctx context.Context, code, msg string, err error, attrs ...any) error {
+	s.logger.Error(msg, append(attrs, "error", err)...)
+	return fail(ctx, codes.Internal, code)
+}
+
+func (s *Server) GetStudent(ctx context.Context, req *universityv1.GetStudentRequest) (*universityv1.Student, error) {
+	if req.Id <= 0 {
+		return nil, fail(ctx, codes.InvalidArgument, "common.invalid_student_id")
+	}
+
+	students, err := s.repo.GetStudentsByIDs(ctx, []int{int(req.Id)})
+	if err != nil {
+		return nil, s.internal(ctx, "common.server_error", "ошибка получения студента", err, "student_id", req.Id)
+	}
+	if len(students) == 0 {
+		return nil, fail(ctx, codes.NotFound, "students.not_found")
+	}
+	return studentMessage(students[0]), nil
+}
+
+func (s *Server) ListStudents(ctx context.Context, req *universityv1.ListStudentsRequest) (*universityv1.ListStudentsResponse, error) {
+	var (
+		students []models.Student
+		err      error
+	)
+	switch {
+	case req.GroupId < 0:
+		return nil, fail(ctx, codes.InvalidArgument, "common.invalid_group_id")
+	case req.GroupId > 0:
+		students, err = s.repo.GetStudentsByGroupIDs(ctx, []int{int(req.GroupId)})
+	default:
+		students, err = s.repo.GetAllStudents(ctx)
+	}
+	if err != nil {
+		return nil, s.internal(ctx, "students.list_failed", "ошибка получения студентов", err)
+	}
+
+	resp := &universityv1.ListStudentsResponse{Students: make([]*universityv1.Student, len(students))}
+	for i, student := range students {
+		resp.Students[i] = studentMessage(student)
+	}
+	return resp, nil
+}
+
+func (s *Server) GetGroup(ctx context.Context, req *universityv1.GetGroupRequest) (*universityv1.Group, error) {
+	if req.Id <= 0 {
+		return nil, fail(ctx, codes.InvalidArgument, "common.invalid_group_id")
+	}
+
+	groups, err := s.repo.GetGroupsByIDs(ctx, []int{int(req.Id)})
+	if err != nil {
+		return nil, s.internal(ctx, "common.server_error", "ошибка получения группы", err, "group_id", req.Id)
+	}
+	if len(groups) == 0 {
+		return nil, fail(ctx, codes.NotFound, "groups.not_found")
+	}
+	return groupMessage(groups[0]), nil
+}
+
+func (s *Server) ListGroups(ctx context.Context, _ *universityv1.ListGroupsRequest) (*universityv1.ListGroupsResponse, error) {
+	groups, err := s.repo.GetGroups(ctx)
+	if err != nil {
+		return nil, s.internal(ctx, "groups.list_failed", "ошибка получения групп", err)
+	}
+
+	resp := &universityv1.ListGroupsResponse{Groups: make([]*universityv1.Group, len(groups))}
+	for i, group := range groups {
+		resp.Groups[i] = groupMessage(group)
+	}
+	return resp, nil
+}
+
+func (s *Server) ListTeachers(ctx context.Context, _ *universityv1.ListTeachersRequest) (*universityv1.ListTeachersResponse, error) {
+	teachers, err := s.repo.GetAllTeachers(ctx)
+	if err != nil {
+		return nil, s.internal(ctx, "teachers.list_failed", "ошибка получения учителей", err)
+	}
+
+	resp := &universityv1.ListTeachersResponse{Teachers: make([]*universityv1.Teacher, len(teachers))}
+	for i, teacher := range teachers {
+		resp.Teachers[i] = teacherMessage(teacher)
+	}
+	return resp, nil
+}
+
+func (s *Server) ListLessons(ctx context.Context, req *universityv1.ListLessonsRequest) (*universityv1.ListLessonsResponse, error) {
+	var (
+		lessons []models.ScheduleEntry
+		err     error
+	)
+	switch {
+	case req.GroupId < 0 || req.GroupId == 0 && req.SubjectId <= 0:
+		return nil, fail(ctx, codes.InvalidArgument, "common.invalid_group_id")
+	case req.GroupId > 0:
+		lessons, err = s.repo.GetScheduleEntriesByGroupIDs(ctx, []int{int(req.GroupId)})
+	default:
+		lessons, err = s.repo.GetScheduleEntriesBySubjectIDs(ctx, []int{int(req.SubjectId)})
+	}
+	if err != nil {
+		return nil, s.internal(ctx, "schedule.get_failed", "ошибка получения расписания", err)
+	}
+
+	resp := &universityv1.ListLessonsResponse{Lessons: make([]*universityv1.Lesson, 0, len(lessons))}
+	for _, lesson := range lessons {
+		if req.SubjectId > 0 && lesson.SubjectID != int(req.SubjectId) {
+			continue
+		}
+		resp.Lessons = append(resp.Lessons, lessonMessage(lesson))
+	}
+	return resp, nil
+}
+
+func (s *Server) ListStudentAttendance(ctx context.Context, req *universityv1.ListStudentAttendanceRequest) (*universityv1.ListAttendanceResponse, error) {
+	if req.StudentId <= 0 {
+		return nil, fail(ctx, codes.InvalidArgument, "common.invalid_student_id")
+	}
+	if req.SubjectId < 0 {
+		return nil, fail(ctx, codes.InvalidArgument, "common.invalid_subject_id")
+	}
+
+	records, err := s.repo.GetAttendanceByStudentIDs(ctx, []int{int(req.StudentId)}, int(req.SubjectId))
+	if err != nil {
+		return nil, s.internal(ctx, "attendance.get_failed", "ошибка получения посещаемости", err, "student_id", req.StudentId)
+	}
+	return attendanceResponse(records), nil
+}
+
+func (s *Server) ListLessonAttendance(ctx context.Context, req *universityv1.ListLessonAttendanceRequest) (*universityv1.ListAttendanceResponse, error) {
+	if req.ScheduleId <= 0 {
+		return nil, fail(ctx, codes.InvalidArgument, "common.invalid_lesson_id")
+	}
+
+	var date *time.Time
+	if req.Date != "" {
+		day, err := time.Parse("02.01.2006", req.Date)
+		if err != nil {
+			return nil, fail(ctx, codes.InvalidArgument, "common.invalid_date")
+		}
+		date = &day
+	}
+
+	records, err := s.repo.GetAttendanceByScheduleIDs(ctx, []int{int(req.ScheduleId)}, date)
+	if err != nil {
+		return nil, s.internal(ctx, "attendance.get_failed", "ошибка получения посещаемости", err, "schedule_id", req.ScheduleId)
+	}
+	return attendanceResponse(records), nil
+}
+
+// StreamAttendance шлёт отметки, видимые вызывающему, пока клиент не отменит вызов.
+// Если сервер закрыл подписку (остановка или клиент не успевает), вызов завершается
+// с Unavailable, и клиент переподключается с last_event_id.
+func (s *Server) StreamAttendance(req *universityv1.StreamAttendanceRequest, stream grpc.ServerStreamingServer[universityv1.AttendanceEvent]) error {
+	ctx := stream.Context()
+
+	filter := realtime.Filter{SubjectID: int(req.SubjectId), GroupID: int(req.GroupId), ScheduleID: int(req.ScheduleId)}
+	if filter.SubjectID < 0 || filter.GroupID < 0 || filter.ScheduleID < 0 || req.LastEventId < 0 {
+		return fail(ctx, codes.InvalidArgument, "common.invalid_id")
+	}
+	if req.Date != "" {
+		day, err := time.Parse("02.01.2006", req.Date)
+		if err != nil {
+			return fail(ctx, codes.InvalidArgument, "common.invalid_date")
+		}
+		filter.VisitDay = day.Format("02.01.2006")
+	}
+
+	scope := realtime.Scope{All: true}
+	p := principalFrom(ctx)
+	if p.user != nil {
+		var err error
+		scope, err = handlers.AttendanceScope(ctx, s.repo, p.user)
+		if err != nil {
+			return s.internal(ctx, "common.server_error", "ошибка определения доступа к потоку посещаемости", err, "user_id", p.user.ID)
+		}
+	}
+	if !scope.Covers(filter) {
+		s.logger.Warn("нет доступа к потоку посещаемости", "user_id", p.user.ID, "subject_id", filter.SubjectID, "group_id", filter.GroupID)
+		return fail(ctx, codes.PermissionDenied, "common.forbidden")
+	}
+
+	// подписка раньше догрузки пропущенного, повторы отбрасываются по event_id
+	sub := s.hub.Subscribe(filter, scope)
+	defer s.hub.Unsubscribe(sub)
+
+	sent := map[int64]bool{}
+	if req.LastEventId > 0 {
+		missed, err := s.hub.Replay(ctx, sub, req.LastEventId)
+		if err != nil {
+			return s.internal(ctx, "attendance.get_failed", "ошибка получения пропущенных событий", err, "after_id", req.LastEventId)
+		}
+		for _, event := range missed {
+			if err := stream.Send(eventMessage(event)); err != nil {
+				return err
+			}
+			sent[event.EventID] = true
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.Events():
+			if !ok {
+				return fail(ctx, codes.Unavailable, "common.unavailable")
+			}
+			if sent[event.EventID] {
+				continue
+			}
+			if err := stream.Send(eventMessage(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
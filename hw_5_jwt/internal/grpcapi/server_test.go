@@ -0,0 +1,216 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"hw_5_jwt/api/universityv1"
+	"hw_5_jwt/internal/handlers"
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/realtime"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testAPIKey = "test-key"
+
+// fakeStore отвечает на запросы сервера из памяти; методы, которые тесты не
+// вызывают, остаются от встроенного nil-интерфейса и паникуют.
+type fakeStore struct {
+	Store
+	users    map[int]*models.User
+	students []models.Student
+	groups   []models.Group
+}
+
+func (f *fakeStore) GetUserByID(_ context.Context, id int) (*models.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (f *fakeStore) GetStudentsByIDs(_ context.Context, ids []int) ([]models.Student, error) {
+	var found []models.Student
+	for _, s := range f.students {
+		for _, id := range ids {
+			if s.StudentID == id {
+				found = append(found, s)
+			}
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeStore) GetGroups(context.Context) ([]models.Group, error) {
+	return f.groups, nil
+}
+
+// startServer поднимает сервер на bufconn и возвращает хаб событий и функцию
+// подключения клиента с заданными учётными данными (nil — без них).
+func startServer(t *testing.T) (*realtime.Hub, func(creds credentials.PerRPCCredentials) universityv1.UniversityClient) {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := &fakeStore{
+		users: map[int]*models.User{
+			1: {ID: 1, Email: "admin@example.com", Role: handlers.RoleAdmin, Language: "en"},
+		},
+		students: []models.Student{{StudentID: 7, Name: "Айгерим", Surname: "Сапарова", GroupID: 3}},
+		groups:   []models.Group{{GroupID: 3, GroupName: "ИС-21", Faculty: "ФИТ"}},
+	}
+	hub := realtime.NewHub(nil, logger)
+	srv := New(store, hub, map[string]string{testAPIKey: "reports"}, logger)
+
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(func() {
+		hub.Close()
+		srv.Stop()
+	})
+
+	dial := func(creds credentials.PerRPCCredentials) universityv1.UniversityClient {
+		opts := []grpc.DialOption{
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		}
+		if creds != nil {
+			opts = append(opts, grpc.WithPerRPCCredentials(creds))
+		}
+		conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+		if err != nil {
+			t.Fatalf("подключение к серверу: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return universityv1.NewUniversityClient(conn)
+	}
+	return hub, dial
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestAPIKeyCall(t *testing.T) {
+	_, dial := startServer(t)
+	client := dial(universityv1.APIKey(testAPIKey))
+
+	resp, err := client.ListGroups(testContext(t), &universityv1.ListGroupsRequest{})
+	if err != nil {
+		t.Fatalf("ListGroups: %v", err)
+	}
+	if len(resp.Groups) != 1 || resp.Groups[0].Id != 3 || resp.Groups[0].Name != "ИС-21" {
+		t.Fatalf("ListGroups вернул %v", resp.Groups)
+	}
+}
+
+func TestJWTCall(t *testing.T) {
+	_, dial := startServer(t)
+	token, err := handlers.GenerateToken(1)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	client := dial(universityv1.BearerToken(token))
+
+	student, err := client.GetStudent(testContext(t), &universityv1.GetStudentRequest{Id: 7})
+	if err != nil {
+		t.Fatalf("GetStudent: %v", err)
+	}
+	if student.Id != 7 || student.Surname != "Сапарова" || student.GroupId != 3 {
+		t.Fatalf("GetStudent вернул %v", student)
+	}
+
+	// ошибки приходят на языке из профиля пользователя
+	_, err = client.GetStudent(testContext(t), &universityv1.GetStudentRequest{Id: 8})
+	assertStatus(t, err, codes.NotFound, "students.not_found")
+	if msg := status.Convert(err).Message(); msg == "" || msg == "students.not_found" {
+		t.Fatalf("сообщение об ошибке не переведено: %q", msg)
+	}
+}
+
+func TestMissingCredentials(t *testing.T) {
+	_, dial := startServer(t)
+
+	_, err := dial(nil).ListGroups(testContext(t), &universityv1.ListGroupsRequest{})
+	assertStatus(t, err, codes.Unauthenticated, "auth.header_required")
+
+	_, err = dial(universityv1.APIKey("wrong")).ListGroups(testContext(t), &universityv1.ListGroupsRequest{})
+	assertStatus(t, err, codes.Unauthenticated, "auth.invalid_token")
+}
+
+func TestStreamAttendance(t *testing.T) {
+	hub, dial := startServer(t)
+	ctx := testContext(t)
+
+	stream, err := dial(universityv1.APIKey(testAPIKey)).StreamAttendance(ctx, &universityv1.StreamAttendanceRequest{GroupId: 3})
+	if err != nil {
+		t.Fatalf("StreamAttendance: %v", err)
+	}
+
+	event := models.AttendanceStreamEvent{EventID: 42, SubjectID: 5, GroupID: 3, Name: "Айгерим", Surname: "Сапарова"}
+	event.StudentID = 7
+	event.ScheduleID = 11
+	event.VisitDay = "19.10.2026"
+	event.Visited = true
+	event.Status = "late"
+
+	// сервер подписывается уже после ответа на открытие потока, поэтому событие
+	// публикуется, пока клиент его не получит
+	received := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-received:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				hub.Publish(event)
+			}
+		}
+	}()
+
+	msg, err := stream.Recv()
+	close(received)
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if msg.EventId != 42 || msg.StudentId != 7 || msg.GroupId != 3 || msg.Status != universityv1.AttendanceStatus_ATTENDANCE_STATUS_LATE {
+		t.Fatalf("получено событие %v", msg)
+	}
+}
+
+func assertStatus(t *testing.T, err error, code codes.Code, reason string) {
+	t.Helper()
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != code {
+		t.Fatalf("ожидался код %v, получено %v", code, err)
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			if info.Reason != reason {
+				t.Fatalf("ErrorInfo.Reason = %q, ожидался %q", info.Reason, reason)
+			}
+			return
+		}
+	}
+	t.Fatalf("в статусе нет ErrorInfo: %v", err)
+}
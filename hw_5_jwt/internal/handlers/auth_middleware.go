@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"strings"
 
+	"hw_5_jwt/internal/i18n"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -11,35 +13,41 @@ func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"message": "Authorization header required",
-			})
-			c.Abort()
+			abortWithProblem(c, http.StatusUnauthorized, "auth.header_required")
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"message": "Invalid authorization format. Use Bearer <token>",
-			})
-			c.Abort()
+			abortWithProblem(c, http.StatusUnauthorized, "auth.invalid_format")
 			return
 		}
 
 		tokenString := parts[1]
 		claims, err := ValidateToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"message": "Invalid or expired token",
-			})
-			c.Abort()
+			abortWithProblem(c, http.StatusUnauthorized, "auth.invalid_token")
 			return
 		}
 		c.Set("userID", claims.UserID)
 		c.Next()
 	}
 }
+
+// abortWithProblem отвечает тем же problem+json, что и обработчики Echo. Профиль
+// пользователя здесь недоступен, поэтому язык берётся только из Accept-Language.
+func abortWithProblem(c *gin.Context, status int, code string) {
+	lang := i18n.Negotiate(c.GetHeader("Accept-Language"))
+	if lang == "" {
+		lang = i18n.Default
+	}
+
+	id := c.Writer.Header().Get("X-Request-ID")
+	if id == "" {
+		id = c.GetHeader("X-Request-ID")
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.Header("Content-Language", lang)
+	c.AbortWithStatusJSON(status, newProblem(status, code, i18n.T(lang, code), c.Request.URL.Path, id))
+}
@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	"hw_5_jwt/internal/ical"
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/terms"
+
+	"github.com/labstack/echo/v4"
+)
+
+// scheduleLocation — часовой пояс времени занятий в расписании.
+func scheduleLocation() *time.Location {
+	name := os.Getenv("SCHEDULE_TIMEZONE")
+	if name == "" {
+		name = "Asia/Almaty"
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// withFeed отдаёт запросы к /:id.ics календарю с авторизацией по секрету ссылки,
+// остальные — обычному обработчику с Bearer-токеном.
+func (h *Handler) withFeed(feed, next echo.HandlerFunc) echo.HandlerFunc {
+	authed := h.AuthMiddleware(next)
+	return func(c echo.Context) error {
+		if strings.HasSuffix(c.Param("id"), ".ics") {
+			// секрет ссылки не должен попасть в журнал запросов вместе с URI
+			c.Set(feedTokenKey, takeQueryParam(c.Request(), "token"))
+			return feed(c)
+		}
+		return authed(c)
+	}
+}
+
+// CreateCalendarToken выпускает новый секрет для ссылок на календарь. Секрет
+// показывается один раз, прежние ссылки перестают работать.
+func (h *Handler) CreateCalendarToken(c echo.Context) error {
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		h.logger.Error("ошибка генерации секрета календаря", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+	token := hex.EncodeToString(buf)
+
+	ctx := c.Request().Context()
+	if err := h.repo.SetFeedTokenHash(ctx, user.ID, hashFeedToken(token)); err != nil {
+		h.logger.Error("ошибка сохранения секрета календаря", "user_id", user.ID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "calendar.token_failed")
+	}
+
+	base := c.Scheme() + "://" + c.Request().Host
+	feed := models.CalendarFeed{Token: token, URLs: []string{}}
+
+	switch user.Role {
+	case RoleStudent:
+		if student, err := h.repo.GetStudentByUserID(ctx, user.ID); err == nil {
+			feed.URLs = append(feed.URLs, fmt.Sprintf("%s/api/schedule/group/%d.ics?token=%s", base, student.GroupID, token))
+		}
+	case RoleTeacher:
+		if teacherID, err := h.repo.GetTeacherIDByUserID(ctx, user.ID); err == nil {
+			feed.URLs = append(feed.URLs, fmt.Sprintf("%s/api/schedule/teacher/%d.ics?token=%s", base, teacherID, token))
+		}
+	}
+
+	h.logger.Info("выпущена ссылка на календарь", "user_id", user.ID)
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "calendar.token_created"),
+		Data:    feed,
+	})
+}
+
+// feedTokenKey — ключ контекста, под которым withFeed передаёт секрет ссылки.
+const feedTokenKey = "feedToken"
+
+// feedUser находит пользователя по ?token=; при ошибке ответ уже отправлен.
+func (h *Handler) feedUser(c echo.Context) (*models.User, error) {
+	token, _ := c.Get(feedTokenKey).(string)
+	if token == "" {
+		return nil, h.fail(c, http.StatusUnauthorized, "calendar.token_required")
+	}
+
+	user, err := h.repo.GetUserByFeedTokenHash(c.Request().Context(), hashFeedToken(token))
+	if err != nil {
+		h.logger.Error("ошибка проверки ссылки на календарь", "error", err)
+		return nil, h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+	if user == nil {
+		h.logger.Warn("неверный секрет ссылки на календарь", "path", c.Path())
+		return nil, h.fail(c, http.StatusUnauthorized, "calendar.invalid_token")
+	}
+
+	return user, nil
+}
+
+func feedIDParam(c echo.Context) (int, bool) {
+	id, err := strconv.Atoi(strings.TrimSuffix(c.Param("id"), ".ics"))
+	return id, err == nil && id > 0
+}
+
+func (h *Handler) GetGroupScheduleFeed(c echo.Context) error {
+	groupID, ok := feedIDParam(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_group_id")
+	}
+
+	user, err := h.feedUser(c)
+	if err != nil || user == nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	if user.Role == RoleStudent {
+		student, err := h.repo.GetStudentByUserID(ctx, user.ID)
+		if err != nil || student.GroupID != groupID {
+			h.logger.Warn("нет доступа к календарю группы", "user_id", user.ID, "group_id", groupID)
+			return h.fail(c, http.StatusForbidden, "common.forbidden")
+		}
+	}
+
+	term, ok := queryTerm(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_term")
+	}
+
+	entries, err := h.repo.GetGroupScheduleEntries(ctx, groupID)
+	if err != nil {
+		h.logger.Error("ошибка получения расписания группы", "group_id", groupID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "schedule.get_failed")
+	}
+
+	return h.writeCalendar(c, fmt.Sprintf("Расписание группы %d", groupID), term, entries)
+}
+
+func (h *Handler) GetTeacherScheduleFeed(c echo.Context) error {
+	teacherID, ok := feedIDParam(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_teacher_id")
+	}
+
+	user, err := h.feedUser(c)
+	if err != nil || user == nil {
+		return err
+	}
+
+	return h.teacherSchedule(c, user, teacherID, true)
+}
+
+// GetTeacherSchedule возвращает расписание учителя на семестр в JSON.
+func (h *Handler) GetTeacherSchedule(c echo.Context) error {
+	teacherID, ok := h.teacherIDParam(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	return h.teacherSchedule(c, user, teacherID, false)
+}
+
+// teacherSchedule: учитель видит только своё расписание, администратор — любое.
+func (h *Handler) teacherSchedule(c echo.Context, user *models.User, teacherID int, ics bool) error {
+	ctx := c.Request().Context()
+
+	if user.Role != RoleAdmin {
+		ownID, err := h.repo.GetTeacherIDByUserID(ctx, user.ID)
+		if err != nil || ownID != teacherID {
+			h.logger.Warn("нет доступа к расписанию учителя", "user_id", user.ID, "teacher_id", teacherID)
+			return h.fail(c, http.StatusForbidden, "common.forbidden")
+		}
+	}
+
+	term, ok := queryTerm(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_term")
+	}
+
+	entries, err := h.repo.GetTeacherScheduleEntries(ctx, teacherID, term)
+	if err != nil {
+		h.logger.Error("ошибка получения расписания учителя", "teacher_id", teacherID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "schedule.get_failed")
+	}
+
+	if ics {
+		return h.writeCalendar(c, fmt.Sprintf("Расписание преподавателя %d", teacherID), term, entries)
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   entries,
+	})
+}
+
+// isoWeekday: понедельник — 1, воскресенье — 7, как day_of_week в расписании.
+func isoWeekday(t time.Time) int {
+	return (int(t.Weekday())+6)%7 + 1
+}
+
+func atTime(day time.Time, hhmm string, loc *time.Location) time.Time {
+	t, _ := time.Parse("15:04", hhmm)
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+}
+
+// writeCalendar превращает недельные занятия в повторяющиеся события в границах семестра;
+// праздники исключаются через EXDATE.
+func (h *Handler) writeCalendar(c echo.Context, name, term string, entries []models.ScheduleEntry) error {
+	from, to, _ := terms.Teaching(term)
+	loc := scheduleLocation()
+
+	holidays, err := h.repo.GetHolidays(c.Request().Context(), from, to)
+	if err != nil {
+		h.logger.Error("ошибка получения праздников", "term", term, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "holidays.list_failed")
+	}
+
+	holidayDays := make([]time.Time, 0, len(holidays))
+	for _, hd := range holidays {
+		if d, err := time.Parse("02.01.2006", hd.Date); err == nil {
+			holidayDays = append(holidayDays, d)
+		}
+	}
+
+	cal := ical.Calendar{Name: name, Location: loc}
+	for _, e := range entries {
+		if e.DayOfWeek < 1 || e.DayOfWeek > 7 || e.StartTime == "" || e.EndTime == "" {
+			continue
+		}
+
+		first := from
+		for isoWeekday(first) != e.DayOfWeek {
+			first = first.AddDate(0, 0, 1)
+		}
+		if first.After(to) {
+			continue
+		}
+
+		// UID зависит только от занятия и семестра, поэтому календари обновляют события, а не дублируют их
+		event := ical.Event{
+			UID:         fmt.Sprintf("schedule-%d-%s@hw_5_jwt", e.ScheduleID, term),
+			Summary:     e.LessonName,
+			Description: fmt.Sprintf("%s, группа %d", e.SubjectName, e.GroupID),
+			Start:       atTime(first, e.StartTime, loc),
+			End:         atTime(first, e.EndTime, loc),
+			Until:       atTime(to, e.EndTime, loc),
+		}
+		if e.RoomName != nil {
+			event.Location = *e.RoomName
+		}
+
+		for _, d := range holidayDays {
+			if isoWeekday(d) == e.DayOfWeek {
+				event.ExDates = append(event.ExDates, atTime(d, e.StartTime, loc))
+			}
+		}
+
+		cal.Events = append(cal.Events, event)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="schedule.ics"`)
+	c.Response().WriteHeader(http.StatusOK)
+	if _, err := cal.WriteTo(c.Response()); err != nil {
+		h.logger.Error("ошибка записи календаря", "error", err)
+		return err
+	}
+
+	h.logger.Info("календарь отдан", "name", name, "term", term, "events", len(cal.Events))
+	return nil
+}
+
+func (h *Handler) GetHolidays(c echo.Context) error {
+	term, ok := queryTerm(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_term")
+	}
+	from, to, _ := terms.Bounds(term)
+
+	holidays, err := h.repo.GetHolidays(c.Request().Context(), from, to)
+	if err != nil {
+		h.logger.Error("ошибка получения праздников", "term", term, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "holidays.list_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   holidays,
+	})
+}
+
+func (h *Handler) CreateHoliday(c echo.Context) error {
+	var req models.HolidayRequest
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	date, _ := parseQueryDate(req.Date)
+
+	if err := h.repo.CreateHoliday(c.Request().Context(), date, strings.TrimSpace(req.Name)); err != nil {
+		h.logger.Error("ошибка создания праздника", "date", req.Date, "error", err)
+
+		if strings.Contains(err.Error(), "уже существует") {
+			return h.fail(c, http.StatusConflict, "holidays.exists")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "holidays.create_failed")
+	}
+
+	h.logger.Info("праздник добавлен", "date", req.Date)
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "holidays.created"),
+		Data:    models.Holiday{Date: date.Format("02.01.2006"), Name: strings.TrimSpace(req.Name)},
+	})
+}
+
+func (h *Handler) DeleteHoliday(c echo.Context) error {
+	date, err := time.Parse("02.01.2006", c.Param("date"))
+	if err != nil {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_date")
+	}
+
+	if err := h.repo.DeleteHoliday(c.Request().Context(), date); err != nil {
+		h.logger.Error("ошибка удаления праздника", "date", c.Param("date"), "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "holidays.not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "holidays.delete_failed")
+	}
+
+	h.logger.Info("праздник удалён", "date", c.Param("date"))
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "holidays.deleted"),
+	})
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hw_5_jwt/internal/metrics"
+	"hw_5_jwt/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	checkinCodeStep         = 30 * time.Second
+	checkinCodeDigits       = 6
+	defaultCheckinDuration  = 15
+	defaultCheckinLateAfter = 10
+)
+
+// checkinCode считает одноразовый код по схеме HOTP (RFC 4226) для номера 30-секундного шага.
+func checkinCode(secret []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", checkinCodeDigits, value%1000000)
+}
+
+func checkinStep(t time.Time) int64 {
+	return t.Unix() / int64(checkinCodeStep/time.Second)
+}
+
+// matchCheckinCode принимает код текущего или предыдущего шага,
+// чтобы студент успел ввести код, сменившийся у него на глазах.
+func matchCheckinCode(secret []byte, code string, now time.Time) (int64, bool) {
+	step := checkinStep(now)
+	for _, candidate := range []int64{step, step - 1} {
+		if subtle.ConstantTimeCompare([]byte(checkinCode(secret, candidate)), []byte(code)) == 1 {
+			return candidate, true
+		}
+	}
+	return 0, false
+}
+
+func (h *Handler) OpenCheckinWindow(c echo.Context) error {
+	var req models.CheckinWindowRequest
+
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	if req.DurationMinutes == 0 {
+		req.DurationMinutes = defaultCheckinDuration
+	}
+	if req.LateAfterMinutes == 0 {
+		req.LateAfterMinutes = defaultCheckinLateAfter
+	}
+	if req.LateAfterMinutes > req.DurationMinutes {
+		return h.fail(c, http.StatusBadRequest, "checkin.invalid_duration")
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	now := time.Now()
+	if ok, err := h.requireLessonTeacher(c, req.ScheduleID, now.Format("02.01.2006")); !ok {
+		return err
+	}
+
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		h.logger.Error("ошибка генерации секрета", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	window := &models.CheckinWindow{
+		ScheduleID: req.ScheduleID,
+		VisitDay:   now.Format("02.01.2006"),
+		OpenedBy:   user.ID,
+		OpensAt:    now,
+		LateAfter:  now.Add(time.Duration(req.LateAfterMinutes) * time.Minute),
+		ClosesAt:   now.Add(time.Duration(req.DurationMinutes) * time.Minute),
+		Secret:     secret,
+	}
+
+	if err := h.repo.CreateCheckinWindow(c.Request().Context(), window); err != nil {
+		h.logger.Error("ошибка создания окна отметки", "schedule_id", req.ScheduleID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "lessons.not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "checkin.open_failed")
+	}
+
+	h.logger.Info("окно отметки открыто",
+		"window_id", window.WindowID,
+		"schedule_id", window.ScheduleID,
+		"closes_at", window.ClosesAt,
+	)
+
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "checkin.opened"),
+		Data:    window,
+	})
+}
+
+func (h *Handler) GetCheckinCode(c echo.Context) error {
+	window, err := h.loadOwnCheckinWindow(c)
+	if err != nil || window == nil {
+		return err
+	}
+
+	now := time.Now()
+	if window.ClosedAt != nil || now.After(window.ClosesAt) {
+		return h.fail(c, http.StatusGone, "checkin.closed")
+	}
+
+	step := checkinStep(now)
+	code := checkinCode(window.Secret, step)
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data: models.CheckinCode{
+			WindowID:  window.WindowID,
+			Code:      code,
+			ExpiresAt: time.Unix((step+1)*int64(checkinCodeStep/time.Second), 0),
+			QRPayload: fmt.Sprintf("checkin:%d:%s", window.WindowID, code),
+		},
+	})
+}
+
+func (h *Handler) CloseCheckinWindow(c echo.Context) error {
+	window, err := h.loadOwnCheckinWindow(c)
+	if err != nil || window == nil {
+		return err
+	}
+
+	if err := h.repo.CloseCheckinWindow(c.Request().Context(), window.WindowID); err != nil {
+		h.logger.Error("ошибка закрытия окна отметки", "window_id", window.WindowID, "error", err)
+
+		if strings.Contains(err.Error(), "уже закрыто") {
+			return h.fail(c, http.StatusConflict, "checkin.already_closed")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "checkin.close_failed")
+	}
+
+	h.logger.Info("окно отметки закрыто", "window_id", window.WindowID)
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "checkin.closed"),
+	})
+}
+
+func (h *Handler) SubmitCheckin(c echo.Context) error {
+	var req models.CheckinRequest
+
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	req.Code = strings.TrimSpace(req.Code)
+
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	student, err := h.repo.GetStudentByUserID(c.Request().Context(), user.ID)
+	if err != nil {
+		h.logger.Warn("профиль студента не найден", "user_id", user.ID, "error", err)
+		return h.fail(c, http.StatusForbidden, "common.student_profile_not_found")
+	}
+
+	window, err := h.repo.GetCheckinWindow(c.Request().Context(), req.WindowID)
+	if err != nil {
+		h.logger.Warn("окно отметки не найдено", "window_id", req.WindowID, "error", err)
+		return h.fail(c, http.StatusBadRequest, "checkin.invalid_code")
+	}
+
+	visitDate, _ := time.Parse("02.01.2006", window.VisitDay)
+	enrolled, err := h.repo.IsEnrolledInLesson(c.Request().Context(), student.StudentID, window.ScheduleID, visitDate)
+	if err != nil {
+		h.logger.Error("ошибка проверки записи на предмет", "window_id", window.WindowID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+	if !enrolled {
+		h.logger.Warn("самоотметка без записи на предмет",
+			"window_id", window.WindowID,
+			"student_id", student.StudentID,
+		)
+		return h.fail(c, http.StatusForbidden, "checkin.not_enrolled")
+	}
+
+	now := time.Now()
+	if window.ClosedAt != nil || now.After(window.ClosesAt) {
+		return h.fail(c, http.StatusGone, "checkin.closed")
+	}
+
+	step, ok := matchCheckinCode(window.Secret, req.Code, now)
+	if !ok || step < checkinStep(window.OpensAt) {
+		h.logger.Warn("неверный код самоотметки",
+			"window_id", window.WindowID,
+			"student_id", student.StudentID,
+		)
+		return h.fail(c, http.StatusBadRequest, "checkin.expired_code")
+	}
+
+	attendance := models.AttendanceRequest{
+		Status:  models.AttendancePresent,
+		Visited: true,
+	}
+	if now.After(window.LateAfter) {
+		// окно могут открыть и после начала занятия, поэтому опоздание считается от
+		// начала по расписанию; время открытия — только если оно в расписании не задано
+		started := window.OpensAt
+		if window.LessonStart != nil {
+			started = *window.LessonStart
+		}
+		minutesLate := max(int(now.Sub(started)/time.Minute), 0)
+		attendance.Status = models.AttendanceLate
+		attendance.MinutesLate = &minutesLate
+	}
+
+	if err := h.repo.SubmitCheckin(c.Request().Context(), window, student.StudentID, step, attendance); err != nil {
+		h.logger.Error("ошибка самоотметки",
+			"window_id", window.WindowID,
+			"student_id", student.StudentID,
+			"error", err,
+		)
+
+		if strings.Contains(err.Error(), "уже отмечен") {
+			return h.fail(c, http.StatusConflict, "checkin.already_marked")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "checkin.submit_failed")
+	}
+
+	metrics.AttendanceWritten("checkin", 1)
+	h.logger.Info("студент отметился",
+		"window_id", window.WindowID,
+		"student_id", student.StudentID,
+		"status", attendance.Status,
+	)
+
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "checkin.submitted"),
+		Data: models.CheckinResult{
+			WindowID:    window.WindowID,
+			StudentID:   student.StudentID,
+			Status:      attendance.Status,
+			MinutesLate: attendance.MinutesLate,
+		},
+	})
+}
+
+// loadOwnCheckinWindow загружает окно по :id; управлять им может открывший учитель или админ.
+func (h *Handler) loadOwnCheckinWindow(c echo.Context) (*models.CheckinWindow, error) {
+	idStr := c.Param("id")
+
+	windowID, err := strconv.Atoi(idStr)
+	if err != nil || windowID <= 0 {
+		h.logger.Warn("неверный формат ID окна отметки", "id", idStr)
+		return nil, h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	window, err := h.repo.GetCheckinWindow(c.Request().Context(), windowID)
+	if err != nil {
+		h.logger.Error("ошибка получения окна отметки", "window_id", windowID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return nil, h.fail(c, http.StatusNotFound, "checkin.not_found")
+		}
+
+		return nil, h.fail(c, http.StatusInternalServerError, "checkin.get_failed")
+	}
+
+	user, _ := h.currentUser(c)
+	if user == nil || (user.Role != RoleAdmin && user.ID != window.OpenedBy) {
+		return nil, h.fail(c, http.StatusForbidden, "common.forbidden")
+	}
+
+	return window, nil
+}
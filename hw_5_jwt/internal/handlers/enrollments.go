@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"hw_5_jwt/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) CreateOffering(c echo.Context) error {
+	var req models.OfferingRequest
+
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	offering, err := h.repo.CreateOffering(c.Request().Context(), req)
+	if err != nil {
+		h.logger.Error("ошибка создания предложения предмета", "error", err)
+
+		if strings.Contains(err.Error(), "уже существует") {
+			return h.fail(c, http.StatusConflict, "offerings.exists")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "offerings.create_failed")
+	}
+
+	h.logger.Info("предмет открыт для записи",
+		"offering_id", offering.OfferingID,
+		"subject_id", offering.SubjectID,
+		"group_id", offering.GroupID,
+		"term", offering.Term,
+	)
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "offerings.created"),
+		Data:    offering,
+	})
+}
+
+func (h *Handler) GetOfferings(c echo.Context) error {
+	f, err := parseGradeFilter(c)
+	if err != nil {
+		return h.failErr(c, http.StatusBadRequest, err)
+	}
+
+	offerings, err := h.repo.ListOfferings(c.Request().Context(), f.SubjectID, f.GroupID, f.Term)
+	if err != nil {
+		h.logger.Error("ошибка получения предложений предметов", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "offerings.list_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   offerings,
+	})
+}
+
+func (h *Handler) GetOfferingEnrollments(c echo.Context) error {
+	offeringID, ok := h.offeringIDParam(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	enrollments, err := h.repo.GetOfferingEnrollments(c.Request().Context(), offeringID, c.QueryParam("status"))
+	if err != nil {
+		h.logger.Error("ошибка получения записей на предмет", "offering_id", offeringID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "offerings.enrollments_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   enrollments,
+	})
+}
+
+func (h *Handler) offeringIDParam(c echo.Context) (int, bool) {
+	idStr := c.Param("id")
+	offeringID, err := strconv.Atoi(idStr)
+	if err != nil || offeringID <= 0 {
+		h.logger.Warn("неверный формат ID предложения", "id", idStr)
+		return 0, false
+	}
+	return offeringID, true
+}
+
+// enrollmentTarget определяет студента для записи или отчисления: администратор
+// указывает student_id, студент действует только за себя.
+func (h *Handler) enrollmentTarget(c echo.Context) (int, error) {
+	var req models.EnrollmentRequest
+	if ok, err := h.bind(c, &req); !ok {
+		return 0, err
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return 0, h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	if user.Role == RoleAdmin {
+		if req.StudentID <= 0 {
+			return 0, h.fail(c, http.StatusBadRequest, "common.student_id_required")
+		}
+		return req.StudentID, nil
+	}
+
+	student, err := h.repo.GetStudentByUserID(c.Request().Context(), user.ID)
+	if err != nil || (req.StudentID != 0 && req.StudentID != student.StudentID) {
+		h.logger.Warn("нет доступа к записи на предмет", "user_id", user.ID, "student_id", req.StudentID)
+		return 0, h.fail(c, http.StatusForbidden, "common.forbidden")
+	}
+
+	return student.StudentID, nil
+}
+
+func (h *Handler) Enroll(c echo.Context) error {
+	offeringID, ok := h.offeringIDParam(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	studentID, err := h.enrollmentTarget(c)
+	if err != nil || studentID == 0 {
+		return err
+	}
+
+	enrollment, err := h.repo.Enroll(c.Request().Context(), offeringID, studentID)
+	if err != nil {
+		h.logger.Error("ошибка записи на предмет", "offering_id", offeringID, "student_id", studentID, "error", err)
+
+		switch {
+		case strings.Contains(err.Error(), "не найден"):
+			return h.fail(c, http.StatusNotFound, "subjects.not_found")
+		case strings.Contains(err.Error(), "уже записан"):
+			return h.fail(c, http.StatusConflict, "enrollments.exists")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "enrollments.create_failed")
+	}
+
+	message := "enrollments.enrolled"
+	if enrollment.Status == models.EnrollmentWaitlisted {
+		message = "enrollments.waitlisted"
+	}
+
+	h.logger.Info("запись на предмет",
+		"offering_id", offeringID,
+		"student_id", studentID,
+		"status", enrollment.Status,
+	)
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, message),
+		Data:    enrollment,
+	})
+}
+
+func (h *Handler) Drop(c echo.Context) error {
+	offeringID, ok := h.offeringIDParam(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	studentID, err := h.enrollmentTarget(c)
+	if err != nil || studentID == 0 {
+		return err
+	}
+
+	result, err := h.repo.Drop(c.Request().Context(), offeringID, studentID)
+	if err != nil {
+		h.logger.Error("ошибка отчисления с предмета", "offering_id", offeringID, "student_id", studentID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "enrollments.not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "enrollments.drop_failed")
+	}
+
+	if result.Promoted != nil {
+		h.logger.Info("студент переведён из листа ожидания",
+			"offering_id", offeringID,
+			"student_id", result.Promoted.StudentID,
+		)
+	}
+
+	h.logger.Info("отчисление с предмета", "offering_id", offeringID, "student_id", studentID)
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "enrollments.dropped"),
+		Data:    result,
+	})
+}
+
+// EnrollGroup записывает группу целиком; без group_id берётся группа, для которой открыт предмет.
+func (h *Handler) EnrollGroup(c echo.Context) error {
+	offeringID, ok := h.offeringIDParam(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	var req models.EnrollGroupRequest
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	if req.GroupID == 0 {
+		offering, err := h.repo.GetOffering(c.Request().Context(), offeringID)
+		if err != nil {
+			h.logger.Error("ошибка получения предложения предмета", "offering_id", offeringID, "error", err)
+
+			if strings.Contains(err.Error(), "не найден") {
+				return h.fail(c, http.StatusNotFound, "subjects.not_found")
+			}
+
+			return h.fail(c, http.StatusInternalServerError, "offerings.get_failed")
+		}
+		req.GroupID = offering.GroupID
+	}
+
+	enrollments, err := h.repo.EnrollGroup(c.Request().Context(), offeringID, req.GroupID)
+	if err != nil {
+		h.logger.Error("ошибка записи группы", "offering_id", offeringID, "group_id", req.GroupID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "subjects.not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "enrollments.group_failed")
+	}
+
+	h.logger.Info("группа записана на предмет",
+		"offering_id", offeringID,
+		"group_id", req.GroupID,
+		"count", len(enrollments),
+	)
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "enrollments.group_enrolled"),
+		Data:    enrollments,
+	})
+}
+
+func (h *Handler) GetMyEnrollments(c echo.Context) error {
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	student, err := h.repo.GetStudentByUserID(c.Request().Context(), user.ID)
+	if err != nil {
+		h.logger.Warn("профиль студента не найден", "user_id", user.ID, "error", err)
+		return h.fail(c, http.StatusForbidden, "common.student_profile_not_found")
+	}
+
+	enrollments, err := h.repo.GetStudentEnrollments(c.Request().Context(), student.StudentID, c.QueryParam("term"))
+	if err != nil {
+		h.logger.Error("ошибка получения записей на предметы", "student_id", student.StudentID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "enrollments.list_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   enrollments,
+	})
+}
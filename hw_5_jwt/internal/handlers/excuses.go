@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"hw_5_jwt/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+const maxExcuseDocumentSize = 5 << 20
+
+func (h *Handler) CreateExcuse(c echo.Context) error {
+	var req models.ExcuseRequest
+
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	student, err := h.repo.GetStudentByUserID(c.Request().Context(), user.ID)
+	if err != nil {
+		h.logger.Warn("профиль студента не найден", "user_id", user.ID, "error", err)
+		return h.fail(c, http.StatusForbidden, "common.student_profile_not_found")
+	}
+
+	var document *models.ExcuseDocument
+	if file, err := c.FormFile("document"); err == nil {
+		if file.Size > maxExcuseDocumentSize {
+			return h.fail(c, http.StatusRequestEntityTooLarge, "excuses.document_too_large")
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			h.logger.Error("ошибка открытия документа", "error", err)
+			return h.fail(c, http.StatusBadRequest, "excuses.document_read_failed")
+		}
+		defer src.Close()
+
+		data, err := io.ReadAll(io.LimitReader(src, maxExcuseDocumentSize))
+		if err != nil {
+			h.logger.Error("ошибка чтения документа", "error", err)
+			return h.fail(c, http.StatusBadRequest, "excuses.document_read_failed")
+		}
+
+		document = &models.ExcuseDocument{
+			Name:        file.Filename,
+			ContentType: file.Header.Get("Content-Type"),
+			Data:        data,
+		}
+	}
+
+	excuse := &models.Excuse{
+		StudentID:     student.StudentID,
+		Reason:        req.Reason,
+		AttendanceIDs: req.AttendanceIDs,
+	}
+
+	h.logger.Info("создание объяснительной",
+		"student_id", student.StudentID,
+		"attendance_ids", req.AttendanceIDs,
+		"with_document", document != nil,
+	)
+
+	if err := h.repo.CreateExcuse(c.Request().Context(), excuse, document, user.ID); err != nil {
+		h.logger.Error("ошибка создания объяснительной", "student_id", student.StudentID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusBadRequest, "excuses.own_only")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "excuses.create_failed")
+	}
+
+	h.logger.Info("объяснительная создана", "excuse_id", excuse.ExcuseID)
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "excuses.created"),
+		Data:    excuse,
+	})
+}
+
+func (h *Handler) GetExcuses(c echo.Context) error {
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	var studentID, teacherUserID int
+	switch user.Role {
+	case RoleStudent:
+		student, err := h.repo.GetStudentByUserID(c.Request().Context(), user.ID)
+		if err != nil {
+			h.logger.Warn("профиль студента не найден", "user_id", user.ID, "error", err)
+			return h.fail(c, http.StatusForbidden, "common.student_profile_not_found")
+		}
+		studentID = student.StudentID
+	case RoleTeacher:
+		teacherUserID = user.ID
+	}
+
+	status := c.QueryParam("status")
+
+	excuses, err := h.repo.ListExcuses(c.Request().Context(), studentID, teacherUserID, status)
+	if err != nil {
+		h.logger.Error("ошибка получения объяснительных", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "excuses.list_failed")
+	}
+
+	h.logger.Info("объяснительные успешно получены", "user_id", user.ID, "count", len(excuses))
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   excuses,
+	})
+}
+
+func (h *Handler) GetExcuse(c echo.Context) error {
+	excuse, err := h.loadVisibleExcuse(c)
+	if err != nil || excuse == nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   excuse,
+	})
+}
+
+func (h *Handler) GetExcuseDocument(c echo.Context) error {
+	excuse, err := h.loadVisibleExcuse(c)
+	if err != nil || excuse == nil {
+		return err
+	}
+
+	doc, err := h.repo.GetExcuseDocument(c.Request().Context(), excuse.ExcuseID)
+	if err != nil {
+		h.logger.Error("ошибка получения документа", "excuse_id", excuse.ExcuseID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "excuses.document_not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "excuses.document_failed")
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+strconv.Quote(doc.Name))
+	return c.Blob(http.StatusOK, doc.ContentType, doc.Data)
+}
+
+func (h *Handler) ApproveExcuse(c echo.Context) error {
+	return h.decideExcuse(c, models.ExcuseApproved)
+}
+
+func (h *Handler) RejectExcuse(c echo.Context) error {
+	return h.decideExcuse(c, models.ExcuseRejected)
+}
+
+func (h *Handler) decideExcuse(c echo.Context, status string) error {
+	var req models.ExcuseDecision
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	excuse, err := h.loadVisibleExcuse(c)
+	if err != nil || excuse == nil {
+		return err
+	}
+
+	user, _ := h.currentUser(c)
+
+	// видеть объяснительную учителю достаточно одного своего пропуска, а решение
+	// меняет все связанные отметки, поэтому нужны все
+	if user.Role == RoleTeacher {
+		ok, err := h.repo.TeachesAllExcuseLessons(c.Request().Context(), excuse.ExcuseID, user.ID)
+		if err != nil {
+			h.logger.Error("ошибка проверки учителя", "excuse_id", excuse.ExcuseID, "error", err)
+			return h.fail(c, http.StatusInternalServerError, "common.server_error")
+		}
+		if !ok {
+			h.logger.Warn("объяснительная затрагивает предметы других учителей", "excuse_id", excuse.ExcuseID, "user_id", user.ID)
+			return h.fail(c, http.StatusForbidden, "excuses.other_teachers")
+		}
+	}
+
+	h.logger.Info("рассмотрение объяснительной",
+		"excuse_id", excuse.ExcuseID,
+		"status", status,
+		"user_id", user.ID,
+	)
+
+	if err := h.repo.DecideExcuse(c.Request().Context(), excuse.ExcuseID, status, user.ID, req.Comment); err != nil {
+		h.logger.Error("ошибка рассмотрения объяснительной", "excuse_id", excuse.ExcuseID, "error", err)
+
+		if strings.Contains(err.Error(), "уже рассмотрена") {
+			return h.fail(c, http.StatusConflict, "excuses.already_decided")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "excuses.decide_failed")
+	}
+
+	message := "excuses.approved"
+	if status == models.ExcuseRejected {
+		message = "excuses.rejected"
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, message),
+	})
+}
+
+// loadVisibleExcuse загружает объяснительную по :id и проверяет доступ: студент видит только свои,
+// учитель — по своим предметам, админ — все. При отказе ответ уже записан и возвращается nil.
+func (h *Handler) loadVisibleExcuse(c echo.Context) (*models.Excuse, error) {
+	idStr := c.Param("id")
+
+	excuseID, err := strconv.Atoi(idStr)
+	if err != nil || excuseID <= 0 {
+		h.logger.Warn("неверный формат ID объяснительной", "id", idStr)
+		return nil, h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return nil, h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	excuse, err := h.repo.GetExcuse(c.Request().Context(), excuseID)
+	if err != nil {
+		h.logger.Error("ошибка получения объяснительной", "excuse_id", excuseID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return nil, h.fail(c, http.StatusNotFound, "excuses.not_found")
+		}
+
+		return nil, h.fail(c, http.StatusInternalServerError, "excuses.get_failed")
+	}
+
+	allowed := user.Role == RoleAdmin
+	switch user.Role {
+	case RoleStudent:
+		student, err := h.repo.GetStudentByUserID(c.Request().Context(), user.ID)
+		allowed = err == nil && student.StudentID == excuse.StudentID
+	case RoleTeacher:
+		allowed, err = h.repo.IsExcuseTeacher(c.Request().Context(), excuse.ExcuseID, user.ID)
+		if err != nil {
+			h.logger.Error("ошибка проверки учителя", "excuse_id", excuseID, "error", err)
+			return nil, h.fail(c, http.StatusInternalServerError, "common.server_error")
+		}
+	}
+
+	if !allowed {
+		h.logger.Warn("нет доступа к объяснительной", "excuse_id", excuseID, "user_id", user.ID)
+		return nil, h.fail(c, http.StatusForbidden, "common.forbidden")
+	}
+
+	return excuse, nil
+}
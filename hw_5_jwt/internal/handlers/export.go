@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"hw_5_jwt/internal/export"
+	"hw_5_jwt/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// отметки в ячейках журнала, как в бумажных ведомостях
+var markSymbols = map[string]string{
+	models.AttendancePresent: "+",
+	models.AttendanceLate:    "о",
+	models.AttendanceAbsent:  "н",
+	models.AttendanceExcused: "у",
+	models.AttendanceRemote:  "д",
+}
+
+const markLegend = "+ присутствовал, о опоздал, н отсутствовал, у уважительная причина, д дистанционно"
+
+// колонки журнала до и после дат
+var (
+	sheetLead  = []string{"Фамилия", "Имя", "Группа"}
+	sheetTrail = []string{"Был", "Пропуски", "%"}
+)
+
+// ширины колонок PDF в пунктах
+const (
+	pdfLeadWidth  = 80.0
+	pdfDateWidth  = 22.0
+	pdfTrailWidth = 36.0
+)
+
+// columnLabels подписывает колонки датой, а если в один день несколько занятий — ещё и временем.
+func columnLabels(columns []models.AttendanceColumn) []string {
+	perDay := map[string]int{}
+	for _, col := range columns {
+		perDay[col.Date.Format(time.DateOnly)]++
+	}
+
+	labels := make([]string, len(columns))
+	for i, col := range columns {
+		labels[i] = col.Date.Format("02.01")
+		if perDay[col.Date.Format(time.DateOnly)] > 1 && col.StartTime != "" {
+			labels[i] += " " + col.StartTime
+		}
+	}
+	return labels
+}
+
+// sheetCells переводит строку журнала в ячейки: ФИО, группа, отметки в колонках
+// [from, to) и итоги по всему периоду. Итоги считаются так же, как в статистике.
+func sheetCells(row models.AttendanceSheetRow, from, to int) []string {
+	cells := []string{row.Surname, row.Name, row.GroupName}
+	for _, status := range row.Marks[from:to] {
+		cells = append(cells, markSymbols[status])
+	}
+
+	var visited, missed, counted int
+	for _, status := range row.Marks {
+		switch status {
+		case models.AttendancePresent, models.AttendanceLate, models.AttendanceRemote:
+			visited++
+			counted++
+		case models.AttendanceAbsent:
+			missed++
+			counted++
+		}
+	}
+	rate := ""
+	if counted > 0 {
+		rate = strconv.Itoa(visited * 100 / counted)
+	}
+	return append(cells, strconv.Itoa(visited), strconv.Itoa(missed), rate)
+}
+
+// ExportAttendance: GET /attendance/export?subject_id=&group_id=&student_id=&from=&to=&format=csv|xlsx|pdf.
+// Журнал «студенты × занятия» пишется в ответ по мере чтения из базы. Студент может
+// выгрузить только собственную посещаемость.
+func (h *Handler) ExportAttendance(c echo.Context) error {
+	f, err := parseStatsFilter(c)
+	if err != nil {
+		return h.failErr(c, http.StatusBadRequest, err)
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	if _, ok := export.ContentTypes[format]; !ok {
+		return h.fail(c, http.StatusBadRequest, "export.invalid_format")
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	if user.Role == RoleStudent {
+		own, err := h.repo.GetStudentByUserID(c.Request().Context(), user.ID)
+		if err != nil || (f.StudentID != 0 && own.StudentID != f.StudentID) {
+			h.logger.Warn("нет доступа к выгрузке посещаемости", "student_id", f.StudentID, "user_id", user.ID)
+			return h.fail(c, http.StatusForbidden, "common.forbidden")
+		}
+		f.StudentID = own.StudentID
+	}
+
+	if f.SubjectID == 0 && f.GroupID == 0 && f.StudentID == 0 {
+		return h.fail(c, http.StatusBadRequest, "export.filter_required")
+	}
+
+	ctx := c.Request().Context()
+	columns, err := h.repo.GetAttendanceColumns(ctx, f)
+	if err != nil {
+		h.logger.Error("ошибка получения занятий для выгрузки", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "export.failed")
+	}
+	labels := columnLabels(columns)
+
+	filename := fmt.Sprintf("attendance_%s_%s.%s", f.From.Format("20060102"), f.To.Format("20060102"), format)
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, export.ContentTypes[format])
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	res.WriteHeader(http.StatusOK)
+
+	// после WriteHeader ответ уже начат: ошибки можно только записать в лог
+	var rows int
+	err = func() error {
+		if format == export.FormatPDF {
+			return h.exportAttendancePDF(c, f, columns, labels, &rows)
+		}
+
+		var w export.Writer
+		if format == export.FormatXLSX {
+			w, err = export.NewXLSX(res, "Посещаемость")
+		} else {
+			w, err = export.NewCSV(res)
+		}
+		if err != nil {
+			return err
+		}
+
+		header := append(append(append([]string{}, sheetLead...), labels...), sheetTrail...)
+		if err := w.Header(header); err != nil {
+			return err
+		}
+		err := h.repo.StreamAttendanceSheet(ctx, f, columns, func(row models.AttendanceSheetRow) error {
+			rows++
+			if rows%200 == 0 {
+				res.Flush()
+			}
+			return w.Row(sheetCells(row, 0, len(columns)))
+		})
+		if err != nil {
+			return err
+		}
+		return w.Close()
+	}()
+	if err != nil {
+		h.logger.Error("выгрузка посещаемости прервана", "format", format, "rows", rows, "error", err)
+		return nil
+	}
+
+	h.logger.Info("посещаемость выгружена",
+		"format", format,
+		"subject_id", f.SubjectID,
+		"group_id", f.GroupID,
+		"student_id", f.StudentID,
+		"lessons", len(columns),
+		"rows", rows,
+	)
+	return nil
+}
+
+// exportAttendancePDF печатает журнал на альбомных листах. Если занятия не помещаются
+// по ширине, журнал печатается несколькими блоками колонок; каждый блок читается из
+// базы заново, чтобы не держать весь журнал в памяти.
+func (h *Handler) exportAttendancePDF(c echo.Context, f models.StatsFilter, columns []models.AttendanceColumn, labels []string, rows *int) error {
+	doc := export.NewPDF(c.Response(),
+		"Attendance sheet",
+		fmt.Sprintf("Period: %s - %s", f.From.Format("02.01.2006"), f.To.Format("02.01.2006")),
+		markLegend,
+	)
+
+	perPage := int((doc.Width() - pdfLeadWidth*float64(len(sheetLead)) - pdfTrailWidth*float64(len(sheetTrail))) / pdfDateWidth)
+	if perPage < 1 {
+		perPage = 1
+	}
+
+	for from := 0; from == 0 || from < len(columns); from += perPage {
+		to := min(from+perPage, len(columns))
+
+		var widths []float64
+		header := append([]string{}, sheetLead...)
+		for range sheetLead {
+			widths = append(widths, pdfLeadWidth)
+		}
+		for _, label := range labels[from:to] {
+			header = append(header, label)
+			widths = append(widths, pdfDateWidth)
+		}
+		for _, label := range sheetTrail {
+			header = append(header, label)
+			widths = append(widths, pdfTrailWidth)
+		}
+
+		doc.SetWidths(widths)
+		if err := doc.Header(header); err != nil {
+			return err
+		}
+		*rows = 0
+		err := h.repo.StreamAttendanceSheet(c.Request().Context(), f, columns, func(row models.AttendanceSheetRow) error {
+			*rows++
+			return doc.Row(sheetCells(row, from, to))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return doc.Close()
+}
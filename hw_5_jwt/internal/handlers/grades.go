@@ -0,0 +1,473 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"hw_5_jwt/internal/i18n"
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/terms"
+
+	"github.com/labstack/echo/v4"
+)
+
+// parseGradeFilter читает необязательные student_id, subject_id, group_id и term.
+func parseGradeFilter(c echo.Context) (models.GradeFilter, error) {
+	var f models.GradeFilter
+
+	ids := map[string]*int{
+		"student_id": &f.StudentID,
+		"subject_id": &f.SubjectID,
+		"group_id":   &f.GroupID,
+	}
+	for name, dest := range ids {
+		value := c.QueryParam(name)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return f, i18n.Errorf("common.invalid_param", name)
+		}
+		*dest = id
+	}
+
+	f.Term = c.QueryParam("term")
+	if f.Term != "" {
+		if _, _, err := terms.Bounds(f.Term); err != nil {
+			return f, i18n.Errorf("common.invalid_term")
+		}
+	}
+
+	return f, nil
+}
+
+// canGradeSubject: администратор оценивает любой предмет, учитель — только назначенные ему
+// предмет и группу в семестре.
+func (h *Handler) canGradeSubject(c echo.Context, user *models.User, subjectID, groupID int, term string) (bool, error) {
+	switch user.Role {
+	case RoleAdmin:
+		return true, nil
+	case RoleTeacher:
+		return h.repo.IsAssignedTeacher(c.Request().Context(), user.ID, subjectID, groupID, term)
+	}
+	return false, nil
+}
+
+// loadGradableAssessment загружает мероприятие из :id и проверяет право выставлять по нему оценки.
+func (h *Handler) loadGradableAssessment(c echo.Context) (*models.Assessment, *models.User, error) {
+	idStr := c.Param("id")
+
+	assessmentID, err := strconv.Atoi(idStr)
+	if err != nil || assessmentID <= 0 {
+		h.logger.Warn("неверный формат ID мероприятия", "id", idStr)
+		return nil, nil, h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return nil, nil, h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	assessment, err := h.repo.GetAssessment(c.Request().Context(), assessmentID)
+	if err != nil {
+		h.logger.Error("ошибка получения мероприятия", "assessment_id", assessmentID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return nil, nil, h.fail(c, http.StatusNotFound, "assessments.not_found")
+		}
+
+		return nil, nil, h.fail(c, http.StatusInternalServerError, "assessments.get_failed")
+	}
+
+	allowed, err := h.canGradeSubject(c, user, assessment.SubjectID, assessment.GroupID, assessment.Term)
+	if err != nil {
+		h.logger.Error("ошибка проверки учителя", "assessment_id", assessmentID, "error", err)
+		return nil, nil, h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	if !allowed {
+		h.logger.Warn("нет доступа к мероприятию", "assessment_id", assessmentID, "user_id", user.ID)
+		return nil, nil, h.fail(c, http.StatusForbidden, "common.forbidden")
+	}
+
+	return assessment, user, nil
+}
+
+func (h *Handler) CreateAssessment(c echo.Context) error {
+	var req models.AssessmentRequest
+
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	req.Title = strings.TrimSpace(req.Title)
+
+	assessment := &models.Assessment{
+		SubjectID: req.SubjectID,
+		GroupID:   req.GroupID,
+		Kind:      req.Kind,
+		Title:     req.Title,
+		Term:      req.Term,
+		Weight:    req.Weight,
+		MaxScore:  req.MaxScore,
+	}
+
+	if req.HeldOn != "" {
+		heldOn, _ := normalizeDate(req.HeldOn)
+		assessment.HeldOn = &heldOn
+	}
+
+	// без term семестр определяется по дате проведения
+	if assessment.Term == "" {
+		if assessment.HeldOn == nil {
+			return h.fail(c, http.StatusBadRequest, "assessments.term_or_date")
+		}
+		heldOn, _ := parseQueryDate(*assessment.HeldOn)
+		assessment.Term = terms.For(heldOn)
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	allowed, err := h.canGradeSubject(c, user, assessment.SubjectID, assessment.GroupID, assessment.Term)
+	if err != nil {
+		h.logger.Error("ошибка проверки учителя", "subject_id", req.SubjectID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+	if !allowed {
+		return h.fail(c, http.StatusForbidden, "assessments.own_subjects_only")
+	}
+
+	if err := h.repo.CreateAssessment(c.Request().Context(), assessment, user.ID); err != nil {
+		h.logger.Error("ошибка создания мероприятия", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "assessments.create_failed")
+	}
+
+	h.logger.Info("оценочное мероприятие создано",
+		"assessment_id", assessment.AssessmentID,
+		"subject_id", assessment.SubjectID,
+		"group_id", assessment.GroupID,
+		"kind", assessment.Kind,
+	)
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "assessments.created"),
+		Data:    assessment,
+	})
+}
+
+func (h *Handler) GetAssessments(c echo.Context) error {
+	f, err := parseGradeFilter(c)
+	if err != nil {
+		return h.failErr(c, http.StatusBadRequest, err)
+	}
+
+	assessments, err := h.repo.ListAssessments(c.Request().Context(), f.SubjectID, f.GroupID, f.Term)
+	if err != nil {
+		h.logger.Error("ошибка получения мероприятий", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "assessments.list_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   assessments,
+	})
+}
+
+func (h *Handler) DeleteAssessment(c echo.Context) error {
+	assessment, _, err := h.loadGradableAssessment(c)
+	if err != nil || assessment == nil {
+		return err
+	}
+
+	if err := h.repo.DeleteAssessment(c.Request().Context(), assessment.AssessmentID); err != nil {
+		h.logger.Error("ошибка удаления мероприятия", "assessment_id", assessment.AssessmentID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "assessments.delete_failed")
+	}
+
+	h.logger.Info("оценочное мероприятие удалено", "assessment_id", assessment.AssessmentID)
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "assessments.deleted"),
+	})
+}
+
+func (h *Handler) GetAssessmentGrades(c echo.Context) error {
+	assessment, _, err := h.loadGradableAssessment(c)
+	if err != nil || assessment == nil {
+		return err
+	}
+
+	grades, err := h.repo.GetGrades(c.Request().Context(), models.GradeFilter{AssessmentID: assessment.AssessmentID})
+	if err != nil {
+		h.logger.Error("ошибка получения оценок", "assessment_id", assessment.AssessmentID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "grades.list_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   grades,
+	})
+}
+
+// SetGrade выставляет оценку одному студенту: PUT /assessments/:id/grades/:student_id.
+func (h *Handler) SetGrade(c echo.Context) error {
+	studentIDStr := c.Param("student_id")
+	studentID, err := strconv.Atoi(studentIDStr)
+	if err != nil || studentID <= 0 {
+		h.logger.Warn("неверный формат ID студента", "id", studentIDStr)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	// student_id берётся из пути (тег param), тело его не переопределяет
+	var grade models.GradeRequest
+	if ok, err := h.bind(c, &grade); !ok {
+		return err
+	}
+	grade.StudentID = studentID
+
+	results, status, err := h.saveGrades(c, []models.GradeRequest{grade})
+	if err != nil || results == nil {
+		return err
+	}
+
+	if results[0].Status != "saved" {
+		return h.problem(c, models.Problem{Status: status, Code: results[0].Code, Detail: results[0].Error})
+	}
+
+	return c.JSON(status, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "grades.saved_one"),
+		Data:    results[0],
+	})
+}
+
+func (h *Handler) SetGradesBulk(c echo.Context) error {
+	var req models.BulkGradeRequest
+
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	results, status, err := h.saveGrades(c, req.Grades)
+	if err != nil || results == nil {
+		return err
+	}
+
+	if status != http.StatusOK {
+		return h.problem(c, models.Problem{Status: status, Code: "grades.rejected", Data: results})
+	}
+
+	return c.JSON(status, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "grades.saved"),
+		Data:    results,
+	})
+}
+
+// saveGrades проверяет оценки и сохраняет их все или ни одной, как и перекличка.
+// Ответ с ошибкой доступа или сервера уже отправлен, если results == nil.
+func (h *Handler) saveGrades(c echo.Context, grades []models.GradeRequest) ([]models.BulkGradeResult, int, error) {
+	assessment, user, err := h.loadGradableAssessment(c)
+	if err != nil || assessment == nil {
+		return nil, 0, err
+	}
+
+	studentIDs, err := h.repo.GetEnrolledStudentIDs(c.Request().Context(), assessment.SubjectID, assessment.GroupID, assessment.Term)
+	if err != nil {
+		h.logger.Error("ошибка получения записанных студентов", "assessment_id", assessment.AssessmentID, "error", err)
+		return nil, 0, h.fail(c, http.StatusInternalServerError, "grades.students_failed")
+	}
+
+	enrolled := make(map[int]bool, len(studentIDs))
+	for _, id := range studentIDs {
+		enrolled[id] = true
+	}
+
+	results := make([]models.BulkGradeResult, 0, len(grades))
+	seen := make(map[int]bool, len(grades))
+	invalid := 0
+	for _, grade := range grades {
+		result := models.BulkGradeResult{StudentID: grade.StudentID, Status: "saved"}
+		reject := func(code string, args ...any) {
+			result.Status, result.Code, result.Error = "rejected", code, h.tr(c, code, args...)
+		}
+		switch {
+		case grade.StudentID <= 0:
+			reject("common.student_id_required")
+		case seen[grade.StudentID]:
+			reject("common.student_duplicate")
+		case !enrolled[grade.StudentID]:
+			reject("grades.not_enrolled")
+		case grade.Score < 0 || grade.Score > assessment.MaxScore:
+			reject("grades.score_range", assessment.MaxScore)
+		}
+		if result.Status == "rejected" {
+			invalid++
+		}
+		seen[grade.StudentID] = true
+		results = append(results, result)
+	}
+
+	if invalid > 0 {
+		h.logger.Warn("оценки отклонены", "assessment_id", assessment.AssessmentID, "invalid", invalid)
+		for i := range results {
+			if results[i].Status == "saved" {
+				results[i].Status = "skipped"
+			}
+		}
+		return results, http.StatusUnprocessableEntity, nil
+	}
+
+	if err := h.repo.UpsertGrades(c.Request().Context(), assessment.AssessmentID, grades, user.ID); err != nil {
+		h.logger.Error("ошибка записи оценок", "assessment_id", assessment.AssessmentID, "error", err)
+		return nil, 0, h.fail(c, http.StatusInternalServerError, "grades.save_failed")
+	}
+
+	h.logger.Info("оценки сохранены", "assessment_id", assessment.AssessmentID, "count", len(grades))
+	return results, http.StatusOK, nil
+}
+
+// GetMyGrades возвращает студенту только его оценки и итоговые баллы.
+func (h *Handler) GetMyGrades(c echo.Context) error {
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	student, err := h.repo.GetStudentByUserID(c.Request().Context(), user.ID)
+	if err != nil {
+		h.logger.Warn("профиль студента не найден", "user_id", user.ID, "error", err)
+		return h.fail(c, http.StatusForbidden, "common.student_profile_not_found")
+	}
+
+	return h.studentGradesResponse(c, student.StudentID)
+}
+
+func (h *Handler) GetStudentGrades(c echo.Context) error {
+	idStr := c.Param("id")
+
+	studentID, err := strconv.Atoi(idStr)
+	if err != nil || studentID <= 0 {
+		h.logger.Warn("неверный формат ID студента", "id", idStr)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	return h.studentGradesResponse(c, studentID)
+}
+
+func (h *Handler) studentGradesResponse(c echo.Context, studentID int) error {
+	f, err := parseGradeFilter(c)
+	if err != nil {
+		return h.failErr(c, http.StatusBadRequest, err)
+	}
+	f.StudentID = studentID
+
+	grades, err := h.repo.GetGrades(c.Request().Context(), f)
+	if err != nil {
+		h.logger.Error("ошибка получения оценок", "student_id", studentID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "grades.list_failed")
+	}
+
+	finals, err := h.repo.GetFinalGrades(c.Request().Context(), f)
+	if err != nil {
+		h.logger.Error("ошибка расчёта итоговых оценок", "student_id", studentID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "grades.list_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data: models.StudentGrades{
+			StudentID: studentID,
+			Grades:    grades,
+			Finals:    finals,
+		},
+	})
+}
+
+func (h *Handler) GetFinalGrades(c echo.Context) error {
+	f, err := parseGradeFilter(c)
+	if err != nil {
+		return h.failErr(c, http.StatusBadRequest, err)
+	}
+
+	finals, err := h.repo.GetFinalGrades(c.Request().Context(), f)
+	if err != nil {
+		h.logger.Error("ошибка расчёта итоговых оценок", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "grades.finals_failed")
+	}
+
+	h.logger.Info("итоговые оценки получены", "count", len(finals))
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   finals,
+	})
+}
+
+func (h *Handler) GetGradingScale(c echo.Context) error {
+	scale, err := h.repo.GetGradingScale(c.Request().Context())
+	if err != nil {
+		h.logger.Error("ошибка получения шкалы оценивания", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "grading_scale.get_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   scale,
+	})
+}
+
+// SetGradingScale заменяет шкалу целиком. Шкала должна начинаться с порога 0,
+// чтобы любой итоговый балл получал букву.
+func (h *Handler) SetGradingScale(c echo.Context) error {
+	var scale []models.GradingScaleEntry
+
+	if ok, err := h.bind(c, &scale); !ok {
+		return err
+	}
+
+	if len(scale) == 0 {
+		return h.fail(c, http.StatusBadRequest, "grading_scale.empty")
+	}
+
+	sort.Slice(scale, func(i, j int) bool { return scale[i].MinPercent > scale[j].MinPercent })
+
+	letters := make(map[string]bool, len(scale))
+	for i, entry := range scale {
+		entry.Letter = strings.TrimSpace(entry.Letter)
+		scale[i].Letter = entry.Letter
+		switch {
+		case letters[entry.Letter]:
+			return h.fail(c, http.StatusBadRequest, "grading_scale.duplicate_letter", entry.Letter)
+		case i > 0 && entry.MinPercent == scale[i-1].MinPercent:
+			return h.fail(c, http.StatusBadRequest, "grading_scale.duplicate_threshold")
+		case i > 0 && entry.GPAPoints > scale[i-1].GPAPoints:
+			return h.fail(c, http.StatusBadRequest, "grading_scale.gpa_order")
+		}
+		letters[entry.Letter] = true
+	}
+
+	if scale[len(scale)-1].MinPercent != 0 {
+		return h.fail(c, http.StatusBadRequest, "grading_scale.lowest_zero")
+	}
+
+	if err := h.repo.ReplaceGradingScale(c.Request().Context(), scale); err != nil {
+		h.logger.Error("ошибка сохранения шкалы оценивания", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "grading_scale.save_failed")
+	}
+
+	h.logger.Info("шкала оценивания обновлена", "levels", len(scale))
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "grading_scale.updated"),
+		Data:    scale,
+	})
+}
@@ -0,0 +1,451 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"hw_5_jwt/internal/graphql"
+	"hw_5_jwt/internal/i18n"
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/terms"
+
+	"github.com/labstack/echo/v4"
+)
+
+type graphqlUserKey struct{}
+
+// GraphQL выполняет запрос из тела POST или из параметров GET. Ошибки запроса
+// и полей возвращаются в errors по спецификации GraphQL, статус всегда 200.
+func (h *Handler) GraphQL(c echo.Context) error {
+	var req graphql.Request
+	if c.Request().Method == http.MethodGet {
+		req.Query = c.QueryParam("query")
+		req.OperationName = c.QueryParam("operationName")
+		if vars := c.QueryParam("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				return h.fail(c, http.StatusBadRequest, "graphql.invalid_variables")
+			}
+		}
+	} else if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+	if user == nil {
+		return h.fail(c, http.StatusUnauthorized, "auth.unauthenticated")
+	}
+
+	ctx := context.WithValue(c.Request().Context(), graphqlUserKey{}, user)
+	resp := h.schema.Execute(ctx, req)
+	for _, e := range resp.Errors {
+		code := "common.server_error"
+		var msg *i18n.Message
+		if errors.As(e.Err, &msg) {
+			code = msg.ID
+			e.Message = h.tr(c, msg.ID, msg.Args...)
+		} else {
+			h.logger.Error("ошибка выполнения GraphQL", "path", e.Path, "error", e.Err)
+			e.Message = h.tr(c, code)
+		}
+		e.Extensions = map[string]any{"code": code}
+	}
+
+	if len(resp.Errors) > 0 && resp.Data == nil {
+		h.logger.Warn("запрос GraphQL отклонён", "user_id", user.ID, "errors", len(resp.Errors))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetGraphQLSchema отдаёт схему в SDL для генераторов клиентов.
+func (h *Handler) GetGraphQLSchema(c echo.Context) error {
+	return c.String(http.StatusOK, h.schema.SDL())
+}
+
+func graphqlUser(ctx context.Context) *models.User {
+	user, _ := ctx.Value(graphqlUserKey{}).(*models.User)
+	return user
+}
+
+// requireRoles — проверка роли для поля, как RequireRole для маршрута.
+func (h *Handler) requireRoles(resolve graphql.ResolveFunc, roles ...string) graphql.ResolveFunc {
+	return func(ctx context.Context, parents []any, args graphql.Args) ([]any, error) {
+		user := graphqlUser(ctx)
+		if user == nil || !slices.Contains(roles, user.Role) {
+			if user != nil {
+				h.logger.Warn("недостаточно прав", "user_id", user.ID, "role", user.Role, "path", "/graphql")
+			}
+			return nil, i18n.Errorf("common.forbidden")
+		}
+		return resolve(ctx, parents, args)
+	}
+}
+
+func byID[V any](items []V, id func(V) int) map[int]V {
+	out := make(map[int]V, len(items))
+	for _, item := range items {
+		out[id(item)] = item
+	}
+	return out
+}
+
+func groupByID[V any](items []V, id func(V) int) map[int][]V {
+	out := make(map[int][]V)
+	for _, item := range items {
+		out[id(item)] = append(out[id(item)], item)
+	}
+	return out
+}
+
+// first — единственный объект по ID или nil, если его нет.
+func first[V any](items []V, err error) (any, error) {
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+func nullString(s sql.NullString) any {
+	if !s.Valid {
+		return nil
+	}
+	return s.String
+}
+
+func optionalString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func prop[P any](name, typ string, get func(P) any) *graphql.FieldDef {
+	return &graphql.FieldDef{Name: name, Type: typ, Resolve: graphql.Prop(get)}
+}
+
+// newGraphQLSchema описывает граф поверх Repository. Права те же, что у REST API:
+// справочники и посещаемость доступны любому вошедшему пользователю, нагрузка
+// преподавателей — только сотрудникам.
+func (h *Handler) newGraphQLSchema() *graphql.Schema {
+	loadStudents := func(ctx context.Context, ids []int, _ graphql.Args) (map[int]models.Student, error) {
+		students, err := h.repo.GetStudentsByIDs(ctx, ids)
+		return byID(students, func(s models.Student) int { return s.StudentID }), err
+	}
+	loadGroups := func(ctx context.Context, ids []int, _ graphql.Args) (map[int]models.Group, error) {
+		groups, err := h.repo.GetGroupsByIDs(ctx, ids)
+		return byID(groups, func(g models.Group) int { return g.GroupID }), err
+	}
+	loadTeachers := func(ctx context.Context, ids []int, _ graphql.Args) (map[int]models.Teacher, error) {
+		teachers, err := h.repo.GetTeachersByIDs(ctx, ids)
+		return byID(teachers, func(t models.Teacher) int { return t.ID }), err
+	}
+	loadSubjects := func(ctx context.Context, ids []int, _ graphql.Args) (map[int]models.SubjectSummary, error) {
+		subjects, err := h.repo.GetSubjects(ctx, ids)
+		return byID(subjects, func(s models.SubjectSummary) int { return s.SubjectID }), err
+	}
+	loadLessons := func(ctx context.Context, ids []int, _ graphql.Args) (map[int]models.ScheduleEntry, error) {
+		lessons, err := h.repo.GetScheduleEntriesByIDs(ctx, ids)
+		return byID(lessons, func(l models.ScheduleEntry) int { return l.ScheduleID }), err
+	}
+	groupLessons := func(ctx context.Context, ids []int, _ graphql.Args) (map[int][]models.ScheduleEntry, error) {
+		lessons, err := h.repo.GetScheduleEntriesByGroupIDs(ctx, ids)
+		return groupByID(lessons, func(l models.ScheduleEntry) int { return l.GroupID }), err
+	}
+
+	user := &graphql.Object{
+		Name:        "User",
+		Description: "Учётная запись",
+		Fields: []*graphql.FieldDef{
+			prop("id", "Int!", func(u *models.User) any { return u.ID }),
+			prop("email", "String!", func(u *models.User) any { return u.Email }),
+			prop("name", "String", func(u *models.User) any { return nullString(u.Name) }),
+			prop("surname", "String", func(u *models.User) any { return nullString(u.Surname) }),
+			prop("role", "String!", func(u *models.User) any { return u.Role }),
+			prop("language", "String", func(u *models.User) any { return optionalString(u.Language) }),
+			prop("createdAt", "String!", func(u *models.User) any { return u.CreatedAt.Format(time.RFC3339) }),
+			{
+				Name: "student", Type: "Student", Description: "Профиль студента, если пользователь — студент",
+				Resolve: graphql.Load(func(u *models.User) int { return u.ID },
+					func(ctx context.Context, ids []int, _ graphql.Args) (map[int]models.Student, error) {
+						students, err := h.repo.GetStudentsByUserIDs(ctx, ids)
+						return byID(students, func(s models.Student) int { return s.UserId }), err
+					}),
+			},
+			{
+				Name: "teacher", Type: "Teacher", Description: "Профиль преподавателя, если пользователь — преподаватель",
+				Resolve: graphql.Load(func(u *models.User) int { return u.ID },
+					func(ctx context.Context, ids []int, _ graphql.Args) (map[int]models.Teacher, error) {
+						teachers, err := h.repo.GetTeachersByUserIDs(ctx, ids)
+						return byID(teachers, func(t models.Teacher) int { return t.UserId }), err
+					}),
+			},
+		},
+	}
+
+	student := &graphql.Object{
+		Name: "Student",
+		Fields: []*graphql.FieldDef{
+			prop("id", "Int!", func(s models.Student) any { return s.StudentID }),
+			prop("name", "String!", func(s models.Student) any { return s.Name }),
+			prop("surname", "String!", func(s models.Student) any { return s.Surname }),
+			prop("gender", "String", func(s models.Student) any { return optionalString(s.Gender) }),
+			prop("birthday", "String", func(s models.Student) any {
+				if s.Birthday.Year() <= 1 {
+					return nil
+				}
+				return s.Birthday.Format("02.01.2006")
+			}),
+			prop("groupId", "Int", func(s models.Student) any { return s.GroupID }),
+			{Name: "group", Type: "Group", Resolve: graphql.Load(func(s models.Student) int { return s.GroupID }, loadGroups)},
+			{
+				Name: "attendance", Type: "[Attendance!]!", Description: "Отметки студента, новые первыми",
+				Args: []*graphql.ArgDef{{Name: "subjectId", Type: "Int"}},
+				Resolve: graphql.Load(func(s models.Student) int { return s.StudentID },
+					func(ctx context.Context, ids []int, args graphql.Args) (map[int][]models.AttendanceRecord, error) {
+						subjectID, _ := args.Int("subjectId")
+						records, err := h.repo.GetAttendanceByStudentIDs(ctx, ids, subjectID)
+						return groupByID(records, func(a models.AttendanceRecord) int { return a.StudentID }), err
+					}),
+			},
+		},
+	}
+
+	group := &graphql.Object{
+		Name: "Group",
+		Fields: []*graphql.FieldDef{
+			prop("id", "Int!", func(g models.Group) any { return g.GroupID }),
+			prop("name", "String!", func(g models.Group) any { return g.GroupName }),
+			prop("faculty", "String", func(g models.Group) any { return optionalString(g.Faculty) }),
+			{
+				Name: "students", Type: "[Student!]!",
+				Resolve: graphql.Load(func(g models.Group) int { return g.GroupID },
+					func(ctx context.Context, ids []int, _ graphql.Args) (map[int][]models.Student, error) {
+						students, err := h.repo.GetStudentsByGroupIDs(ctx, ids)
+						return groupByID(students, func(s models.Student) int { return s.GroupID }), err
+					}),
+			},
+			{Name: "schedule", Type: "[Lesson!]!", Resolve: graphql.Load(func(g models.Group) int { return g.GroupID }, groupLessons)},
+		},
+	}
+
+	teacher := &graphql.Object{
+		Name: "Teacher",
+		Fields: []*graphql.FieldDef{
+			prop("id", "Int!", func(t models.Teacher) any { return t.ID }),
+			prop("name", "String", func(t models.Teacher) any { return nullString(t.Name) }),
+			prop("surname", "String", func(t models.Teacher) any { return nullString(t.Surname) }),
+			prop("gender", "String", func(t models.Teacher) any { return nullString(t.Gender) }),
+			prop("subjects", "String", func(t models.Teacher) any { return nullString(t.Subject) }),
+			{
+				Name: "assignments", Type: "[TeacherAssignment!]!", Description: "Назначения; без term — за все семестры",
+				Args: []*graphql.ArgDef{{Name: "term", Type: "String"}},
+				Resolve: graphql.Load(func(t models.Teacher) int { return t.ID },
+					func(ctx context.Context, ids []int, args graphql.Args) (map[int][]models.TeacherAssignment, error) {
+						term, _ := args.String("term")
+						assignments, err := h.repo.GetAssignmentsByTeacherIDs(ctx, ids, term)
+						return groupByID(assignments, func(a models.TeacherAssignment) int { return a.TeacherID }), err
+					}),
+			},
+		},
+	}
+
+	assignment := &graphql.Object{
+		Name: "TeacherAssignment",
+		Fields: []*graphql.FieldDef{
+			prop("id", "Int!", func(a models.TeacherAssignment) any { return a.AssignmentID }),
+			prop("term", "String!", func(a models.TeacherAssignment) any { return a.Term }),
+			prop("role", "String!", func(a models.TeacherAssignment) any { return a.Role }),
+			prop("weeklyHours", "Float!", func(a models.TeacherAssignment) any { return a.WeeklyHours }),
+			{Name: "teacher", Type: "Teacher", Resolve: graphql.Load(func(a models.TeacherAssignment) int { return a.TeacherID }, loadTeachers)},
+			{Name: "subject", Type: "Subject", Resolve: graphql.Load(func(a models.TeacherAssignment) int { return a.SubjectID }, loadSubjects)},
+			{Name: "group", Type: "Group", Resolve: graphql.Load(func(a models.TeacherAssignment) int { return a.GroupID }, loadGroups)},
+		},
+	}
+
+	subject := &graphql.Object{
+		Name: "Subject",
+		Fields: []*graphql.FieldDef{
+			prop("id", "Int!", func(s models.SubjectSummary) any { return s.SubjectID }),
+			prop("name", "String!", func(s models.SubjectSummary) any { return s.SubjectName }),
+			prop("credits", "Int!", func(s models.SubjectSummary) any { return s.Credits }),
+			{
+				Name: "lessons", Type: "[Lesson!]!",
+				Resolve: graphql.Load(func(s models.SubjectSummary) int { return s.SubjectID },
+					func(ctx context.Context, ids []int, _ graphql.Args) (map[int][]models.ScheduleEntry, error) {
+						lessons, err := h.repo.GetScheduleEntriesBySubjectIDs(ctx, ids)
+						return groupByID(lessons, func(l models.ScheduleEntry) int { return l.SubjectID }), err
+					}),
+			},
+		},
+	}
+
+	lesson := &graphql.Object{
+		Name:        "Lesson",
+		Description: "Занятие недельного расписания",
+		Fields: []*graphql.FieldDef{
+			prop("id", "Int!", func(l models.ScheduleEntry) any { return l.ScheduleID }),
+			prop("name", "String!", func(l models.ScheduleEntry) any { return l.LessonName }),
+			prop("dayOfWeek", "Int!", func(l models.ScheduleEntry) any { return l.DayOfWeek }),
+			prop("startTime", "String!", func(l models.ScheduleEntry) any { return l.StartTime }),
+			prop("endTime", "String!", func(l models.ScheduleEntry) any { return l.EndTime }),
+			prop("room", "String", func(l models.ScheduleEntry) any { return l.RoomName }),
+			{Name: "group", Type: "Group", Resolve: graphql.Load(func(l models.ScheduleEntry) int { return l.GroupID }, loadGroups)},
+			{Name: "subject", Type: "Subject", Resolve: graphql.Load(func(l models.ScheduleEntry) int { return l.SubjectID }, loadSubjects)},
+			{
+				Name: "attendance", Type: "[Attendance!]!", Description: "Отметки на занятии; date в формате DD.MM.YYYY",
+				Args: []*graphql.ArgDef{{Name: "date", Type: "String"}},
+				Resolve: graphql.Load(func(l models.ScheduleEntry) int { return l.ScheduleID },
+					func(ctx context.Context, ids []int, args graphql.Args) (map[int][]models.AttendanceRecord, error) {
+						var date *time.Time
+						if value, ok := args.String("date"); ok {
+							normalized, err := normalizeDate(value)
+							if err != nil {
+								return nil, i18n.Errorf("common.invalid_date")
+							}
+							day, _ := time.Parse("02.01.2006", normalized)
+							date = &day
+						}
+						records, err := h.repo.GetAttendanceByScheduleIDs(ctx, ids, date)
+						return groupByID(records, func(a models.AttendanceRecord) int { return a.ScheduleID }), err
+					}),
+			},
+		},
+	}
+
+	attendance := &graphql.Object{
+		Name: "Attendance",
+		Fields: []*graphql.FieldDef{
+			prop("id", "Int!", func(a models.AttendanceRecord) any { return a.AttendanceID }),
+			prop("visitDay", "String!", func(a models.AttendanceRecord) any { return a.VisitDay }),
+			prop("visited", "Boolean!", func(a models.AttendanceRecord) any { return a.Visited }),
+			prop("status", "String!", func(a models.AttendanceRecord) any { return a.Status }),
+			prop("minutesLate", "Int", func(a models.AttendanceRecord) any { return a.MinutesLate }),
+			prop("note", "String", func(a models.AttendanceRecord) any { return a.Note }),
+			{Name: "student", Type: "Student", Resolve: graphql.Load(func(a models.AttendanceRecord) int { return a.StudentID }, loadStudents)},
+			{Name: "lesson", Type: "Lesson", Resolve: graphql.Load(func(a models.AttendanceRecord) int { return a.ScheduleID }, loadLessons)},
+		},
+	}
+
+	load := &graphql.Object{
+		Name: "TeacherLoad",
+		Fields: []*graphql.FieldDef{
+			prop("term", "String!", func(l models.TeacherLoad) any { return l.Term }),
+			prop("assignments", "Int!", func(l models.TeacherLoad) any { return l.Assignments }),
+			prop("weeklyHours", "Float!", func(l models.TeacherLoad) any { return l.WeeklyHours }),
+			prop("maxWeeklyHours", "Float!", func(l models.TeacherLoad) any { return l.MaxWeeklyHours }),
+			prop("overloaded", "Boolean!", func(l models.TeacherLoad) any { return l.Overloaded }),
+			{Name: "teacher", Type: "Teacher", Resolve: graphql.Load(func(l models.TeacherLoad) int { return l.TeacherID }, loadTeachers)},
+		},
+	}
+
+	id := []*graphql.ArgDef{{Name: "id", Type: "Int!"}}
+	query := &graphql.Object{
+		Name: "Query",
+		Fields: []*graphql.FieldDef{
+			{
+				Name: "me", Type: "User!", Description: "Текущий пользователь",
+				Resolve: graphql.Root(func(ctx context.Context, _ graphql.Args) (any, error) {
+					return graphqlUser(ctx), nil
+				}),
+			},
+			{
+				Name: "students", Type: "[Student!]!", Args: []*graphql.ArgDef{{Name: "groupId", Type: "Int"}},
+				Resolve: graphql.Root(func(ctx context.Context, args graphql.Args) (any, error) {
+					if groupID, ok := args.Int("groupId"); ok {
+						return h.repo.GetStudentsByGroupIDs(ctx, []int{groupID})
+					}
+					return h.repo.GetAllStudents(ctx)
+				}),
+			},
+			{
+				Name: "student", Type: "Student", Args: id,
+				Resolve: graphql.Root(func(ctx context.Context, args graphql.Args) (any, error) {
+					studentID, _ := args.Int("id")
+					return first(h.repo.GetStudentsByIDs(ctx, []int{studentID}))
+				}),
+			},
+			{
+				Name: "groups", Type: "[Group!]!",
+				Resolve: graphql.Root(func(ctx context.Context, _ graphql.Args) (any, error) {
+					return h.repo.GetGroups(ctx)
+				}),
+			},
+			{
+				Name: "group", Type: "Group", Args: id,
+				Resolve: graphql.Root(func(ctx context.Context, args graphql.Args) (any, error) {
+					groupID, _ := args.Int("id")
+					return first(h.repo.GetGroupsByIDs(ctx, []int{groupID}))
+				}),
+			},
+			{
+				Name: "teachers", Type: "[Teacher!]!",
+				Resolve: graphql.Root(func(ctx context.Context, _ graphql.Args) (any, error) {
+					return h.repo.GetAllTeachers(ctx)
+				}),
+			},
+			{
+				Name: "teacher", Type: "Teacher", Args: id,
+				Resolve: graphql.Root(func(ctx context.Context, args graphql.Args) (any, error) {
+					teacherID, _ := args.Int("id")
+					return first(h.repo.GetTeachersByIDs(ctx, []int{teacherID}))
+				}),
+			},
+			{
+				Name: "subjects", Type: "[Subject!]!",
+				Resolve: graphql.Root(func(ctx context.Context, _ graphql.Args) (any, error) {
+					return h.repo.GetSubjects(ctx, nil)
+				}),
+			},
+			{
+				Name: "subject", Type: "Subject", Args: id,
+				Resolve: graphql.Root(func(ctx context.Context, args graphql.Args) (any, error) {
+					subjectID, _ := args.Int("id")
+					return first(h.repo.GetSubjects(ctx, []int{subjectID}))
+				}),
+			},
+			{
+				Name: "schedule", Type: "[Lesson!]!", Description: "Недельное расписание группы",
+				Args: []*graphql.ArgDef{{Name: "groupId", Type: "Int!"}},
+				Resolve: graphql.Root(func(ctx context.Context, args graphql.Args) (any, error) {
+					groupID, _ := args.Int("groupId")
+					return h.repo.GetScheduleEntriesByGroupIDs(ctx, []int{groupID})
+				}),
+			},
+			{
+				Name: "lesson", Type: "Lesson", Args: id,
+				Resolve: graphql.Root(func(ctx context.Context, args graphql.Args) (any, error) {
+					lessonID, _ := args.Int("id")
+					return first(h.repo.GetScheduleEntriesByIDs(ctx, []int{lessonID}))
+				}),
+			},
+			{
+				Name: "teacherLoad", Type: "[TeacherLoad!]!", Description: "Нагрузка преподавателей; только для преподавателей и администраторов",
+				Args: []*graphql.ArgDef{{Name: "term", Type: "String", Description: "Семестр вида 2025-fall, по умолчанию текущий"}},
+				Cost: 10,
+				Resolve: h.requireRoles(graphql.Root(func(ctx context.Context, args graphql.Args) (any, error) {
+					term, ok := args.String("term")
+					if !ok {
+						term = terms.For(time.Now())
+					} else if _, _, err := terms.Bounds(term); err != nil {
+						return nil, i18n.Errorf("common.invalid_term")
+					}
+					return h.repo.GetTeachingLoad(ctx, term)
+				}), RoleTeacher, RoleAdmin),
+			},
+		},
+	}
+
+	schema, err := graphql.NewSchema(query, user, student, group, teacher, assignment, subject, lesson, attendance, load)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
@@ -12,8 +12,14 @@ import (
 	"strings"
 	"time"
 
+	"hw_5_jwt/internal/graphql"
+	"hw_5_jwt/internal/i18n"
+	"hw_5_jwt/internal/metrics"
 	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/notify"
 	"hw_5_jwt/internal/postgres"
+	"hw_5_jwt/internal/realtime"
+	"hw_5_jwt/internal/terms"
 
 	"github.com/labstack/echo/v4"
 	"golang.org/x/crypto/bcrypt"
@@ -21,36 +27,157 @@ import (
 
 type Handler struct {
 	repo   *postgres.Repository
+	alerts *notify.Engine
+	hub    *realtime.Hub
+	schema *graphql.Schema
 	logger *slog.Logger
 }
 
-func NewHandler(repo *postgres.Repository, logger *slog.Logger) *Handler {
+func NewHandler(repo *postgres.Repository, alerts *notify.Engine, hub *realtime.Hub, logger *slog.Logger) *Handler {
 	if logger == nil {
 		logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
 	}
-	return &Handler{repo: repo, logger: logger}
+	h := &Handler{repo: repo, alerts: alerts, hub: hub, logger: logger}
+	h.schema = h.newGraphQLSchema()
+	return h
 }
 
 func (h *Handler) RegisterRoutes(e *echo.Echo) {
+	e.Validator = newRequestValidator()
+	e.HTTPErrorHandler = h.HandleHTTPError
+
 	e.GET("/health", h.HealthCheck)
-	e.POST("/api/auth/register", h.Register)
-	e.POST("/api/auth/login", h.Login)
+	e.GET("/openapi.json", h.GetOpenAPI)
+	e.GET("/docs", h.GetDocs)
+
+	// у GraphQL своя эволюция схемы, поэтому эндпоинт один для всех версий REST API
+	e.GET("/graphql", h.GraphQL, h.AuthMiddleware)
+	e.POST("/graphql", h.GraphQL, h.AuthMiddleware)
+	e.GET("/graphql/schema.graphql", h.GetGraphQLSchema)
+
+	h.registerAPI(apiGroup{group: e.Group(legacyPrefix, h.DeprecatedV1)})
+	h.registerAPI(apiGroup{group: e.Group(v1Prefix, h.DeprecatedV1)})
+	h.registerAPI(apiGroup{group: e.Group(v2Prefix), paths: v2Paths})
+}
 
-	protected := e.Group("/api")
-	protected.Use(h.AuthMiddleware)
+// registerAPI регистрирует маршруты одной версии API; пути записаны как в v1.
+func (h *Handler) registerAPI(api apiGroup) {
+	api.POST("/auth/register", h.Register)
+	api.POST("/auth/login", h.Login)
+
+	// календарные приложения не передают Bearer-токен, поэтому *.ics авторизуются секретом в ссылке
+	api.GET("/schedule/group/:id", h.withFeed(h.GetGroupScheduleFeed, h.GetGroupSchedule))
+	api.GET("/schedule/teacher/:id", h.withFeed(h.GetTeacherScheduleFeed, h.GetTeacherSchedule))
+
+	// EventSource и WebSocket в браузере не передают заголовки, токен можно передать в access_token
+	api.GET("/attendance/stream", h.StreamAttendance, tokenFromQuery, h.AuthMiddleware)
+	api.GET("/attendance/ws", h.AttendanceSocket, tokenFromQuery, h.AuthMiddleware)
+
+	protected := api.Group("", h.AuthMiddleware)
 	{
 		protected.GET("/users/me", h.GetCurrentUser)
+		protected.POST("/users", h.CreateUser, h.RequireRole(RoleAdmin))
 		protected.GET("/teachers", h.GetAllTeachers)
-		protected.POST("/teachers/subject", h.SetInfoToTeacher)
+		protected.POST("/teachers/subject", h.SetInfoToTeacher, h.RequireRole(RoleAdmin))
+		protected.GET("/teachers/load", h.GetTeachingLoad, h.RequireRole(RoleTeacher, RoleAdmin))
+		protected.GET("/teachers/:id/assignments", h.GetTeacherAssignments)
+		protected.POST("/teachers/:id/assignments", h.AssignTeacher, h.RequireRole(RoleAdmin))
+		protected.PUT("/teachers/:id/max-load", h.SetTeacherMaxLoad, h.RequireRole(RoleAdmin))
+		protected.DELETE("/teachers/assignments/:id", h.DeleteTeacherAssignment, h.RequireRole(RoleAdmin))
+		protected.GET("/teachers/:id/availability", h.GetTeacherAvailability, h.RequireRole(RoleTeacher, RoleAdmin))
+		protected.PUT("/teachers/:id/availability", h.SetTeacherAvailability, h.RequireRole(RoleAdmin))
 		protected.GET("/students", h.GetAllStudents)
 		protected.GET("/students/:id", h.GetStudent)
+		protected.PUT("/students/:id/group", h.SetStudentGroup, h.RequireRole(RoleAdmin))
 		protected.GET("/schedule", h.GetAllSchedule)
-		protected.GET("/schedule/group/:id", h.GetGroupSchedule)
+		protected.PUT("/users/me/language", h.SetMyLanguage)
+		protected.POST("/users/me/calendar-token", h.CreateCalendarToken)
+		protected.GET("/holidays", h.GetHolidays)
+		protected.POST("/holidays", h.CreateHoliday, h.RequireRole(RoleAdmin))
+		protected.DELETE("/holidays/:date", h.DeleteHoliday, h.RequireRole(RoleAdmin))
+		protected.POST("/schedule", h.CreateScheduleEntry, h.RequireRole(RoleAdmin))
+		protected.PUT("/schedule/:id/room", h.SetScheduleRoom, h.RequireRole(RoleAdmin))
+		protected.GET("/rooms", h.GetRooms)
+		protected.POST("/rooms", h.CreateRoom, h.RequireRole(RoleAdmin))
+		protected.GET("/rooms/free", h.FindFreeRooms)
+		protected.GET("/rooms/occupancy", h.GetRoomOccupancy, h.RequireRole(RoleAdmin))
+		protected.GET("/rooms/:id/schedule", h.GetRoomSchedule)
 		protected.GET("/groups", h.GetAllGroups)
 		protected.GET("/groups/:id", h.GetGroup)
-		protected.POST("/attendance/subject", h.CreateAttendance)
+		protected.POST("/attendance/subject", h.CreateAttendance, h.RequireRole(RoleTeacher, RoleAdmin))
+		protected.GET("/attendance/roster/:id", h.GetLessonRoster, h.RequireRole(RoleTeacher, RoleAdmin))
+		protected.POST("/attendance/bulk", h.CreateAttendanceBulk, h.RequireRole(RoleTeacher, RoleAdmin))
+		protected.GET("/attendance/export", h.ExportAttendance)
 		protected.GET("/attendanceBySubjectId/:id", h.GetAttendanceBySubjectID)
 		protected.GET("/attendanceByStudentId/:id", h.GetAttendanceByStudentID)
+		protected.POST("/checkin/windows", h.OpenCheckinWindow, h.RequireRole(RoleTeacher, RoleAdmin))
+		protected.GET("/checkin/windows/:id/code", h.GetCheckinCode, h.RequireRole(RoleTeacher, RoleAdmin))
+		protected.POST("/checkin/windows/:id/close", h.CloseCheckinWindow, h.RequireRole(RoleTeacher, RoleAdmin))
+		protected.POST("/checkin", h.SubmitCheckin, h.RequireRole(RoleStudent))
+		protected.POST("/excuses", h.CreateExcuse, h.RequireRole(RoleStudent))
+		protected.GET("/excuses", h.GetExcuses)
+		protected.GET("/excuses/:id", h.GetExcuse)
+		protected.GET("/excuses/:id/document", h.GetExcuseDocument)
+		protected.POST("/excuses/:id/approve", h.ApproveExcuse, h.RequireRole(RoleTeacher, RoleAdmin))
+		protected.POST("/excuses/:id/reject", h.RejectExcuse, h.RequireRole(RoleTeacher, RoleAdmin))
+
+		stats := protected.Group("/stats", h.RequireRole(RoleTeacher, RoleAdmin))
+		stats.GET("/students", h.GetStudentStats)
+		stats.GET("/subjects", h.GetSubjectStats)
+		stats.GET("/groups", h.GetGroupStats)
+		stats.GET("/faculties", h.GetFacultyStats)
+		stats.GET("/weekly", h.GetWeeklyStats)
+		stats.GET("/at-risk", h.GetAtRiskStudents)
+
+		protected.GET("/notifications", h.GetNotifications)
+		protected.POST("/notifications/:id/read", h.MarkNotificationRead)
+		protected.POST("/notifications/read-all", h.MarkAllNotificationsRead)
+
+		alerts := protected.Group("/alerts", h.RequireRole(RoleAdmin))
+		alerts.GET("/rules", h.GetAlertRules)
+		alerts.POST("/rules", h.CreateAlertRule)
+		alerts.DELETE("/rules/:id", h.DeactivateAlertRule)
+		alerts.POST("/evaluate", h.EvaluateAlerts)
+		protected.PUT("/groups/:id/curator", h.SetGroupCurator, h.RequireRole(RoleAdmin))
+
+		protected.GET("/offerings", h.GetOfferings)
+		protected.POST("/offerings", h.CreateOffering, h.RequireRole(RoleAdmin))
+		protected.GET("/offerings/:id/enrollments", h.GetOfferingEnrollments, h.RequireRole(RoleTeacher, RoleAdmin))
+		protected.POST("/offerings/:id/enroll", h.Enroll, h.RequireRole(RoleStudent, RoleAdmin))
+		protected.POST("/offerings/:id/drop", h.Drop, h.RequireRole(RoleStudent, RoleAdmin))
+		protected.POST("/offerings/:id/enroll-group", h.EnrollGroup, h.RequireRole(RoleAdmin))
+		protected.GET("/enrollments/me", h.GetMyEnrollments, h.RequireRole(RoleStudent))
+
+		assessments := protected.Group("/assessments", h.RequireRole(RoleTeacher, RoleAdmin))
+		assessments.GET("", h.GetAssessments)
+		assessments.POST("", h.CreateAssessment)
+		assessments.DELETE("/:id", h.DeleteAssessment)
+		assessments.GET("/:id/grades", h.GetAssessmentGrades)
+		assessments.POST("/:id/grades", h.SetGradesBulk)
+		assessments.PUT("/:id/grades/:student_id", h.SetGrade)
+		protected.GET("/grades/me", h.GetMyGrades, h.RequireRole(RoleStudent))
+		protected.GET("/grades/final", h.GetFinalGrades, h.RequireRole(RoleTeacher, RoleAdmin))
+		protected.GET("/students/:id/grades", h.GetStudentGrades, h.RequireRole(RoleTeacher, RoleAdmin))
+		protected.GET("/students/:id/transcript", h.GetTranscript)
+		protected.PUT("/subjects/:id/credits", h.SetSubjectCredits, h.RequireRole(RoleAdmin))
+		protected.GET("/grading-scale", h.GetGradingScale)
+		protected.PUT("/grading-scale", h.SetGradingScale, h.RequireRole(RoleAdmin))
+
+		protected.POST("/import/:kind", h.Import, h.RequireRole(RoleAdmin))
+
+		drafts := protected.Group("/timetable/drafts", h.RequireRole(RoleAdmin))
+		drafts.GET("", h.GetTimetableDrafts)
+		drafts.POST("", h.GenerateTimetable)
+		drafts.GET("/:id", h.GetTimetableDraft)
+		drafts.POST("/:id/publish", h.PublishTimetableDraft)
+		drafts.DELETE("/:id", h.DiscardTimetableDraft)
+
+		webhooks := protected.Group("/webhooks", h.RequireRole(RoleAdmin))
+		webhooks.GET("", h.GetWebhookSubscriptions)
+		webhooks.POST("", h.CreateWebhookSubscription)
+		webhooks.DELETE("/:id", h.DeactivateWebhookSubscription)
+		webhooks.GET("/:id/deliveries", h.GetWebhookDeliveries)
+		webhooks.POST("/deliveries/:id/redeliver", h.RedeliverWebhook)
 	}
 }
 
@@ -58,28 +185,19 @@ func (h *Handler) AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		authHeader := c.Request().Header.Get("Authorization")
 		if authHeader == "" {
-			return c.JSON(http.StatusUnauthorized, models.ServerResponse{
-				Status:  "error",
-				Message: "Authorization header required",
-			})
+			return h.fail(c, http.StatusUnauthorized, "auth.header_required")
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			return c.JSON(http.StatusUnauthorized, models.ServerResponse{
-				Status:  "error",
-				Message: "Invalid authorization format. Use Bearer <token>",
-			})
+			return h.fail(c, http.StatusUnauthorized, "auth.invalid_format")
 		}
 
 		tokenString := parts[1]
 		claims, err := ValidateToken(tokenString)
 		if err != nil {
 			h.logger.Warn("невалидный токен", "error", err)
-			return c.JSON(http.StatusUnauthorized, models.ServerResponse{
-				Status:  "error",
-				Message: "Invalid or expired token",
-			})
+			return h.fail(c, http.StatusUnauthorized, "auth.invalid_token")
 		}
 
 		c.Set("userID", claims.UserID)
@@ -90,49 +208,75 @@ func (h *Handler) AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 func (h *Handler) Register(c echo.Context) error {
 	var req models.RegisterRequest
 
-	if err := c.Bind(&req); err != nil {
-		h.logger.Warn("ошибка валидации регистрации", "error", err)
-		return c.JSON(http.StatusBadRequest, models.ServerResponse{
-			Status:  "error",
-			Message: "Неверные данные",
-			Error:   err.Error(),
-		})
+	if ok, err := h.bind(c, &req); !ok {
+		return err
 	}
-	if req.Role != "" {
-		validRoles := map[string]bool{"student": true, "teacher": true, "admin": true}
-		if !validRoles[req.Role] {
-			return c.JSON(http.StatusBadRequest, models.ServerResponse{
-				Status:  "error",
-				Message: "Недопустимая роль. Допустимые значения: student, teacher, admin",
-			})
-		}
-	} else {
-		req.Role = "student"
+	req.Role = RoleStudent
+
+	createdUser, err := h.createAccount(c, models.CreateUserRequest(req))
+	if createdUser == nil {
+		return err
+	}
+
+	token, err := GenerateToken(createdUser.ID)
+	if err != nil {
+		h.logger.Error("ошибка генерации токена", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "auth.token_failed")
+	}
+
+	// ответ уже на языке нового профиля
+	c.Set("userID", createdUser.ID)
+	c.Set("user", createdUser)
+
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "auth.registered"),
+		Data: map[string]interface{}{
+			"token": token,
+			"user":  createdUser,
+		},
+	})
+}
+
+// CreateUser — создание учётной записи администратором, в том числе учителя или
+// администратора, которых нельзя зарегистрировать самостоятельно.
+func (h *Handler) CreateUser(c echo.Context) error {
+	var req models.CreateUserRequest
+
+	if ok, err := h.bind(c, &req); !ok {
+		return err
 	}
 
+	createdUser, err := h.createAccount(c, req)
+	if createdUser == nil {
+		return err
+	}
+
+	h.logger.Info("администратор создал пользователя", "user_id", createdUser.ID, "role", createdUser.Role)
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "users.created"),
+		Data:    createdUser,
+	})
+}
+
+// createAccount создаёт пользователя, а для роли teacher — и запись учителя.
+// При ошибке отвечает клиенту и возвращает nil.
+func (h *Handler) createAccount(c echo.Context, req models.CreateUserRequest) (*models.User, error) {
 	existingUser, err := h.repo.GetUserByEmail(c.Request().Context(), req.Email)
 	if err != nil {
 		h.logger.Error("ошибка при проверке пользователя", "error", err)
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Ошибка сервера",
-		})
+		return nil, h.fail(c, http.StatusInternalServerError, "common.server_error")
 	}
 
 	if existingUser != nil {
-		return c.JSON(http.StatusBadRequest, models.ServerResponse{
-			Status:  "error",
-			Message: "Пользователь с таким email уже существует",
-		})
+		return nil, h.fail(c, http.StatusBadRequest, "auth.email_taken")
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		h.logger.Error("ошибка хеширования пароля", "error", err)
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Ошибка при обработке пароля",
-		})
+		return nil, h.fail(c, http.StatusInternalServerError, "auth.password_failed")
 	}
 
 	user := &models.User{
@@ -142,16 +286,13 @@ func (h *Handler) Register(c echo.Context) error {
 		Status:   sql.NullString{String: "active", Valid: true},
 		Name:     sql.NullString{String: req.Name, Valid: req.Name != ""},
 		Surname:  sql.NullString{String: req.Surname, Valid: req.Surname != ""},
+		Language: req.Language,
 	}
 
 	createdUser, err := h.repo.CreateUser(c.Request().Context(), user)
 	if err != nil {
 		h.logger.Error("ошибка создания пользователя", "error", err)
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Не удалось создать пользователя",
-			Error:   err.Error(),
-		})
+		return nil, h.fail(c, http.StatusInternalServerError, "auth.user_create_failed")
 	}
 
 	if createdUser.Role == "teacher" {
@@ -169,11 +310,7 @@ func (h *Handler) Register(c echo.Context) error {
 		err := h.repo.CreateTeacher(c.Request().Context(), teacher)
 		if err != nil {
 			h.logger.Error("ошибка создания учителя", "error", err)
-			return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-				Status:  "error",
-				Message: "Не удалось создать учителя",
-				Error:   err.Error(),
-			})
+			return nil, h.fail(c, http.StatusInternalServerError, "auth.teacher_create_failed")
 		}
 		h.logger.Info("учитель создан",
 			"user_id", user.ID,
@@ -181,79 +318,50 @@ func (h *Handler) Register(c echo.Context) error {
 		)
 	}
 
-	token, err := GenerateToken(createdUser.ID)
-	if err != nil {
-		h.logger.Error("ошибка генерации токена", "error", err)
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Не удалось создать токен",
-		})
-	}
-
 	createdUser.Password = ""
-
-	return c.JSON(http.StatusCreated, models.ServerResponse{
-		Status:  "success",
-		Message: "Пользователь успешно зарегистрирован",
-		Data: map[string]interface{}{
-			"token": token,
-			"user":  createdUser,
-		},
-	})
+	return createdUser, nil
 }
 
 func (h *Handler) Login(c echo.Context) error {
 	var req models.LoginRequest
 
-	if err := c.Bind(&req); err != nil {
-		h.logger.Warn("ошибка валидации входа", "error", err)
-		return c.JSON(http.StatusBadRequest, models.ServerResponse{
-			Status:  "error",
-			Message: "Неверные данные",
-			Error:   err.Error(),
-		})
+	if ok, err := h.bind(c, &req); !ok {
+		return err
 	}
 
 	user, err := h.repo.GetUserByEmail(c.Request().Context(), req.Email)
 	if err != nil {
 		h.logger.Error("ошибка при получении пользователя", "error", err)
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Ошибка сервера",
-		})
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
 	}
 
 	if user == nil {
 		h.logger.Warn("пользователь не найден", "email", req.Email)
-		return c.JSON(http.StatusUnauthorized, models.ServerResponse{
-			Status:  "error",
-			Message: "Неверный email или пароль",
-		})
+		metrics.Login(false)
+		return h.fail(c, http.StatusUnauthorized, "auth.invalid_credentials")
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
 		h.logger.Warn("неверный пароль", "email", req.Email)
-		return c.JSON(http.StatusUnauthorized, models.ServerResponse{
-			Status:  "error",
-			Message: "Неверный email или пароль",
-		})
+		metrics.Login(false)
+		return h.fail(c, http.StatusUnauthorized, "auth.invalid_credentials")
 	}
 
 	token, err := GenerateToken(user.ID)
 	if err != nil {
 		h.logger.Error("ошибка генерации токена", "error", err)
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Не удалось создать токен",
-		})
+		return h.fail(c, http.StatusInternalServerError, "auth.token_failed")
 	}
 
+	metrics.Login(true)
 	user.Password = ""
+	c.Set("userID", user.ID)
+	c.Set("user", user)
 
 	return c.JSON(http.StatusOK, models.ServerResponse{
 		Status:  "success",
-		Message: "Успешный вход",
+		Message: h.tr(c, "auth.logged_in"),
 		Data: map[string]interface{}{
 			"token": token,
 			"user":  user,
@@ -261,23 +369,33 @@ func (h *Handler) Login(c echo.Context) error {
 	})
 }
 
+// SetInfoToTeacher назначает предмет преподавателю: в v1 teacher_id передаётся в теле,
+// в v2 (POST /teachers/:id/subjects) — в пути.
 func (h *Handler) SetInfoToTeacher(c echo.Context) error {
-	var req models.SetInfoToTeacher
+	pathID := 0
+	if idStr := c.Param("id"); idStr != "" {
+		id, err := strconv.Atoi(idStr)
+		if err != nil || id <= 0 {
+			h.logger.Warn("неверный формат ID учителя", "id", idStr)
+			return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+		}
+		pathID = id
+	}
 
-	if err := c.Bind(&req); err != nil {
-		h.logger.Warn("ошибка привязки данных для назначения предмета учителю",
-			"error", err)
-		return c.JSON(http.StatusBadRequest, models.ServerResponse{
-			Status:  "error",
-			Message: "Неверный формат данных",
-		})
+	// teacher_id из пути (тег param) тело не переопределяет
+	var req models.SetInfoToTeacher
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+	if pathID > 0 {
+		req.TeacherID = pathID
 	}
 	h.logger.Info("назначение предмета учителю",
 		"teacher_id", req.TeacherID,
 		"subject_id", req.SubjectID,
 	)
 
-	err := h.repo.SetInfoToTeacher(c.Request().Context(), req.TeacherID, req.SubjectID)
+	assignments, err := h.repo.SetInfoToTeacher(c.Request().Context(), req.TeacherID, req.SubjectID, terms.For(time.Now()))
 	if err != nil {
 		h.logger.Error("ошибка назначения предмета учителю",
 			"error", err,
@@ -286,17 +404,14 @@ func (h *Handler) SetInfoToTeacher(c echo.Context) error {
 		)
 
 		if strings.Contains(err.Error(), "не найден") {
-			return c.JSON(http.StatusNotFound, models.ServerResponse{
-				Status:  "error",
-				Message: "Учитель не найден",
-			})
+			return h.fail(c, http.StatusNotFound, "teachers.subject_not_found")
 		}
 
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Не удалось назначить предмет учителю",
-			Error:   err.Error(),
-		})
+		if strings.Contains(err.Error(), "превышена") {
+			return h.fail(c, http.StatusConflict, "teachers.overloaded")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "teachers.subject_assign_failed")
 	}
 
 	h.logger.Info("предмет успешно назначен учителю",
@@ -306,7 +421,8 @@ func (h *Handler) SetInfoToTeacher(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, models.ServerResponse{
 		Status:  "success",
-		Message: "Предмет успешно назначен учителю",
+		Message: h.tr(c, "teachers.subject_assigned"),
+		Data:    assignments,
 	})
 }
 
@@ -314,26 +430,17 @@ func (h *Handler) GetCurrentUser(c echo.Context) error {
 
 	userID := c.Get("userID")
 	if userID == nil {
-		return c.JSON(http.StatusUnauthorized, models.ServerResponse{
-			Status:  "error",
-			Message: "Пользователь не аутентифицирован",
-		})
+		return h.fail(c, http.StatusUnauthorized, "auth.unauthenticated")
 	}
 
 	user, err := h.repo.GetUserByID(c.Request().Context(), userID.(int))
 	if err != nil {
 		h.logger.Error("ошибка получения пользователя", "error", err)
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Ошибка сервера",
-		})
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
 	}
 
 	if user == nil {
-		return c.JSON(http.StatusNotFound, models.ServerResponse{
-			Status:  "error",
-			Message: "Пользователь не найден",
-		})
+		return h.fail(c, http.StatusNotFound, "users.not_found")
 	}
 
 	user.Password = ""
@@ -347,65 +454,59 @@ func (h *Handler) GetCurrentUser(c echo.Context) error {
 func (h *Handler) CreateAttendance(c echo.Context) error {
 	var req models.AttendanceRequest
 
-	if err := c.Bind(&req); err != nil {
-		h.logger.Warn("ошибка привязки данных", "error", err)
-		return c.JSON(http.StatusBadRequest, models.ServerResponse{
-			Status:  "error",
-			Message: "Неверный формат данных",
-		})
+	if ok, err := h.bind(c, &req); !ok {
+		return err
 	}
 
-	if req.ScheduleID == 0 {
-		return c.JSON(http.StatusBadRequest, models.ServerResponse{
-			Status:  "error",
-			Message: "schedule_id обязателен",
-		})
-	}
+	// формат даты уже проверен валидатором
+	req.VisitDay, _ = normalizeDate(req.VisitDay)
 
-	if req.VisitDay == "" {
-		return c.JSON(http.StatusBadRequest, models.ServerResponse{
-			Status:  "error",
-			Message: "visit_day обязателен",
-		})
+	// студенты отмечаются только кодом или через уважительную причину
+	if ok, err := h.requireLessonTeacher(c, req.ScheduleID, req.VisitDay); !ok {
+		return err
 	}
 
-	if req.StudentID == 0 {
-		return c.JSON(http.StatusBadRequest, models.ServerResponse{
-			Status:  "error",
-			Message: "student_id обязателен",
-		})
-	}
+	var err error
 
-	normalizedDate, err := normalizeDate(req.VisitDay)
+	req.Status, req.Visited, err = resolveAttendanceStatus(req.Status, req.Visited, req.MinutesLate)
 	if err != nil {
-		h.logger.Warn("неверный формат даты", "date", req.VisitDay, "error", err)
-		return c.JSON(http.StatusBadRequest, models.ServerResponse{
-			Status:  "error",
-			Message: "Неверный формат даты. Используйте формат DD.MM.YYYY",
-		})
+		return h.failErr(c, http.StatusBadRequest, err)
 	}
 
-	req.VisitDay = normalizedDate
+	visitDate, _ := time.Parse("02.01.2006", req.VisitDay)
+	enrolled, err := h.repo.IsEnrolledInLesson(c.Request().Context(), req.StudentID, req.ScheduleID, visitDate)
+	if err != nil {
+		h.logger.Error("ошибка проверки записи на предмет", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "attendance.enrollment_check_failed")
+	}
+	if !enrolled {
+		h.logger.Warn("студент не записан на предмет",
+			"student_id", req.StudentID,
+			"schedule_id", req.ScheduleID,
+			"visit_day", req.VisitDay,
+		)
+		return h.fail(c, http.StatusUnprocessableEntity, "attendance.not_enrolled")
+	}
 
 	h.logger.Info("создание записи посещаемости",
 		"schedule_id", req.ScheduleID,
 		"student_id", req.StudentID,
 		"visit_day", req.VisitDay,
 		"visited", req.Visited,
+		"status", req.Status,
 	)
 
 	if err := h.repo.CreateAttendance(c.Request().Context(), req); err != nil {
 		h.logger.Error("ошибка создания посещаемости", "error", err.Error()) // ← Добавь .Error()
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Ошибка создания записи посещаемости: " + err.Error(), // ← Добавь err.Error()
-		})
+		return h.fail(c, http.StatusInternalServerError, "attendance.create_failed")
 	}
 
 	h.logger.Info("запись посещаемости успешно создана")
+	metrics.AttendanceWritten("mark", 1)
+	h.alerts.EvaluateAsync(req.StudentID)
 	return c.JSON(http.StatusCreated, models.ServerResponse{
 		Status:  "success",
-		Message: "Запись посещаемости успешно создана",
+		Message: h.tr(c, "attendance.created"),
 	})
 }
 
@@ -432,16 +533,44 @@ func normalizeDate(dateStr string) (string, error) {
 	return "", fmt.Errorf("неподдерживаемый формат даты")
 }
 
+// resolveAttendanceStatus сводит новый статус и старое поле visited к одному виду:
+// если статус не передан, он выводится из visited, иначе visited вычисляется из статуса.
+func resolveAttendanceStatus(status string, visited bool, minutesLate *int) (string, bool, error) {
+	if status == "" {
+		status = models.AttendanceAbsent
+		if visited {
+			status = models.AttendancePresent
+		}
+	}
+
+	switch status {
+	case models.AttendancePresent, models.AttendanceLate, models.AttendanceRemote:
+		visited = true
+	case models.AttendanceAbsent, models.AttendanceExcused:
+		visited = false
+	default:
+		return "", false, i18n.Errorf("attendance.invalid_status")
+	}
+
+	if minutesLate != nil {
+		if status != models.AttendanceLate {
+			return "", false, i18n.Errorf("attendance.minutes_late_only_late")
+		}
+		if *minutesLate < 0 {
+			return "", false, i18n.Errorf("attendance.minutes_late_negative")
+		}
+	}
+
+	return status, visited, nil
+}
+
 func (h *Handler) GetAttendanceBySubjectID(c echo.Context) error {
 	idStr := c.Param("id")
 
 	subjectID, err := strconv.Atoi(idStr)
 	if err != nil || subjectID <= 0 {
 		h.logger.Warn("неверный формат ID предмета", "id", idStr)
-		return c.JSON(http.StatusBadRequest, models.ServerResponse{
-			Status:  "error",
-			Message: "Неверный формат ID предмета",
-		})
+		return h.fail(c, http.StatusBadRequest, "common.invalid_subject_id")
 	}
 
 	h.logger.Info("получение посещаемости по предмету", "subject_id", subjectID)
@@ -449,17 +578,14 @@ func (h *Handler) GetAttendanceBySubjectID(c echo.Context) error {
 	attendances, err := h.repo.GetAttendanceBySubjectID(c.Request().Context(), subjectID)
 	if err != nil {
 		h.logger.Error("ошибка получения посещаемости", "subject_id", subjectID, "error", err)
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Ошибка получения посещаемости",
-		})
+		return h.fail(c, http.StatusInternalServerError, "attendance.get_failed")
 	}
 
 	if len(attendances) == 0 {
 		h.logger.Info("посещаемость не найдена", "subject_id", subjectID)
 		return c.JSON(http.StatusOK, models.ServerResponse{
 			Status:  "success",
-			Message: "Посещаемость по данному предмету не найдена",
+			Message: h.tr(c, "attendance.subject_empty"),
 			Data:    []models.AttendanceBySubject{},
 		})
 	}
@@ -477,10 +603,7 @@ func (h *Handler) GetAttendanceByStudentID(c echo.Context) error {
 	studentID, err := strconv.Atoi(idStr)
 	if err != nil || studentID <= 0 {
 		h.logger.Warn("неверный формат ID студента", "id", idStr)
-		return c.JSON(http.StatusBadRequest, models.ServerResponse{
-			Status:  "error",
-			Message: "Неверный формат ID студента",
-		})
+		return h.fail(c, http.StatusBadRequest, "common.invalid_student_id")
 	}
 
 	h.logger.Info("получение посещаемости по студенту", "student_id", studentID)
@@ -488,17 +611,14 @@ func (h *Handler) GetAttendanceByStudentID(c echo.Context) error {
 	attendances, err := h.repo.GetAttendanceByStudentID(c.Request().Context(), studentID)
 	if err != nil {
 		h.logger.Error("ошибка получения посещаемости", "student_id", studentID, "error", err)
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Ошибка получения посещаемости",
-		})
+		return h.fail(c, http.StatusInternalServerError, "attendance.get_failed")
 	}
 
 	if len(attendances) == 0 {
 		h.logger.Info("посещаемость не найдена", "student_id", studentID)
 		return c.JSON(http.StatusOK, models.ServerResponse{
 			Status:  "success",
-			Message: "Посещаемость данного студента не найдена",
+			Message: h.tr(c, "attendance.student_empty"),
 			Data:    []models.AttendanceByStudent{},
 		})
 	}
@@ -523,10 +643,7 @@ func (h *Handler) GetStudent(c echo.Context) error {
 	studentID, err := strconv.Atoi(idStr)
 	if err != nil || studentID <= 0 {
 		h.logger.Warn("неверный формат ID студента", "id", idStr)
-		return c.JSON(http.StatusBadRequest, models.ServerResponse{
-			Status:  "error",
-			Message: "Неверный формат ID",
-		})
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
 	}
 
 	h.logger.Info("получение студента", "id", studentID)
@@ -536,16 +653,10 @@ func (h *Handler) GetStudent(c echo.Context) error {
 		h.logger.Error("ошибка получения студента", "id", studentID, "error", err)
 
 		if err.Error() == "студент с ID %d не найден" {
-			return c.JSON(http.StatusNotFound, models.ServerResponse{
-				Status:  "error",
-				Message: "Студент не найден",
-			})
+			return h.fail(c, http.StatusNotFound, "students.not_found")
 		}
 
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Внутренняя ошибка сервера",
-		})
+		return h.fail(c, http.StatusInternalServerError, "common.internal_error")
 	}
 
 	h.logger.Info("студент успешно получен", "id", studentID)
@@ -555,16 +666,51 @@ func (h *Handler) GetStudent(c echo.Context) error {
 	})
 }
 
+// SetStudentGroup переводит студента в группу: так карточка, созданная при регистрации,
+// получает группу, а с ней запись на предметы, отметки и поток посещаемости.
+func (h *Handler) SetStudentGroup(c echo.Context) error {
+	idStr := c.Param("id")
+
+	studentID, err := strconv.Atoi(idStr)
+	if err != nil || studentID <= 0 {
+		h.logger.Warn("неверный формат ID студента", "id", idStr)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	var req models.StudentGroupRequest
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	student, err := h.repo.SetStudentGroup(c.Request().Context(), studentID, req.GroupID)
+	if err != nil {
+		h.logger.Error("ошибка перевода студента", "student_id", studentID, "group_id", req.GroupID, "error", err)
+
+		switch {
+		case strings.HasPrefix(err.Error(), "группа с ID"):
+			return h.fail(c, http.StatusNotFound, "groups.not_found")
+		case strings.HasPrefix(err.Error(), "студент с ID"):
+			return h.fail(c, http.StatusNotFound, "students.not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "students.group_failed")
+	}
+
+	h.logger.Info("студент переведён в группу", "student_id", studentID, "group_id", req.GroupID)
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "students.group_set"),
+		Data:    student,
+	})
+}
+
 func (h *Handler) GetAllStudents(c echo.Context) error {
 	h.logger.Info("получение всех студентов")
 
 	students, err := h.repo.GetAllStudents(c.Request().Context())
 	if err != nil {
 		h.logger.Error("ошибка получения студентов", "error", err)
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Ошибка получения студентов",
-		})
+		return h.fail(c, http.StatusInternalServerError, "students.list_failed")
 	}
 
 	h.logger.Info("студенты успешно получены", "count", len(students))
@@ -580,10 +726,7 @@ func (h *Handler) GetAllTeachers(c echo.Context) error {
 	teachers, err := h.repo.GetAllTeachers(c.Request().Context())
 	if err != nil {
 		h.logger.Error("ошибка получения учителей", "error", err)
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Ошибка получения учителей",
-		})
+		return h.fail(c, http.StatusInternalServerError, "teachers.list_failed")
 	}
 
 	h.logger.Info("учителя успешно получены", "count", len(teachers))
@@ -621,10 +764,7 @@ func (h *Handler) GetGroupSchedule(c echo.Context) error {
 	groupID, err := strconv.Atoi(idStr)
 	if err != nil || groupID <= 0 {
 		h.logger.Warn("неверный формат ID группы", "id", idStr)
-		return c.JSON(http.StatusBadRequest, models.ServerResponse{
-			Status:  "error",
-			Message: "Неверный формат ID группы",
-		})
+		return h.fail(c, http.StatusBadRequest, "common.invalid_group_id")
 	}
 
 	h.logger.Info("получение расписания группы", "group_id", groupID)
@@ -632,10 +772,7 @@ func (h *Handler) GetGroupSchedule(c echo.Context) error {
 	schedule, err := h.repo.GetGroupSchedule(c.Request().Context(), groupID)
 	if err != nil {
 		h.logger.Error("ошибка получения расписания группы", "group_id", groupID, "error", err)
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Ошибка получения расписания",
-		})
+		return h.fail(c, http.StatusInternalServerError, "schedule.get_failed")
 	}
 
 	h.logger.Info("расписание группы успешно получено",
@@ -644,7 +781,7 @@ func (h *Handler) GetGroupSchedule(c echo.Context) error {
 	if len(schedule) == 0 {
 		return c.JSON(http.StatusOK, models.ServerResponse{
 			Status:  "success",
-			Message: "Расписание для группы пустое",
+			Message: h.tr(c, "schedule.group_empty"),
 			Data:    []models.Schedule{},
 		})
 	}
@@ -661,10 +798,7 @@ func (h *Handler) GetAllGroups(c echo.Context) error {
 	groups, err := h.repo.GetGroups(c.Request().Context())
 	if err != nil {
 		h.logger.Error("ошибка получения групп", "error", err)
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Ошибка получения групп",
-		})
+		return h.fail(c, http.StatusInternalServerError, "groups.list_failed")
 	}
 
 	h.logger.Info("группы успешно получены", "count", len(groups))
@@ -680,10 +814,7 @@ func (h *Handler) GetGroup(c echo.Context) error {
 	groupID, err := strconv.Atoi(idStr)
 	if err != nil || groupID <= 0 {
 		h.logger.Warn("неверный формат ID группы", "id", idStr)
-		return c.JSON(http.StatusBadRequest, models.ServerResponse{
-			Status:  "error",
-			Message: "Неверный формат ID",
-		})
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
 	}
 
 	h.logger.Info("получение группы", "id", groupID)
@@ -693,16 +824,10 @@ func (h *Handler) GetGroup(c echo.Context) error {
 		h.logger.Error("ошибка получения группы", "id", groupID, "error", err)
 
 		if err.Error() == "группа с ID %d не найдена" {
-			return c.JSON(http.StatusNotFound, models.ServerResponse{
-				Status:  "error",
-				Message: "Группа не найдена",
-			})
+			return h.fail(c, http.StatusNotFound, "groups.not_found")
 		}
 
-		return c.JSON(http.StatusInternalServerError, models.ServerResponse{
-			Status:  "error",
-			Message: "Внутренняя ошибка сервера",
-		})
+		return h.fail(c, http.StatusInternalServerError, "common.internal_error")
 	}
 
 	h.logger.Info("группа успешно получена", "id", groupID)
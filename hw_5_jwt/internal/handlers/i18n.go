@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+
+	"hw_5_jwt/internal/i18n"
+	"hw_5_jwt/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// lang определяет язык ответа: язык из профиля пользователя важнее Accept-Language.
+// Пользователь известен только после AuthMiddleware, поэтому язык вычисляется при
+// первом сообщении и кэшируется в контексте.
+func (h *Handler) lang(c echo.Context) string {
+	if lang, ok := c.Get("lang").(string); ok {
+		return lang
+	}
+
+	lang := ""
+	if _, ok := c.Get("userID").(int); ok {
+		// ошибка получения профиля не должна ломать ответ — язык возьмём из заголовка
+		if user, err := h.currentUser(c); err == nil && user != nil && i18n.Supported(user.Language) {
+			lang = user.Language
+		}
+	}
+	if lang == "" {
+		lang = i18n.Negotiate(c.Request().Header.Get("Accept-Language"))
+	}
+	if lang == "" {
+		lang = i18n.Default
+	}
+
+	c.Set("lang", lang)
+	header := c.Response().Header()
+	header.Set("Content-Language", lang)
+	header.Add("Vary", "Accept-Language")
+	return lang
+}
+
+// tr переводит сообщение id на язык запроса.
+func (h *Handler) tr(c echo.Context, id string, args ...any) string {
+	return i18n.T(h.lang(c), id, args...)
+}
+
+func (h *Handler) SetMyLanguage(c echo.Context) error {
+	var req models.LanguageRequest
+
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	userID, _ := c.Get("userID").(int)
+	if err := h.repo.SetUserLanguage(c.Request().Context(), userID, req.Language); err != nil {
+		h.logger.Error("ошибка сохранения языка", "user_id", userID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "users.language_update_failed")
+	}
+
+	// ответ уже на новом языке
+	c.Set("lang", req.Language)
+	c.Response().Header().Set("Content-Language", req.Language)
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "users.language_updated"),
+		Data:    req,
+	})
+}
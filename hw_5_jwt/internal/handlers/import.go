@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"hw_5_jwt/internal/importer"
+	"hw_5_jwt/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+const maxImportSize = 10 << 20
+
+// Import: POST /import/:kind (groups, students, schedule), multipart-форма:
+// file — CSV или XLSX, mapping — JSON {"поле": "заголовок колонки"}, dry_run — только проверка.
+func (h *Handler) Import(c echo.Context) error {
+	kind := c.Param("kind")
+	if _, ok := importer.Fields[kind]; !ok {
+		return h.fail(c, http.StatusBadRequest, "import.unknown_kind")
+	}
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return h.fail(c, http.StatusBadRequest, "import.file_missing")
+	}
+	if fh.Size > maxImportSize {
+		return h.fail(c, http.StatusRequestEntityTooLarge, "import.file_too_large")
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		h.logger.Error("ошибка открытия файла импорта", "error", err)
+		return h.fail(c, http.StatusBadRequest, "import.file_read_failed")
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxImportSize))
+	if err != nil {
+		h.logger.Error("ошибка чтения файла импорта", "error", err)
+		return h.fail(c, http.StatusBadRequest, "import.file_read_failed")
+	}
+
+	mapping := map[string]string{}
+	if m := c.FormValue("mapping"); m != "" {
+		if err := json.Unmarshal([]byte(m), &mapping); err != nil {
+			return h.fail(c, http.StatusBadRequest, "import.invalid_mapping")
+		}
+	}
+
+	dryRun, _ := strconv.ParseBool(c.FormValue("dry_run"))
+
+	report, err := importer.Run(c.Request().Context(), h.repo, kind, data, mapping, dryRun)
+	if err != nil {
+		if errors.Is(err, importer.ErrInvalidFile) {
+			h.logger.Warn("файл импорта отклонён", "kind", kind, "file", fh.Filename, "error", err)
+			return h.fail(c, http.StatusBadRequest, "import.invalid_file")
+		}
+
+		h.logger.Error("ошибка импорта", "kind", kind, "file", fh.Filename, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "import.failed")
+	}
+
+	h.logger.Info("импорт обработан",
+		"kind", kind,
+		"file", fh.Filename,
+		"dry_run", dryRun,
+		"committed", report.Committed,
+		"total", report.Total,
+		"failed", report.Failed,
+	)
+
+	switch {
+	case report.HasErrors():
+		return h.problem(c, models.Problem{Status: http.StatusUnprocessableEntity, Code: "import.has_errors", Data: report})
+	case dryRun:
+		return c.JSON(http.StatusOK, models.ServerResponse{
+			Status:  "success",
+			Message: h.tr(c, "import.dry_run_ok"),
+			Data:    report,
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "import.done"),
+		Data:    report,
+	})
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/notify"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) GetNotifications(c echo.Context) error {
+	userID, ok := c.Get("userID").(int)
+	if !ok {
+		return h.fail(c, http.StatusUnauthorized, "auth.unauthenticated")
+	}
+
+	unreadOnly := c.QueryParam("unread") == "true"
+
+	notifications, err := h.repo.GetNotifications(c.Request().Context(), userID, unreadOnly)
+	if err != nil {
+		h.logger.Error("ошибка получения уведомлений", "user_id", userID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "notifications.list_failed")
+	}
+
+	unread, err := h.repo.CountUnreadNotifications(c.Request().Context(), userID)
+	if err != nil {
+		h.logger.Error("ошибка подсчёта уведомлений", "user_id", userID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "notifications.list_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data: map[string]interface{}{
+			"unread": unread,
+			"items":  notifications,
+		},
+	})
+}
+
+func (h *Handler) MarkNotificationRead(c echo.Context) error {
+	idStr := c.Param("id")
+
+	notificationID, err := strconv.Atoi(idStr)
+	if err != nil || notificationID <= 0 {
+		h.logger.Warn("неверный формат ID уведомления", "id", idStr)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	userID, _ := c.Get("userID").(int)
+
+	updated, err := h.repo.MarkNotificationsRead(c.Request().Context(), userID, notificationID)
+	if err != nil {
+		h.logger.Error("ошибка обновления уведомления", "notification_id", notificationID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "notifications.update_failed")
+	}
+
+	if updated == 0 {
+		return h.fail(c, http.StatusNotFound, "notifications.unread_not_found")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "notifications.read"),
+	})
+}
+
+func (h *Handler) MarkAllNotificationsRead(c echo.Context) error {
+	userID, _ := c.Get("userID").(int)
+
+	updated, err := h.repo.MarkNotificationsRead(c.Request().Context(), userID, 0)
+	if err != nil {
+		h.logger.Error("ошибка обновления уведомлений", "user_id", userID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "notifications.update_all_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "notifications.all_read"),
+		Data:    map[string]interface{}{"updated": updated},
+	})
+}
+
+func (h *Handler) GetAlertRules(c echo.Context) error {
+	rules, err := h.repo.GetAlertRules(c.Request().Context())
+	if err != nil {
+		h.logger.Error("ошибка получения правил оповещений", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "alerts.list_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   rules,
+	})
+}
+
+func (h *Handler) CreateAlertRule(c echo.Context) error {
+	var req models.AlertRuleRequest
+
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.MinLessons <= 0 {
+		req.MinLessons = 4
+	}
+
+	if len(req.Channels) == 0 {
+		req.Channels = []string{notify.ChannelInbox}
+	}
+
+	rule := &models.AlertRule{
+		Name:       req.Name,
+		Threshold:  req.Threshold,
+		MinLessons: req.MinLessons,
+		Channels:   req.Channels,
+	}
+
+	if err := h.repo.CreateAlertRule(c.Request().Context(), rule); err != nil {
+		h.logger.Error("ошибка создания правила оповещений", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "alerts.create_failed")
+	}
+
+	h.logger.Info("правило оповещений создано", "rule_id", rule.RuleID, "threshold", rule.Threshold)
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "alerts.created"),
+		Data:    rule,
+	})
+}
+
+func (h *Handler) DeactivateAlertRule(c echo.Context) error {
+	idStr := c.Param("id")
+
+	ruleID, err := strconv.Atoi(idStr)
+	if err != nil || ruleID <= 0 {
+		h.logger.Warn("неверный формат ID правила", "id", idStr)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	if err := h.repo.DeactivateAlertRule(c.Request().Context(), ruleID); err != nil {
+		h.logger.Error("ошибка отключения правила оповещений", "rule_id", ruleID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "alerts.not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "alerts.disable_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "alerts.disabled"),
+	})
+}
+
+func (h *Handler) EvaluateAlerts(c echo.Context) error {
+	if h.alerts == nil {
+		return h.fail(c, http.StatusServiceUnavailable, "alerts.engine_disabled")
+	}
+
+	count, err := h.alerts.Evaluate(c.Request().Context(), nil)
+	if err != nil {
+		h.logger.Error("ошибка проверки правил оповещений", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "alerts.evaluate_failed")
+	}
+
+	h.logger.Info("ручная проверка правил оповещений", "events", count)
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   map[string]interface{}{"events": count},
+	})
+}
+
+func (h *Handler) SetGroupCurator(c echo.Context) error {
+	idStr := c.Param("id")
+
+	groupID, err := strconv.Atoi(idStr)
+	if err != nil || groupID <= 0 {
+		h.logger.Warn("неверный формат ID группы", "id", idStr)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	var req models.CuratorRequest
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	if err := h.repo.SetGroupCurator(c.Request().Context(), groupID, req.UserID); err != nil {
+		h.logger.Error("ошибка назначения куратора", "group_id", groupID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "groups.not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "groups.curator_failed")
+	}
+
+	h.logger.Info("куратор назначен", "group_id", groupID, "user_id", req.UserID)
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "groups.curator_assigned"),
+	})
+}
@@ -0,0 +1,503 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"hw_5_jwt/internal/graphql"
+	"hw_5_jwt/internal/importer"
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/openapi"
+	"hw_5_jwt/internal/timetable"
+
+	"github.com/labstack/echo/v4"
+)
+
+// apiRoute описывает маршрут для OpenAPI. Параметры пути берутся из Path, схемы тела
+// и поля data — из Go-типов моделей. Новый маршрут в RegisterRoutes без описания здесь
+// находит CheckOpenAPI.
+type apiRoute struct {
+	Method      string
+	Path        string
+	Tag         string
+	Summary     string
+	Description string
+	Public      bool
+	Roles       []string
+	Query       []apiParam
+	Body        any
+	Multipart   []apiParam
+	Data        any
+	// Unprocessable — схема data в ответе 422, если обработчик возвращает диагностику
+	Unprocessable any
+	Status        int
+	Produces      []string
+	// Plain — схема ответа, который не оборачивается в ServerResponse
+	Plain any
+}
+
+type apiParam struct {
+	Name        string
+	Description string
+	Required    bool
+	Enum        []string
+}
+
+type authData struct {
+	Token string      `json:"token"`
+	User  models.User `json:"user"`
+}
+
+type notificationsData struct {
+	Unread int                   `json:"unread"`
+	Items  []models.Notification `json:"items"`
+}
+
+var (
+	qTerm      = apiParam{Name: "term", Description: "Семестр: 2025-fall или 2026-spring; по умолчанию текущий"}
+	qFrom      = apiParam{Name: "from", Description: "Начало периода, DD.MM.YYYY"}
+	qTo        = apiParam{Name: "to", Description: "Конец периода, DD.MM.YYYY"}
+	qGroupID   = apiParam{Name: "group_id", Description: "ID группы"}
+	qSubjectID = apiParam{Name: "subject_id", Description: "ID предмета"}
+	qStudentID = apiParam{Name: "student_id", Description: "ID студента"}
+	qFaculty   = apiParam{Name: "faculty", Description: "Факультет"}
+
+	statsQuery  = []apiParam{qFrom, qTo, qTerm, qGroupID, qSubjectID, qStudentID, qFaculty}
+	gradeQuery  = []apiParam{qStudentID, qSubjectID, qGroupID, qTerm}
+	streamQuery = []apiParam{qSubjectID, qGroupID,
+		{Name: "schedule_id", Description: "ID занятия в расписании"},
+		{Name: "date", Description: "Дата занятия, DD.MM.YYYY"},
+		{Name: "last_event_id", Description: "Дослать события после этого event_id"},
+		{Name: "access_token", Description: "JWT, если клиент не может передать заголовок Authorization"},
+	}
+
+	graphqlQuery = []apiParam{
+		{Name: "query", Description: "Текст запроса", Required: true},
+		{Name: "operationName", Description: "Операция, если в запросе их несколько"},
+		{Name: "variables", Description: "Переменные, JSON-объект"},
+	}
+
+	adminOnly    = []string{RoleAdmin}
+	staffOnly    = []string{RoleTeacher, RoleAdmin}
+	studentOnly  = []string{RoleStudent}
+	studentAdmin = []string{RoleStudent, RoleAdmin}
+)
+
+const excuseDecisionDescription = "Учитель может рассмотреть справку, только если назначен на предметы всех пропусков в ней; иначе её рассматривает администратор."
+
+const (
+	tagService    = "Служебное"
+	tagAuth       = "Авторизация"
+	tagDirectory  = "Студенты и группы"
+	tagTeachers   = "Преподаватели"
+	tagSchedule   = "Расписание"
+	tagRooms      = "Аудитории"
+	tagAttendance = "Посещаемость"
+	tagCheckin    = "Отметка по коду"
+	tagExcuses    = "Справки"
+	tagStats      = "Статистика"
+	tagAlerts     = "Уведомления"
+	tagOfferings  = "Запись на предметы"
+	tagGrades     = "Оценки"
+	tagImport     = "Импорт"
+	tagTimetable  = "Генерация расписания"
+	tagWebhooks   = "Вебхуки"
+	tagGraphQL    = "GraphQL"
+)
+
+const graphqlDescription = "Только query. Ошибки запроса и полей возвращаются в errors со статусом 200, " +
+	"extensions.code — тот же код, что в problem+json. Права как в REST API: teacherLoad — только для преподавателей и администраторов. " +
+	"Запрос отклоняется, если вложенность полей больше 10 или сложность больше 5000: каждое поле стоит 1, поля внутри списков считаются 10 раз."
+
+var apiRoutes = []apiRoute{
+	{Method: http.MethodGet, Path: "/health", Tag: tagService, Summary: "Проверка работоспособности", Public: true, Data: ""},
+	{Method: http.MethodGet, Path: "/openapi.json", Tag: tagService, Summary: "Спецификация OpenAPI", Public: true, Produces: []string{"application/json"}},
+	{Method: http.MethodGet, Path: "/docs", Tag: tagService, Summary: "Интерактивная документация", Public: true, Produces: []string{"text/html"}},
+
+	{Method: http.MethodPost, Path: "/graphql", Tag: tagGraphQL, Summary: "Запрос GraphQL",
+		Description: graphqlDescription, Body: graphql.Request{}, Plain: graphql.Response{}},
+	{Method: http.MethodGet, Path: "/graphql", Tag: tagGraphQL, Summary: "Запрос GraphQL в параметрах URL",
+		Description: "То же, что POST /graphql; variables — JSON-объект.", Query: graphqlQuery, Plain: graphql.Response{}},
+	{Method: http.MethodGet, Path: "/graphql/schema.graphql", Tag: tagGraphQL, Summary: "Схема GraphQL (SDL)", Public: true, Produces: []string{"text/plain"}},
+
+	{Method: http.MethodPost, Path: "/api/auth/register", Tag: tagAuth, Summary: "Регистрация студента",
+		Description: "Самостоятельно регистрируются только студенты; учителей и администраторов создаёт администратор через POST /api/users.", Public: true, Body: models.RegisterRequest{}, Data: authData{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/auth/login", Tag: tagAuth, Summary: "Вход, выдаёт JWT", Public: true, Body: models.LoginRequest{}, Data: authData{}},
+	{Method: http.MethodPost, Path: "/api/users", Tag: tagAuth, Summary: "Создать пользователя с любой ролью", Roles: adminOnly, Body: models.CreateUserRequest{}, Data: models.User{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/users/me", Tag: tagAuth, Summary: "Текущий пользователь", Data: models.User{}},
+	{Method: http.MethodPut, Path: "/api/users/me/language", Tag: tagAuth, Summary: "Язык сообщений API", Body: models.LanguageRequest{}, Data: models.LanguageRequest{}},
+	{Method: http.MethodPost, Path: "/api/users/me/calendar-token", Tag: tagSchedule, Summary: "Секретная ссылка на календарь", Data: models.CalendarFeed{}, Status: http.StatusCreated},
+
+	{Method: http.MethodGet, Path: "/api/students", Tag: tagDirectory, Summary: "Все студенты", Data: []models.Student{}},
+	{Method: http.MethodGet, Path: "/api/students/:id", Tag: tagDirectory, Summary: "Студент по ID", Data: models.Student{}},
+	{Method: http.MethodPut, Path: "/api/students/:id/group", Tag: tagDirectory, Summary: "Перевести студента в группу",
+		Description: "Карточка студента, созданная при регистрации, получает группу; студент записывается на предметы группы в текущем семестре.", Roles: adminOnly,
+		Body: models.StudentGroupRequest{}, Data: models.Student{}},
+	{Method: http.MethodGet, Path: "/api/groups", Tag: tagDirectory, Summary: "Все группы", Data: []models.Group{}},
+	{Method: http.MethodGet, Path: "/api/groups/:id", Tag: tagDirectory, Summary: "Группа по ID", Data: models.Group{}},
+	{Method: http.MethodPut, Path: "/api/groups/:id/curator", Tag: tagDirectory, Summary: "Назначить куратора группы", Roles: adminOnly, Body: models.CuratorRequest{}},
+	{Method: http.MethodPost, Path: "/api/import/:kind", Tag: tagImport, Summary: "Импорт групп, студентов или расписания из CSV/XLSX", Roles: adminOnly,
+		Description: "kind: groups, students или schedule. При ошибках в строках возвращается 422 с отчётом, ничего не записывается. " +
+			"Колонка email у студентов дополняет карточку, созданную при регистрации этого пользователя.",
+		Multipart: []apiParam{
+			{Name: "file", Description: "CSV или XLSX до 10 МБ", Required: true},
+			{Name: "mapping", Description: "JSON {\"поле\": \"заголовок колонки\"}"},
+			{Name: "dry_run", Description: "true — только проверка"},
+		},
+		Data: importer.Report{}, Unprocessable: importer.Report{}},
+
+	{Method: http.MethodGet, Path: "/api/teachers", Tag: tagTeachers, Summary: "Все преподаватели", Data: []models.Teacher{}},
+	{Method: http.MethodPost, Path: "/api/teachers/subject", Tag: tagTeachers, Summary: "Назначить преподавателя лектором предмета на семестр",
+		Description: "В v2 путь /teachers/{id}/subjects: ID преподавателя берётся из пути, teacher_id в теле не нужен.", Roles: adminOnly, Body: models.SetInfoToTeacher{}, Data: []models.TeacherAssignment{}},
+	{Method: http.MethodGet, Path: "/api/teachers/load", Tag: tagTeachers, Summary: "Учебная нагрузка преподавателей", Roles: staffOnly, Query: []apiParam{qTerm}, Data: []models.TeacherLoad{}},
+	{Method: http.MethodGet, Path: "/api/teachers/:id/assignments", Tag: tagTeachers, Summary: "Назначения преподавателя", Query: []apiParam{qTerm}, Data: []models.TeacherAssignment{}},
+	{Method: http.MethodPost, Path: "/api/teachers/:id/assignments", Tag: tagTeachers, Summary: "Назначить преподавателя", Roles: adminOnly, Body: models.TeacherAssignmentRequest{}, Data: models.TeacherAssignment{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/teachers/:id/max-load", Tag: tagTeachers, Summary: "Лимит часов в неделю", Roles: adminOnly, Body: models.TeacherMaxLoadRequest{}},
+	{Method: http.MethodDelete, Path: "/api/teachers/assignments/:id", Tag: tagTeachers, Summary: "Удалить назначение", Roles: adminOnly},
+	{Method: http.MethodGet, Path: "/api/teachers/:id/availability", Tag: tagTeachers, Summary: "Доступность преподавателя", Roles: staffOnly, Data: []models.TeacherAvailability{}},
+	{Method: http.MethodPut, Path: "/api/teachers/:id/availability", Tag: tagTeachers, Summary: "Заменить доступность преподавателя", Roles: adminOnly, Body: []models.TeacherAvailability{}, Data: []models.TeacherAvailability{}},
+
+	{Method: http.MethodGet, Path: "/api/schedule", Tag: tagSchedule, Summary: "Всё расписание", Data: []models.Schedule{}},
+	{Method: http.MethodGet, Path: "/api/schedule/group/:id", Tag: tagSchedule, Summary: "Расписание группы",
+		Description: "С суффиксом .ics (/api/schedule/group/5.ics?token=...) отдаёт календарь iCalendar по секретной ссылке без Bearer-токена.",
+		Query:       []apiParam{{Name: "token", Description: "Секрет календарной ссылки, только для .ics"}},
+		Data:        []models.Schedule{}, Produces: []string{"application/json", "text/calendar"}},
+	{Method: http.MethodGet, Path: "/api/schedule/teacher/:id", Tag: tagSchedule, Summary: "Расписание преподавателя",
+		Description: "С суффиксом .ics отдаёт календарь iCalendar по секретной ссылке без Bearer-токена.",
+		Query:       []apiParam{qTerm, {Name: "token", Description: "Секрет календарной ссылки, только для .ics"}},
+		Data:        []models.ScheduleEntry{}, Produces: []string{"application/json", "text/calendar"}},
+	{Method: http.MethodPost, Path: "/api/schedule", Tag: tagSchedule, Summary: "Добавить занятие", Roles: adminOnly, Body: models.ScheduleEntryRequest{}, Data: models.ScheduleEntry{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/schedule/:id/room", Tag: tagSchedule, Summary: "Сменить аудиторию занятия", Roles: adminOnly, Body: models.ScheduleRoomRequest{}, Data: models.ScheduleEntry{}},
+	{Method: http.MethodGet, Path: "/api/holidays", Tag: tagSchedule, Summary: "Праздничные дни семестра", Query: []apiParam{qTerm}, Data: []models.Holiday{}},
+	{Method: http.MethodPost, Path: "/api/holidays", Tag: tagSchedule, Summary: "Добавить праздничный день", Roles: adminOnly, Body: models.HolidayRequest{}, Data: models.Holiday{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/holidays/:date", Tag: tagSchedule, Summary: "Удалить праздничный день (DD.MM.YYYY)", Roles: adminOnly},
+
+	{Method: http.MethodGet, Path: "/api/rooms", Tag: tagRooms, Summary: "Аудитории", Query: roomQuery, Data: []models.Room{}},
+	{Method: http.MethodPost, Path: "/api/rooms", Tag: tagRooms, Summary: "Добавить аудиторию", Roles: adminOnly, Body: models.RoomRequest{}, Data: models.Room{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/rooms/free", Tag: tagRooms, Summary: "Свободные аудитории",
+		Query: append([]apiParam{
+			{Name: "day_of_week", Description: "День недели 1–7", Required: true},
+			{Name: "start", Description: "Начало, HH:MM", Required: true},
+			{Name: "end", Description: "Конец, HH:MM", Required: true},
+		}, roomQuery...),
+		Data: []models.Room{}},
+	{Method: http.MethodGet, Path: "/api/rooms/occupancy", Tag: tagRooms, Summary: "Загрузка аудиторий", Roles: adminOnly, Query: []apiParam{{Name: "building", Description: "Корпус"}}, Data: []models.RoomOccupancy{}},
+	{Method: http.MethodGet, Path: "/api/rooms/:id/schedule", Tag: tagRooms, Summary: "Расписание аудитории", Data: []models.ScheduleEntry{}},
+
+	{Method: http.MethodPost, Path: "/api/attendance/subject", Tag: tagAttendance, Summary: "Отметить посещаемость",
+		Description: "Учителю — только занятия его предметов в его группах.", Roles: staffOnly, Body: models.AttendanceRequest{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/attendance/roster/:id", Tag: tagAttendance, Summary: "Список студентов занятия для переклички",
+		Description: "Учителю — только занятия его предметов в его группах.", Roles: staffOnly,
+		Query: []apiParam{{Name: "date", Description: "Дата занятия, DD.MM.YYYY"}}, Data: models.LessonRoster{}},
+	{Method: http.MethodPost, Path: "/api/attendance/bulk", Tag: tagAttendance, Summary: "Отметить всю группу за занятие",
+		Description: "Учителю — только занятия его предметов в его группах.", Roles: staffOnly, Body: models.BulkAttendanceRequest{}, Data: []models.BulkAttendanceResult{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/attendance/export", Tag: tagAttendance, Summary: "Выгрузка журнала посещаемости",
+		Description: "Журнал «студенты × занятия». Нужен хотя бы один из subject_id, group_id, student_id; студенту доступна только своя посещаемость.",
+		Query: append(append([]apiParam{}, statsQuery...),
+			apiParam{Name: "format", Description: "Формат файла", Enum: []string{"csv", "xlsx", "pdf"}}),
+		Produces: []string{"text/csv", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "application/pdf"}},
+	{Method: http.MethodGet, Path: "/api/attendance/stream", Tag: tagAttendance, Summary: "Отметки в реальном времени (Server-Sent Events)",
+		Description: "События attendance.recorded с id = event_id и data — AttendanceStreamEvent. Студент видит только свои отметки, " +
+			"учитель — назначенные ему предметы и курируемые группы. После обрыва пропущенные события досылаются по Last-Event-ID.",
+		Query: streamQuery, Produces: []string{"text/event-stream"}},
+	{Method: http.MethodGet, Path: "/api/attendance/ws", Tag: tagAttendance, Summary: "Отметки в реальном времени (WebSocket)",
+		Description: "Тот же поток, что /attendance/stream: сервер шлёт JSON StreamMessage, type = attendance.recorded или ping.",
+		Query:       streamQuery, Status: http.StatusSwitchingProtocols, Produces: []string{}},
+	{Method: http.MethodGet, Path: "/api/attendanceBySubjectId/:id", Tag: tagAttendance, Summary: "Посещаемость занятия", Data: []models.AttendanceBySubject{}},
+	{Method: http.MethodGet, Path: "/api/attendanceByStudentId/:id", Tag: tagAttendance, Summary: "Посещаемость студента", Data: []models.AttendanceByStudent{}},
+
+	{Method: http.MethodPost, Path: "/api/checkin/windows", Tag: tagCheckin, Summary: "Открыть окно отметки по коду", Roles: staffOnly,
+		Description: "Учителю — только для занятий его предметов в его группах. Опоздание считается от начала занятия по расписанию.", Body: models.CheckinWindowRequest{}, Data: models.CheckinWindow{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/checkin/windows/:id/code", Tag: tagCheckin, Summary: "Текущий код окна", Roles: staffOnly, Data: models.CheckinCode{}},
+	{Method: http.MethodPost, Path: "/api/checkin/windows/:id/close", Tag: tagCheckin, Summary: "Закрыть окно", Roles: staffOnly},
+	{Method: http.MethodPost, Path: "/api/checkin", Tag: tagCheckin, Summary: "Отметиться по коду", Roles: studentOnly, Body: models.CheckinRequest{}, Data: models.CheckinResult{}, Status: http.StatusCreated},
+
+	{Method: http.MethodPost, Path: "/api/excuses", Tag: tagExcuses, Summary: "Подать справку", Roles: studentOnly,
+		Description: "Принимает JSON или multipart-форму с теми же полями и файлом document.",
+		Body:        models.ExcuseRequest{}, Data: models.Excuse{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/excuses", Tag: tagExcuses, Summary: "Справки", Query: []apiParam{{Name: "status", Description: "pending, approved или rejected"}}, Data: []models.Excuse{}},
+	{Method: http.MethodGet, Path: "/api/excuses/:id", Tag: tagExcuses, Summary: "Справка по ID", Data: models.Excuse{}},
+	{Method: http.MethodGet, Path: "/api/excuses/:id/document", Tag: tagExcuses, Summary: "Файл справки", Produces: []string{"application/octet-stream"}},
+	{Method: http.MethodPost, Path: "/api/excuses/:id/approve", Tag: tagExcuses, Summary: "Одобрить справку", Roles: staffOnly,
+		Description: excuseDecisionDescription, Body: models.ExcuseDecision{}},
+	{Method: http.MethodPost, Path: "/api/excuses/:id/reject", Tag: tagExcuses, Summary: "Отклонить справку", Roles: staffOnly,
+		Description: excuseDecisionDescription, Body: models.ExcuseDecision{}},
+
+	{Method: http.MethodGet, Path: "/api/stats/students", Tag: tagStats, Summary: "Посещаемость по студентам", Roles: staffOnly, Query: statsQuery, Data: []models.StudentAttendanceStats{}},
+	{Method: http.MethodGet, Path: "/api/stats/subjects", Tag: tagStats, Summary: "Посещаемость по предметам", Roles: staffOnly, Query: statsQuery, Data: []models.SubjectAttendanceStats{}},
+	{Method: http.MethodGet, Path: "/api/stats/groups", Tag: tagStats, Summary: "Посещаемость по группам", Roles: staffOnly, Query: statsQuery, Data: []models.GroupAttendanceStats{}},
+	{Method: http.MethodGet, Path: "/api/stats/faculties", Tag: tagStats, Summary: "Посещаемость по факультетам", Roles: staffOnly, Query: statsQuery, Data: []models.FacultyAttendanceStats{}},
+	{Method: http.MethodGet, Path: "/api/stats/weekly", Tag: tagStats, Summary: "Посещаемость по неделям", Roles: staffOnly, Query: statsQuery, Data: []models.WeeklyAttendanceStats{}},
+	{Method: http.MethodGet, Path: "/api/stats/at-risk", Tag: tagStats, Summary: "Студенты с низкой посещаемостью", Roles: staffOnly,
+		Query: append(append([]apiParam{}, statsQuery...),
+			apiParam{Name: "threshold", Description: "Порог доли посещений, по умолчанию 0.75"},
+			apiParam{Name: "min_lessons", Description: "Минимум занятий для оценки, по умолчанию 3"}),
+		Data: []models.AtRiskStudent{}},
+
+	{Method: http.MethodGet, Path: "/api/notifications", Tag: tagAlerts, Summary: "Мои уведомления", Query: []apiParam{{Name: "unread", Description: "true — только непрочитанные"}}, Data: notificationsData{}},
+	{Method: http.MethodPost, Path: "/api/notifications/:id/read", Tag: tagAlerts, Summary: "Прочитать уведомление"},
+	{Method: http.MethodPost, Path: "/api/notifications/read-all", Tag: tagAlerts, Summary: "Прочитать все уведомления", Data: map[string]int64{}},
+	{Method: http.MethodGet, Path: "/api/alerts/rules", Tag: tagAlerts, Summary: "Правила оповещений", Roles: adminOnly, Data: []models.AlertRule{}},
+	{Method: http.MethodPost, Path: "/api/alerts/rules", Tag: tagAlerts, Summary: "Добавить правило оповещений", Roles: adminOnly, Body: models.AlertRuleRequest{}, Data: models.AlertRule{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/alerts/rules/:id", Tag: tagAlerts, Summary: "Отключить правило", Roles: adminOnly},
+	{Method: http.MethodPost, Path: "/api/alerts/evaluate", Tag: tagAlerts, Summary: "Проверить правила сейчас", Roles: adminOnly, Data: map[string]int{}},
+
+	{Method: http.MethodGet, Path: "/api/offerings", Tag: tagOfferings, Summary: "Предметы семестра", Query: gradeQuery, Data: []models.Offering{}},
+	{Method: http.MethodPost, Path: "/api/offerings", Tag: tagOfferings, Summary: "Открыть предмет на семестр", Roles: adminOnly, Body: models.OfferingRequest{}, Data: models.Offering{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/offerings/:id/enrollments", Tag: tagOfferings, Summary: "Записавшиеся на предмет", Roles: staffOnly,
+		Query: []apiParam{{Name: "status", Description: "enrolled, waitlisted или dropped"}}, Data: []models.Enrollment{}},
+	{Method: http.MethodPost, Path: "/api/offerings/:id/enroll", Tag: tagOfferings, Summary: "Записаться на предмет", Roles: studentAdmin, Body: models.EnrollmentRequest{}, Data: models.Enrollment{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/offerings/:id/drop", Tag: tagOfferings, Summary: "Отписаться от предмета", Roles: studentAdmin, Body: models.EnrollmentRequest{}, Data: models.DropResult{}},
+	{Method: http.MethodPost, Path: "/api/offerings/:id/enroll-group", Tag: tagOfferings, Summary: "Записать всю группу", Roles: adminOnly, Body: models.EnrollGroupRequest{}, Data: []models.Enrollment{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/enrollments/me", Tag: tagOfferings, Summary: "Мои записи", Roles: studentOnly, Query: []apiParam{qTerm}, Data: []models.Enrollment{}},
+
+	{Method: http.MethodGet, Path: "/api/assessments", Tag: tagGrades, Summary: "Контрольные мероприятия", Roles: staffOnly, Query: gradeQuery, Data: []models.Assessment{}},
+	{Method: http.MethodPost, Path: "/api/assessments", Tag: tagGrades, Summary: "Добавить мероприятие", Roles: staffOnly, Body: models.AssessmentRequest{}, Data: models.Assessment{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/assessments/:id", Tag: tagGrades, Summary: "Удалить мероприятие", Roles: staffOnly},
+	{Method: http.MethodGet, Path: "/api/assessments/:id/grades", Tag: tagGrades, Summary: "Оценки за мероприятие", Roles: staffOnly, Data: []models.Grade{}},
+	{Method: http.MethodPost, Path: "/api/assessments/:id/grades", Tag: tagGrades, Summary: "Выставить оценки списком", Roles: staffOnly, Body: models.BulkGradeRequest{}, Data: []models.BulkGradeResult{}},
+	{Method: http.MethodPut, Path: "/api/assessments/:id/grades/:student_id", Tag: tagGrades, Summary: "Выставить оценку студенту", Roles: staffOnly, Body: models.GradeRequest{}, Data: models.BulkGradeResult{}},
+	{Method: http.MethodGet, Path: "/api/grades/me", Tag: tagGrades, Summary: "Мои оценки", Roles: studentOnly, Query: gradeQuery, Data: models.StudentGrades{}},
+	{Method: http.MethodGet, Path: "/api/grades/final", Tag: tagGrades, Summary: "Итоговые оценки", Roles: staffOnly, Query: gradeQuery, Data: []models.FinalGrade{}},
+	{Method: http.MethodGet, Path: "/api/students/:id/grades", Tag: tagGrades, Summary: "Оценки студента", Roles: staffOnly, Query: gradeQuery, Data: models.StudentGrades{}},
+	{Method: http.MethodGet, Path: "/api/students/:id/transcript", Tag: tagGrades, Summary: "Выписка оценок",
+		Description: "?format=pdf или Accept: application/pdf отдаёт PDF. Студенту доступна только своя выписка.",
+		Query:       []apiParam{{Name: "format", Description: "Формат", Enum: []string{"json", "pdf"}}},
+		Data:        models.Transcript{}, Produces: []string{"application/json", "application/pdf"}},
+	{Method: http.MethodPut, Path: "/api/subjects/:id/credits", Tag: tagGrades, Summary: "Кредиты предмета", Roles: adminOnly, Body: models.SubjectCreditsRequest{}},
+	{Method: http.MethodGet, Path: "/api/grading-scale", Tag: tagGrades, Summary: "Шкала оценок", Data: []models.GradingScaleEntry{}},
+	{Method: http.MethodPut, Path: "/api/grading-scale", Tag: tagGrades, Summary: "Заменить шкалу оценок", Roles: adminOnly, Body: []models.GradingScaleEntry{}, Data: []models.GradingScaleEntry{}},
+
+	{Method: http.MethodGet, Path: "/api/timetable/drafts", Tag: tagTimetable, Summary: "Черновики расписания", Roles: adminOnly,
+		Query: []apiParam{{Name: "status", Description: "Статус черновика", Enum: []string{models.DraftStatusDraft, models.DraftStatusPublished, models.DraftStatusDiscarded}}},
+		Data:  []models.TimetableDraft{}},
+	{Method: http.MethodPost, Path: "/api/timetable/drafts", Tag: tagTimetable, Summary: "Сгенерировать черновик", Roles: adminOnly,
+		Description: "Если требования невыполнимы, возвращается 422 с диагностикой, черновик не сохраняется.",
+		Body:        models.TimetableRequest{}, Data: models.TimetableDraft{}, Unprocessable: timetable.Result{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/timetable/drafts/:id", Tag: tagTimetable, Summary: "Черновик с занятиями", Roles: adminOnly, Data: models.TimetableDraft{}},
+	{Method: http.MethodPost, Path: "/api/timetable/drafts/:id/publish", Tag: tagTimetable, Summary: "Опубликовать черновик", Roles: adminOnly, Data: []models.ScheduleEntry{}},
+	{Method: http.MethodDelete, Path: "/api/timetable/drafts/:id", Tag: tagTimetable, Summary: "Отклонить черновик", Roles: adminOnly},
+
+	{Method: http.MethodGet, Path: "/api/webhooks", Tag: tagWebhooks, Summary: "Подписки", Roles: adminOnly, Data: []models.WebhookSubscription{}},
+	{Method: http.MethodPost, Path: "/api/webhooks", Tag: tagWebhooks, Summary: "Подписаться на события", Roles: adminOnly, Body: models.WebhookSubscriptionRequest{}, Data: models.WebhookSubscription{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/webhooks/:id", Tag: tagWebhooks, Summary: "Отключить подписку", Roles: adminOnly},
+	{Method: http.MethodGet, Path: "/api/webhooks/:id/deliveries", Tag: tagWebhooks, Summary: "Доставки подписки", Roles: adminOnly,
+		Query: []apiParam{{Name: "status", Description: "Статус доставки"}}, Data: []models.WebhookDelivery{}},
+	{Method: http.MethodPost, Path: "/api/webhooks/deliveries/:id/redeliver", Tag: tagWebhooks, Summary: "Повторить доставку", Roles: adminOnly, Status: http.StatusAccepted},
+}
+
+var roomQuery = []apiParam{
+	{Name: "building", Description: "Корпус"},
+	{Name: "capacity", Description: "Минимальная вместимость"},
+	{Name: "equipment", Description: "Оборудование через запятую: projector,computers"},
+}
+
+// pathParamDescriptions — описания параметров пути; остальные считаются целочисленными ID.
+var pathParamDescriptions = map[string]string{
+	"kind": "Вид импорта: groups, students или schedule",
+	"date": "Дата, DD.MM.YYYY",
+}
+
+var (
+	specOnce sync.Once
+	spec     *openapi.Builder
+)
+
+// openAPISpec собирает документ один раз: таблица маршрутов и модели не меняются во время работы.
+func openAPISpec() *openapi.Builder {
+	specOnce.Do(func() {
+		spec = buildOpenAPI()
+	})
+	return spec
+}
+
+func buildOpenAPI() *openapi.Builder {
+	b := openapi.New(openapi.Info{
+		Title:       "University attendance API",
+		Version:     "2.0",
+		Description: "Версия v2 — /api/v2 с ресурсными путями (/students/{id}/attendance, /subjects/{id}/attendance). v1 — прежние пути под /api/v1 и без версии (/api/...), устарела: ответы несут заголовки Deprecation и Sunset. Все маршруты API, кроме входа и регистрации, требуют заголовок Authorization: Bearer <JWT>. Успешные ответы JSON обёрнуты в ServerResponse, ошибки — application/problem+json (RFC 7807) со стабильным code и request_id. Язык message и detail: из профиля (PUT /api/users/me/language), иначе по Accept-Language (ru, kk, en), по умолчанию ru.",
+	})
+	b.SecurityScheme("bearerAuth", openapi.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"})
+
+	envelope := b.Schema(models.ServerResponse{})
+	problem := b.Schema(models.Problem{})
+	errorResponse := func(description string) openapi.Response {
+		return openapi.Response{
+			Description: description,
+			Content:     map[string]openapi.MediaType{problemContentType: {Schema: problem}},
+		}
+	}
+
+	for _, r := range apiRoutes {
+		op := &openapi.Operation{
+			OperationID: operationID(r.Method, r.Path),
+			Tags:        []string{r.Tag},
+			Summary:     r.Summary,
+			Description: r.Description,
+			Roles:       r.Roles,
+			Responses:   map[string]openapi.Response{},
+		}
+
+		if !r.Public {
+			op.Security = []map[string][]string{{"bearerAuth": {}}}
+			op.Responses["401"] = errorResponse("Нет или неверный токен")
+			if len(r.Roles) > 0 {
+				op.Responses["403"] = errorResponse("Недостаточно прав: нужна роль " + strings.Join(r.Roles, " или "))
+			}
+		}
+
+		for _, name := range openapi.PathParams(r.Path) {
+			schema := &openapi.Schema{Type: "integer"}
+			description, ok := pathParamDescriptions[name]
+			if ok {
+				schema = &openapi.Schema{Type: "string"}
+			}
+			op.Parameters = append(op.Parameters, openapi.Parameter{Name: name, In: "path", Required: true, Description: description, Schema: schema})
+		}
+		for _, q := range r.Query {
+			op.Parameters = append(op.Parameters, openapi.Parameter{
+				Name:        q.Name,
+				In:          "query",
+				Required:    q.Required,
+				Description: q.Description,
+				Schema:      &openapi.Schema{Type: "string", Enum: q.Enum},
+			})
+		}
+
+		switch {
+		case r.Body != nil:
+			op.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]openapi.MediaType{"application/json": {Schema: b.Schema(r.Body)}},
+			}
+			op.Responses["400"] = errorResponse("Неверное тело запроса")
+			op.Responses["422"] = errorResponse("Поля запроса не прошли проверку: список в errors")
+		case r.Multipart != nil:
+			form := &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{}}
+			for _, p := range r.Multipart {
+				field := &openapi.Schema{Type: "string", Description: p.Description}
+				if p.Name == "file" {
+					field.Format = "binary"
+				}
+				form.Properties[p.Name] = field
+			}
+			op.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]openapi.MediaType{"multipart/form-data": {Schema: form}},
+			}
+			op.Responses["400"] = errorResponse("Неверный файл или параметры")
+			op.Responses["422"] = errorResponse("Поля запроса не прошли проверку: список в errors")
+		}
+
+		status := r.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := openapi.Response{Description: http.StatusText(status), Content: map[string]openapi.MediaType{}}
+		produces := r.Produces
+		if produces == nil {
+			produces = []string{"application/json"}
+		}
+		for _, mediaType := range produces {
+			var schema *openapi.Schema
+			switch {
+			case mediaType != "application/json":
+				schema = &openapi.Schema{Type: "string", Format: "binary"}
+			case r.Path == "/openapi.json":
+				schema = &openapi.Schema{Type: "object"}
+			case r.Plain != nil:
+				schema = b.Schema(r.Plain)
+			case r.Data != nil:
+				schema = openapi.Envelope(envelope, b.Schema(r.Data))
+			default:
+				schema = envelope
+			}
+			success.Content[mediaType] = openapi.MediaType{Schema: schema}
+		}
+		op.Responses[fmt.Sprint(status)] = success
+		if r.Unprocessable != nil {
+			op.Responses["422"] = openapi.Response{
+				Description: "Данные не прошли проверку: поля перечислены в errors, диагностика в data",
+				Content:     map[string]openapi.MediaType{problemContentType: {Schema: openapi.Envelope(problem, b.Schema(r.Unprocessable))}},
+			}
+		}
+		op.Responses["500"] = errorResponse("Внутренняя ошибка")
+
+		// пути без версии — псевдоним v1, в документе описываются /api/v1 и /api/v2
+		_, v1, v2, versioned := apiPaths(r.Path)
+		if !versioned {
+			b.Add(r.Method, r.Path, op)
+			continue
+		}
+		v1Op, v2Op := *op, *op
+		v1Op.OperationID, v1Op.Deprecated = operationID(r.Method, v1), true
+		v2Op.OperationID = operationID(r.Method, v2)
+		b.Add(r.Method, v1, &v1Op)
+		b.Add(r.Method, v2, &v2Op)
+	}
+	return b
+}
+
+// operationID строит стабильный идентификатор: GET /api/students/:id → getApiStudentsId.
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	upper := true
+	for _, r := range path {
+		switch {
+		case r == '/' || r == ':' || r == '-' || r == '_' || r == '.':
+			upper = true
+		case upper:
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// CheckOpenAPI сверяет зарегистрированные маршруты со спецификацией и возвращает
+// расхождения: маршруты без описания и описания маршрутов, которых нет в роутере.
+func CheckOpenAPI(routes []*echo.Route) []string {
+	b := openAPISpec()
+	var problems []string
+
+	registered := map[string]bool{}
+	for _, r := range routes {
+		// catch-all маршруты, которые Echo добавляет для middleware групп
+		if r.Method == echo.RouteNotFound {
+			continue
+		}
+		registered[r.Method+" "+r.Path] = true
+
+		if !b.Has(r.Method, documentedPath(r.Path)) {
+			problems = append(problems, fmt.Sprintf("нет в спецификации: %s %s", r.Method, r.Path))
+		}
+	}
+	for _, r := range apiRoutes {
+		paths := []string{r.Path}
+		if legacy, v1, v2, ok := apiPaths(r.Path); ok {
+			paths = []string{legacy, v1, v2}
+		}
+		for _, path := range paths {
+			if !registered[r.Method+" "+path] {
+				problems = append(problems, fmt.Sprintf("нет в роутере: %s %s", r.Method, path))
+			}
+		}
+	}
+
+	slices.Sort(problems)
+	return problems
+}
+
+func (h *Handler) GetOpenAPI(c echo.Context) error {
+	return c.JSON(http.StatusOK, openAPISpec().Document())
+}
+
+func (h *Handler) GetDocs(c echo.Context) error {
+	return c.HTMLBlob(http.StatusOK, openapi.DocsHTML)
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TestOpenAPICoversRoutes — то же, что go run ./cmd/openapi -check, но в go test.
+func TestOpenAPICoversRoutes(t *testing.T) {
+	e := echo.New()
+	h := NewHandler(nil, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	h.RegisterRoutes(e)
+
+	if problems := CheckOpenAPI(e.Routes()); len(problems) > 0 {
+		for _, problem := range problems {
+			t.Error(problem)
+		}
+		t.Fatalf("спецификация расходится с маршрутами: %d расхождений", len(problems))
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"hw_5_jwt/internal/i18n"
+	"hw_5_jwt/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+const problemContentType = "application/problem+json"
+
+// httpErrorCodes — коды ошибок, которые Echo возвращает сам: нет маршрута,
+// не тот метод, слишком большое тело и т. п.
+var httpErrorCodes = map[int]string{
+	http.StatusBadRequest:            "common.bad_request",
+	http.StatusUnauthorized:          "auth.unauthenticated",
+	http.StatusForbidden:             "common.forbidden",
+	http.StatusNotFound:              "common.route_not_found",
+	http.StatusMethodNotAllowed:      "common.method_not_allowed",
+	http.StatusRequestEntityTooLarge: "common.body_too_large",
+	http.StatusUnsupportedMediaType:  "common.unsupported_media_type",
+	http.StatusTooManyRequests:       "common.too_many_requests",
+	http.StatusServiceUnavailable:    "common.unavailable",
+}
+
+// newProblem заполняет общие поля тела ошибки. Тип about:blank: смысл ошибки
+// несёт code, поэтому title — стандартная фраза статуса.
+func newProblem(status int, code, detail, instance, requestID string) models.Problem {
+	return models.Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  instance,
+		Code:      code,
+		RequestID: requestID,
+	}
+}
+
+// requestID — идентификатор запроса из middleware RequestID или от клиента.
+func requestID(c echo.Context) string {
+	if id := c.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+		return id
+	}
+	return c.Request().Header.Get(echo.HeaderXRequestID)
+}
+
+// fail отвечает ошибкой code; detail — текст сообщения code на языке запроса.
+func (h *Handler) fail(c echo.Context, status int, code string, args ...any) error {
+	return h.problem(c, models.Problem{Status: status, Code: code, Detail: h.tr(c, code, args...)})
+}
+
+// failErr отвечает ошибкой вспомогательной функции, созданной через i18n.Errorf.
+// Текст прочих ошибок клиенту не отдаётся.
+func (h *Handler) failErr(c echo.Context, status int, err error) error {
+	var msg *i18n.Message
+	if errors.As(err, &msg) {
+		return h.fail(c, status, msg.ID, msg.Args...)
+	}
+
+	h.logger.Warn("ошибка без кода", "path", c.Path(), "error", err)
+	if status >= http.StatusInternalServerError {
+		return h.fail(c, status, "common.server_error")
+	}
+	return h.fail(c, status, "common.bad_request")
+}
+
+// problem пишет тело ошибки с полями errors или data, дополняя общие поля.
+func (h *Handler) problem(c echo.Context, p models.Problem) error {
+	if p.Detail == "" {
+		p.Detail = h.tr(c, p.Code)
+	}
+	body := newProblem(p.Status, p.Code, p.Detail, c.Request().URL.Path, requestID(c))
+	body.Errors, body.Data = p.Errors, p.Data
+
+	c.Response().Header().Set(echo.HeaderContentType, problemContentType)
+	return c.JSON(body.Status, body)
+}
+
+// codeOf — код переводимой ошибки для построчных результатов массовых операций.
+func codeOf(err error) string {
+	var msg *i18n.Message
+	if errors.As(err, &msg) {
+		return msg.ID
+	}
+	return "common.bad_request"
+}
+
+// HandleHTTPError заменяет обработчик ошибок Echo: ненайденные маршруты, паники
+// и ошибки middleware отдаются тем же problem+json, что и ошибки обработчиков.
+// Текст ошибки попадает только в журнал запросов.
+func (h *Handler) HandleHTTPError(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	status, code := http.StatusInternalServerError, "common.internal_error"
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		status = he.Code
+		if known, ok := httpErrorCodes[status]; ok {
+			code = known
+		} else if status < http.StatusInternalServerError {
+			code = "common.bad_request"
+		}
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = h.fail(c, status, code)
+	}
+	if err != nil {
+		h.logger.Error("ошибка отправки ответа об ошибке", "path", c.Path(), "error", err)
+	}
+}
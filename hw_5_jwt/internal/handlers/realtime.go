@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"hw_5_jwt/internal/i18n"
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/realtime"
+	"hw_5_jwt/internal/terms"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	streamHeartbeat = 25 * time.Second
+	streamRetry     = 3 * time.Second
+	socketWriteWait = 10 * time.Second
+)
+
+// tokenFromQuery переносит access_token из строки запроса в заголовок Authorization
+// и убирает его из URL, чтобы токен не попал в журнал запросов.
+func tokenFromQuery(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		token := takeQueryParam(req, "access_token")
+		if token != "" && req.Header.Get("Authorization") == "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return next(c)
+	}
+}
+
+// takeQueryParam возвращает параметр строки запроса и убирает его из URL и
+// RequestURI, которые RequestLogger пишет в журнал.
+func takeQueryParam(req *http.Request, name string) string {
+	query := req.URL.Query()
+	value := query.Get(name)
+	if !query.Has(name) {
+		return value
+	}
+
+	query.Del(name)
+	req.URL.RawQuery = query.Encode()
+	req.RequestURI = (&url.URL{Path: req.URL.Path, RawQuery: req.URL.RawQuery}).RequestURI()
+	return value
+}
+
+// parseStreamFilter читает subject_id, group_id, schedule_id и date подписки.
+func parseStreamFilter(c echo.Context) (realtime.Filter, error) {
+	var f realtime.Filter
+
+	ids := map[string]*int{
+		"subject_id":  &f.SubjectID,
+		"group_id":    &f.GroupID,
+		"schedule_id": &f.ScheduleID,
+	}
+	for name, dest := range ids {
+		value := c.QueryParam(name)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return f, i18n.Errorf("common.invalid_param", name)
+		}
+		*dest = id
+	}
+
+	if date := c.QueryParam("date"); date != "" {
+		normalized, err := normalizeDate(date)
+		if err != nil {
+			return f, i18n.Errorf("common.invalid_date")
+		}
+		f.VisitDay = normalized
+	}
+
+	return f, nil
+}
+
+// ScopeStore — запросы, по которым AttendanceScope определяет доступ.
+type ScopeStore interface {
+	GetStudentByUserID(ctx context.Context, userID int) (*models.Student, error)
+	GetCuratedGroupIDs(ctx context.Context, userID int) ([]int, error)
+	GetTeacherIDByUserID(ctx context.Context, userID int) (int, error)
+	GetTeacherAssignments(ctx context.Context, teacherID int, term string) ([]models.TeacherAssignment, error)
+}
+
+// AttendanceScope определяет, чьи отметки видит пользователь. Учитель видит
+// назначенные ему в текущем семестре предметы в группах и курируемые группы.
+// Используется и потоками REST API, и gRPC-сервером.
+func AttendanceScope(ctx context.Context, repo ScopeStore, user *models.User) (realtime.Scope, error) {
+	switch user.Role {
+	case RoleAdmin:
+		return realtime.Scope{All: true}, nil
+	case RoleStudent:
+		student, err := repo.GetStudentByUserID(ctx, user.ID)
+		if err != nil {
+			return realtime.Scope{}, err
+		}
+		return realtime.Scope{StudentID: student.StudentID}, nil
+	}
+
+	scope := realtime.Scope{Groups: map[int]bool{}, Lessons: map[realtime.Lesson]bool{}}
+
+	groupIDs, err := repo.GetCuratedGroupIDs(ctx, user.ID)
+	if err != nil {
+		return scope, err
+	}
+	for _, id := range groupIDs {
+		scope.Groups[id] = true
+	}
+
+	teacherID, err := repo.GetTeacherIDByUserID(ctx, user.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "не найден") {
+			return scope, nil
+		}
+		return scope, err
+	}
+	assignments, err := repo.GetTeacherAssignments(ctx, teacherID, terms.For(time.Now()))
+	if err != nil {
+		return scope, err
+	}
+	for _, a := range assignments {
+		scope.Lessons[realtime.Lesson{SubjectID: a.SubjectID, GroupID: a.GroupID}] = true
+	}
+
+	return scope, nil
+}
+
+// subscribeAttendance проверяет фильтр и права, подписывает клиента и возвращает
+// события после lastEventID. Если подписка не создана, err — уже отправленный ответ.
+func (h *Handler) subscribeAttendance(c echo.Context, lastEventID string) (*realtime.Subscription, []models.AttendanceStreamEvent, error) {
+	filter, err := parseStreamFilter(c)
+	if err != nil {
+		return nil, nil, h.failErr(c, http.StatusBadRequest, err)
+	}
+
+	var afterID int64
+	if lastEventID != "" {
+		afterID, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || afterID < 0 {
+			return nil, nil, h.fail(c, http.StatusBadRequest, "common.invalid_param", "last_event_id")
+		}
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return nil, nil, h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	scope, err := AttendanceScope(c.Request().Context(), h.repo, user)
+	if err != nil {
+		h.logger.Error("ошибка определения доступа к потоку посещаемости", "user_id", user.ID, "error", err)
+		return nil, nil, h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+	if !scope.Covers(filter) {
+		h.logger.Warn("нет доступа к потоку посещаемости",
+			"user_id", user.ID,
+			"subject_id", filter.SubjectID,
+			"group_id", filter.GroupID,
+		)
+		return nil, nil, h.fail(c, http.StatusForbidden, "common.forbidden")
+	}
+
+	// подписка раньше догрузки пропущенного: событие между ними придёт по обоим путям,
+	// повторы отбрасывает вызывающий
+	sub := h.hub.Subscribe(filter, scope)
+
+	var missed []models.AttendanceStreamEvent
+	if afterID > 0 {
+		missed, err = h.hub.Replay(c.Request().Context(), sub, afterID)
+		if err != nil {
+			h.hub.Unsubscribe(sub)
+			h.logger.Error("ошибка получения пропущенных событий", "after_id", afterID, "error", err)
+			return nil, nil, h.fail(c, http.StatusInternalServerError, "attendance.get_failed")
+		}
+	}
+
+	h.logger.Info("подписка на поток посещаемости",
+		"user_id", user.ID,
+		"role", user.Role,
+		"subject_id", filter.SubjectID,
+		"group_id", filter.GroupID,
+		"schedule_id", filter.ScheduleID,
+		"after_id", afterID,
+	)
+	return sub, missed, nil
+}
+
+// StreamAttendance: GET /attendance/stream — отметки посещаемости по мере записи
+// (Server-Sent Events). После обрыва EventSource сам передаёт Last-Event-ID, и
+// пропущенные события досылаются.
+func (h *Handler) StreamAttendance(c echo.Context) error {
+	lastEventID := c.Request().Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.QueryParam("last_event_id")
+	}
+
+	sub, missed, err := h.subscribeAttendance(c, lastEventID)
+	if sub == nil {
+		return err
+	}
+	defer h.hub.Unsubscribe(sub)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	// nginx иначе копит поток в буфере
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	fmt.Fprintf(res, "retry: %d\n\n", streamRetry.Milliseconds())
+	res.Flush()
+
+	// ответ уже начат: ошибки записи означают, что клиент ушёл
+	sent := make(map[int64]bool, len(missed))
+	for _, event := range missed {
+		if writeServerEvent(res, event) != nil {
+			return nil
+		}
+		sent[event.EventID] = true
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				return nil
+			}
+			if sent[event.EventID] {
+				continue
+			}
+			if writeServerEvent(res, event) != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+func writeServerEvent(res *echo.Response, event models.AttendanceStreamEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", event.EventID, models.EventAttendanceRecorded, data)
+	return err
+}
+
+// AttendanceSocket: GET /attendance/ws — тот же поток через WebSocket. Сервер шлёт
+// сообщения StreamMessage; сообщения клиента не ожидаются. Пропущенные после обрыва
+// события досылаются по last_event_id.
+func (h *Handler) AttendanceSocket(c echo.Context) error {
+	if !c.IsWebSocket() {
+		return h.fail(c, http.StatusBadRequest, "realtime.websocket_required")
+	}
+
+	sub, missed, err := h.subscribeAttendance(c, c.QueryParam("last_event_id"))
+	if sub == nil {
+		return err
+	}
+	defer h.hub.Unsubscribe(sub)
+
+	// токен уже проверен, Origin не сверяем: без токена сокет не открыть
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		h.pumpSocket(ws, sub, missed)
+	}}
+	server.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+func (h *Handler) pumpSocket(ws *websocket.Conn, sub *realtime.Subscription, missed []models.AttendanceStreamEvent) {
+	defer ws.Close()
+
+	// читаем только чтобы заметить закрытие соединения клиентом
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		var discard string
+		for websocket.Message.Receive(ws, &discard) == nil {
+		}
+	}()
+
+	send := func(msg models.StreamMessage) bool {
+		ws.SetWriteDeadline(time.Now().Add(socketWriteWait))
+		return websocket.JSON.Send(ws, msg) == nil
+	}
+	event := func(e models.AttendanceStreamEvent) models.StreamMessage {
+		return models.StreamMessage{Type: models.EventAttendanceRecorded, ID: e.EventID, Data: &e}
+	}
+
+	sent := make(map[int64]bool, len(missed))
+	for _, e := range missed {
+		if !send(event(e)) {
+			return
+		}
+		sent[e.EventID] = true
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-heartbeat.C:
+			if !send(models.StreamMessage{Type: "ping"}) {
+				return
+			}
+		case e, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if !sent[e.EventID] && !send(event(e)) {
+				return
+			}
+		}
+	}
+}
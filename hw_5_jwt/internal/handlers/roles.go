@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/postgres"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	RoleStudent = "student"
+	RoleTeacher = "teacher"
+	RoleAdmin   = "admin"
+)
+
+// EnsureAdmin создаёт первого администратора из ADMIN_EMAIL и ADMIN_PASSWORD:
+// через API администратора может создать только другой администратор.
+// Возвращает false, если пользователь с таким email уже есть.
+func EnsureAdmin(ctx context.Context, repo *postgres.Repository, email, password string) (bool, error) {
+	existing, err := repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		return false, nil
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return false, fmt.Errorf("ошибка хеширования пароля: %w", err)
+	}
+	_, err = repo.CreateUser(ctx, &models.User{
+		Email:    email,
+		Password: string(hashed),
+		Role:     RoleAdmin,
+		Status:   sql.NullString{String: "active", Valid: true},
+	})
+	return err == nil, err
+}
+
+// currentUser загружает пользователя из токена; результат кешируется в контексте запроса.
+func (h *Handler) currentUser(c echo.Context) (*models.User, error) {
+	if user, ok := c.Get("user").(*models.User); ok {
+		return user, nil
+	}
+
+	userID, ok := c.Get("userID").(int)
+	if !ok {
+		return nil, nil
+	}
+
+	user, err := h.repo.GetUserByID(c.Request().Context(), userID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	user.Password = ""
+	c.Set("user", user)
+	return user, nil
+}
+
+func (h *Handler) RequireRole(roles ...string) echo.MiddlewareFunc {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, err := h.currentUser(c)
+			if err != nil {
+				h.logger.Error("ошибка получения пользователя", "error", err)
+				return h.fail(c, http.StatusInternalServerError, "common.server_error")
+			}
+
+			if user == nil {
+				return h.fail(c, http.StatusUnauthorized, "auth.unauthenticated")
+			}
+
+			if !allowed[user.Role] {
+				h.logger.Warn("недостаточно прав",
+					"user_id", user.ID,
+					"role", user.Role,
+					"path", c.Path(),
+				)
+				return h.fail(c, http.StatusForbidden, "common.forbidden")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// requireLessonTeacher пропускает администратора и учителя, назначенного на предмет и
+// группу занятия в семестре visitDay (DD.MM.YYYY). Иначе отвечает ошибкой и возвращает false.
+func (h *Handler) requireLessonTeacher(c echo.Context, scheduleID int, visitDay string) (bool, error) {
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return false, h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+	if user.Role == RoleAdmin {
+		return true, nil
+	}
+
+	visitDate, err := time.Parse("02.01.2006", visitDay)
+	if err != nil {
+		return false, h.fail(c, http.StatusBadRequest, "common.invalid_date")
+	}
+
+	ok, err := h.repo.IsLessonTeacher(c.Request().Context(), scheduleID, visitDate, user.ID)
+	if err != nil {
+		h.logger.Error("ошибка проверки учителя занятия", "schedule_id", scheduleID, "error", err)
+		return false, h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+	if !ok {
+		h.logger.Warn("учитель не назначен на занятие", "schedule_id", scheduleID, "user_id", user.ID)
+		return false, h.fail(c, http.StatusForbidden, "common.forbidden")
+	}
+	return true, nil
+}
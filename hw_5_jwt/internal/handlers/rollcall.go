@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hw_5_jwt/internal/metrics"
+	"hw_5_jwt/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) GetLessonRoster(c echo.Context) error {
+	idStr := c.Param("id")
+
+	scheduleID, err := strconv.Atoi(idStr)
+	if err != nil || scheduleID <= 0 {
+		h.logger.Warn("неверный формат ID занятия", "id", idStr)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_lesson_id")
+	}
+
+	visitDay := c.QueryParam("date")
+	if visitDay == "" {
+		visitDay = time.Now().Format("02.01.2006")
+	}
+
+	normalizedDate, err := normalizeDate(visitDay)
+	if err != nil {
+		h.logger.Warn("неверный формат даты", "date", visitDay, "error", err)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_date")
+	}
+
+	if ok, err := h.requireLessonTeacher(c, scheduleID, normalizedDate); !ok {
+		return err
+	}
+
+	h.logger.Info("получение списка группы для переклички",
+		"schedule_id", scheduleID,
+		"visit_day", normalizedDate,
+	)
+
+	roster, err := h.repo.GetLessonRoster(c.Request().Context(), scheduleID, normalizedDate)
+	if err != nil {
+		h.logger.Error("ошибка получения списка группы", "schedule_id", scheduleID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "lessons.not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "rollcall.roster_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   roster,
+	})
+}
+
+func (h *Handler) CreateAttendanceBulk(c echo.Context) error {
+	var req models.BulkAttendanceRequest
+
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	// формат даты уже проверен валидатором
+	req.VisitDay, _ = normalizeDate(req.VisitDay)
+
+	if ok, err := h.requireLessonTeacher(c, req.ScheduleID, req.VisitDay); !ok {
+		return err
+	}
+
+	roster, err := h.repo.GetLessonRoster(c.Request().Context(), req.ScheduleID, req.VisitDay)
+	if err != nil {
+		h.logger.Error("ошибка получения списка группы", "schedule_id", req.ScheduleID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "lessons.not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "rollcall.roster_failed")
+	}
+
+	enrolled := make(map[int]bool, len(roster.Students))
+	for _, student := range roster.Students {
+		enrolled[student.StudentID] = true
+	}
+
+	results := make([]models.BulkAttendanceResult, 0, len(req.Students))
+	seen := make(map[int]bool, len(req.Students))
+	invalid := 0
+	for i, student := range req.Students {
+		result := models.BulkAttendanceResult{StudentID: student.StudentID, Status: "saved"}
+		reject := func(code string) {
+			result.Status, result.Code, result.Error = "rejected", code, h.tr(c, code)
+		}
+		status, visited, statusErr := resolveAttendanceStatus(student.Status, student.Visited, student.MinutesLate)
+		req.Students[i].Status, req.Students[i].Visited = status, visited
+		switch {
+		case statusErr != nil:
+			reject(codeOf(statusErr))
+		case student.StudentID <= 0:
+			reject("common.student_id_required")
+		case seen[student.StudentID]:
+			reject("common.student_duplicate")
+		case !enrolled[student.StudentID]:
+			reject("attendance.not_enrolled")
+		}
+		if result.Status == "rejected" {
+			invalid++
+		}
+		seen[student.StudentID] = true
+		results = append(results, result)
+	}
+
+	if invalid > 0 {
+		h.logger.Warn("перекличка отклонена",
+			"schedule_id", req.ScheduleID,
+			"visit_day", req.VisitDay,
+			"invalid", invalid,
+		)
+		for i := range results {
+			if results[i].Status == "saved" {
+				results[i].Status = "skipped"
+			}
+		}
+		return h.problem(c, models.Problem{Status: http.StatusUnprocessableEntity, Code: "rollcall.rejected", Data: results})
+	}
+
+	h.logger.Info("массовая запись посещаемости",
+		"schedule_id", req.ScheduleID,
+		"visit_day", req.VisitDay,
+		"count", len(req.Students),
+	)
+
+	if err := h.repo.CreateAttendanceBulk(c.Request().Context(), req); err != nil {
+		h.logger.Error("ошибка массовой записи посещаемости", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "rollcall.save_failed")
+	}
+
+	h.logger.Info("перекличка успешно сохранена", "schedule_id", req.ScheduleID, "count", len(results))
+	metrics.AttendanceWritten("rollcall", len(req.Students))
+
+	studentIDs := make([]int, 0, len(req.Students))
+	for _, student := range req.Students {
+		studentIDs = append(studentIDs, student.StudentID)
+	}
+	h.alerts.EvaluateAsync(studentIDs...)
+
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "rollcall.saved"),
+		Data:    results,
+	})
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hw_5_jwt/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// parseLessonTime приводит время занятия к виду HH:MM.
+func parseLessonTime(s string) (string, bool) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return "", false
+	}
+	return t.Format("15:04"), true
+}
+
+// parseRoomFilter читает building, capacity и equipment (через запятую) из запроса.
+func parseRoomFilter(c echo.Context) (models.RoomFilter, bool) {
+	f := models.RoomFilter{Building: c.QueryParam("building")}
+
+	if s := c.QueryParam("capacity"); s != "" {
+		capacity, err := strconv.Atoi(s)
+		if err != nil || capacity < 0 {
+			return f, false
+		}
+		f.MinCapacity = capacity
+	}
+
+	for _, tag := range strings.Split(c.QueryParam("equipment"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			f.Equipment = append(f.Equipment, tag)
+		}
+	}
+
+	return f, true
+}
+
+func (h *Handler) CreateRoom(c echo.Context) error {
+	var req models.RoomRequest
+
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Building = strings.TrimSpace(req.Building)
+
+	room, err := h.repo.CreateRoom(c.Request().Context(), req)
+	if err != nil {
+		h.logger.Error("ошибка создания аудитории", "name", req.Name, "error", err)
+
+		if strings.Contains(err.Error(), "уже существует") {
+			return h.fail(c, http.StatusConflict, "rooms.exists")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "rooms.create_failed")
+	}
+
+	h.logger.Info("аудитория создана", "room_id", room.RoomID, "name", room.Name)
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "rooms.created"),
+		Data:    room,
+	})
+}
+
+func (h *Handler) GetRooms(c echo.Context) error {
+	f, ok := parseRoomFilter(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "rooms.invalid_capacity")
+	}
+
+	rooms, err := h.repo.ListRooms(c.Request().Context(), f)
+	if err != nil {
+		h.logger.Error("ошибка получения аудиторий", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "rooms.list_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   rooms,
+	})
+}
+
+// FindFreeRooms: GET /rooms/free?day_of_week=1&start=09:00&end=10:30&capacity=30&equipment=projector
+func (h *Handler) FindFreeRooms(c echo.Context) error {
+	day, err := strconv.Atoi(c.QueryParam("day_of_week"))
+	if err != nil || day < 1 || day > 7 {
+		return h.fail(c, http.StatusBadRequest, "rooms.invalid_day")
+	}
+
+	start, ok1 := parseLessonTime(c.QueryParam("start"))
+	end, ok2 := parseLessonTime(c.QueryParam("end"))
+	if !ok1 || !ok2 || start >= end {
+		return h.fail(c, http.StatusBadRequest, "rooms.invalid_interval")
+	}
+
+	f, ok := parseRoomFilter(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "rooms.invalid_capacity")
+	}
+
+	rooms, err := h.repo.FindFreeRooms(c.Request().Context(), day, start, end, f)
+	if err != nil {
+		h.logger.Error("ошибка поиска свободных аудиторий", "day_of_week", day, "start", start, "end", end, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "rooms.free_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   rooms,
+	})
+}
+
+func (h *Handler) GetRoomSchedule(c echo.Context) error {
+	idStr := c.Param("id")
+
+	roomID, err := strconv.Atoi(idStr)
+	if err != nil || roomID <= 0 {
+		h.logger.Warn("неверный формат ID аудитории", "id", idStr)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	entries, err := h.repo.GetRoomSchedule(c.Request().Context(), roomID)
+	if err != nil {
+		h.logger.Error("ошибка получения расписания аудитории", "room_id", roomID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "rooms.schedule_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   entries,
+	})
+}
+
+func (h *Handler) GetRoomOccupancy(c echo.Context) error {
+	report, err := h.repo.GetRoomOccupancy(c.Request().Context(), c.QueryParam("building"))
+	if err != nil {
+		h.logger.Error("ошибка расчёта загрузки аудиторий", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "rooms.occupancy_failed")
+	}
+
+	h.logger.Info("отчёт о загрузке аудиторий сформирован", "rooms", len(report))
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   report,
+	})
+}
+
+// roomConflict отвечает на ошибки проверки аудитории; false — ошибка не про аудиторию.
+func (h *Handler) roomConflict(c echo.Context, err error) (bool, error) {
+	switch {
+	case strings.Contains(err.Error(), "не найден"):
+		return true, h.fail(c, http.StatusNotFound, "schedule.refs_not_found")
+	case strings.Contains(err.Error(), "уже занята"):
+		return true, h.fail(c, http.StatusConflict, "rooms.busy")
+	case strings.Contains(err.Error(), "превышена"):
+		return true, h.fail(c, http.StatusConflict, "rooms.too_small")
+	}
+	return false, nil
+}
+
+func (h *Handler) CreateScheduleEntry(c echo.Context) error {
+	var req models.ScheduleEntryRequest
+
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	// формат времени уже проверен валидатором
+	start, _ := parseLessonTime(req.StartTime)
+	end, _ := parseLessonTime(req.EndTime)
+	req.LessonName = strings.TrimSpace(req.LessonName)
+
+	if start >= end {
+		return h.fail(c, http.StatusBadRequest, "schedule.start_after_end")
+	}
+	req.StartTime, req.EndTime = start, end
+
+	entry, err := h.repo.CreateScheduleEntry(c.Request().Context(), req)
+	if err != nil {
+		h.logger.Error("ошибка создания занятия",
+			"group_id", req.GroupID,
+			"subject_id", req.SubjectID,
+			"room_id", req.RoomID,
+			"error", err,
+		)
+
+		if handled, err := h.roomConflict(c, err); handled {
+			return err
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "schedule.create_failed")
+	}
+
+	h.logger.Info("занятие добавлено в расписание",
+		"schedule_id", entry.ScheduleID,
+		"group_id", entry.GroupID,
+		"room_id", entry.RoomID,
+	)
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "schedule.created"),
+		Data:    entry,
+	})
+}
+
+func (h *Handler) SetScheduleRoom(c echo.Context) error {
+	idStr := c.Param("id")
+
+	scheduleID, err := strconv.Atoi(idStr)
+	if err != nil || scheduleID <= 0 {
+		h.logger.Warn("неверный формат ID занятия", "id", idStr)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	var req models.ScheduleRoomRequest
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	entry, err := h.repo.SetScheduleRoom(c.Request().Context(), scheduleID, req.RoomID)
+	if err != nil {
+		h.logger.Error("ошибка назначения аудитории", "schedule_id", scheduleID, "room_id", req.RoomID, "error", err)
+
+		if handled, err := h.roomConflict(c, err); handled {
+			return err
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "schedule.room_failed")
+	}
+
+	h.logger.Info("аудитория занятия обновлена", "schedule_id", scheduleID, "room_id", req.RoomID)
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "schedule.room_updated"),
+		Data:    entry,
+	})
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"hw_5_jwt/internal/i18n"
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/terms"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultAtRiskThreshold = 0.75
+	defaultAtRiskMinLesson = 3
+)
+
+func parseQueryDate(value string) (time.Time, error) {
+	normalized, err := normalizeDate(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse("02.01.2006", normalized)
+}
+
+// parseStatsFilter читает from/to или term и необязательные фильтры;
+// без периода берётся текущий семестр.
+func parseStatsFilter(c echo.Context) (models.StatsFilter, error) {
+	var f models.StatsFilter
+	var err error
+
+	from, to, term := c.QueryParam("from"), c.QueryParam("to"), c.QueryParam("term")
+	switch {
+	case from != "" || to != "":
+		if from == "" || to == "" {
+			return f, i18n.Errorf("stats.from_to_together")
+		}
+		if f.From, err = parseQueryDate(from); err != nil {
+			return f, i18n.Errorf("stats.invalid_from")
+		}
+		if f.To, err = parseQueryDate(to); err != nil {
+			return f, i18n.Errorf("stats.invalid_to")
+		}
+		if f.To.Before(f.From) {
+			return f, i18n.Errorf("stats.to_before_from")
+		}
+	default:
+		if term == "" {
+			term = terms.For(time.Now())
+		}
+		if f.From, f.To, err = terms.Bounds(term); err != nil {
+			return f, i18n.Errorf("common.invalid_term")
+		}
+	}
+
+	ids := map[string]*int{
+		"group_id":   &f.GroupID,
+		"subject_id": &f.SubjectID,
+		"student_id": &f.StudentID,
+	}
+	for name, dest := range ids {
+		value := c.QueryParam(name)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return f, i18n.Errorf("common.invalid_param", name)
+		}
+		*dest = id
+	}
+
+	f.Faculty = c.QueryParam("faculty")
+	return f, nil
+}
+
+func (h *Handler) statsResponse(c echo.Context, name string, load func(models.StatsFilter) (any, int, error)) error {
+	f, err := parseStatsFilter(c)
+	if err != nil {
+		return h.failErr(c, http.StatusBadRequest, err)
+	}
+
+	h.logger.Info("получение статистики посещаемости",
+		"report", name,
+		"from", f.From.Format("02.01.2006"),
+		"to", f.To.Format("02.01.2006"),
+	)
+
+	data, count, err := load(f)
+	if err != nil {
+		h.logger.Error("ошибка получения статистики", "report", name, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "stats.get_failed")
+	}
+
+	h.logger.Info("статистика успешно получена", "report", name, "count", count)
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   data,
+	})
+}
+
+func (h *Handler) GetStudentStats(c echo.Context) error {
+	return h.statsResponse(c, "students", func(f models.StatsFilter) (any, int, error) {
+		stats, err := h.repo.GetStudentAttendanceStats(c.Request().Context(), f)
+		return stats, len(stats), err
+	})
+}
+
+func (h *Handler) GetSubjectStats(c echo.Context) error {
+	return h.statsResponse(c, "subjects", func(f models.StatsFilter) (any, int, error) {
+		stats, err := h.repo.GetSubjectAttendanceStats(c.Request().Context(), f)
+		return stats, len(stats), err
+	})
+}
+
+func (h *Handler) GetGroupStats(c echo.Context) error {
+	return h.statsResponse(c, "groups", func(f models.StatsFilter) (any, int, error) {
+		stats, err := h.repo.GetGroupAttendanceStats(c.Request().Context(), f)
+		return stats, len(stats), err
+	})
+}
+
+func (h *Handler) GetFacultyStats(c echo.Context) error {
+	return h.statsResponse(c, "faculties", func(f models.StatsFilter) (any, int, error) {
+		stats, err := h.repo.GetFacultyAttendanceStats(c.Request().Context(), f)
+		return stats, len(stats), err
+	})
+}
+
+func (h *Handler) GetWeeklyStats(c echo.Context) error {
+	return h.statsResponse(c, "weekly", func(f models.StatsFilter) (any, int, error) {
+		stats, err := h.repo.GetWeeklyAttendanceStats(c.Request().Context(), f)
+		return stats, len(stats), err
+	})
+}
+
+func (h *Handler) GetAtRiskStudents(c echo.Context) error {
+	threshold := defaultAtRiskThreshold
+	if value := c.QueryParam("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 || parsed > 1 {
+			return h.fail(c, http.StatusBadRequest, "stats.invalid_threshold")
+		}
+		threshold = parsed
+	}
+
+	minLessons := defaultAtRiskMinLesson
+	if value := c.QueryParam("min_lessons"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return h.fail(c, http.StatusBadRequest, "stats.invalid_min_lessons")
+		}
+		minLessons = parsed
+	}
+
+	return h.statsResponse(c, "at-risk", func(f models.StatsFilter) (any, int, error) {
+		students, err := h.repo.GetAtRiskStudents(c.Request().Context(), f, threshold, minLessons)
+		return students, len(students), err
+	})
+}
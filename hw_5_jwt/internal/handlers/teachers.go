@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/terms"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) teacherIDParam(c echo.Context) (int, bool) {
+	idStr := c.Param("id")
+	teacherID, err := strconv.Atoi(idStr)
+	if err != nil || teacherID <= 0 {
+		h.logger.Warn("неверный формат ID учителя", "id", idStr)
+		return 0, false
+	}
+	return teacherID, true
+}
+
+// queryTerm возвращает term из запроса или текущий семестр.
+func queryTerm(c echo.Context) (string, bool) {
+	term := c.QueryParam("term")
+	if term == "" {
+		return terms.For(time.Now()), true
+	}
+	_, _, err := terms.Bounds(term)
+	return term, err == nil
+}
+
+func (h *Handler) AssignTeacher(c echo.Context) error {
+	teacherID, ok := h.teacherIDParam(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	var req models.TeacherAssignmentRequest
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	if req.Role == "" {
+		req.Role = models.AssignmentLecturer
+	}
+	if req.Term == "" {
+		req.Term = terms.For(time.Now())
+	}
+
+	assignment, err := h.repo.AssignTeacher(c.Request().Context(), teacherID, req)
+	if err != nil {
+		h.logger.Error("ошибка назначения учителя",
+			"teacher_id", teacherID,
+			"subject_id", req.SubjectID,
+			"group_id", req.GroupID,
+			"error", err,
+		)
+
+		switch {
+		case strings.Contains(err.Error(), "не найден"):
+			return h.fail(c, http.StatusNotFound, "teachers.not_found")
+		case strings.Contains(err.Error(), "уже назначен"):
+			return h.fail(c, http.StatusConflict, "assignments.exists")
+		case strings.Contains(err.Error(), "превышена"):
+			return h.fail(c, http.StatusConflict, "teachers.overloaded")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "assignments.create_failed")
+	}
+
+	h.logger.Info("учитель назначен",
+		"assignment_id", assignment.AssignmentID,
+		"teacher_id", teacherID,
+		"role", assignment.Role,
+		"weekly_hours", assignment.WeeklyHours,
+	)
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "assignments.created"),
+		Data:    assignment,
+	})
+}
+
+func (h *Handler) GetTeacherAssignments(c echo.Context) error {
+	teacherID, ok := h.teacherIDParam(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	assignments, err := h.repo.GetTeacherAssignments(c.Request().Context(), teacherID, c.QueryParam("term"))
+	if err != nil {
+		h.logger.Error("ошибка получения назначений", "teacher_id", teacherID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "assignments.list_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   assignments,
+	})
+}
+
+func (h *Handler) DeleteTeacherAssignment(c echo.Context) error {
+	idStr := c.Param("id")
+
+	assignmentID, err := strconv.Atoi(idStr)
+	if err != nil || assignmentID <= 0 {
+		h.logger.Warn("неверный формат ID назначения", "id", idStr)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	if err := h.repo.DeleteTeacherAssignment(c.Request().Context(), assignmentID); err != nil {
+		h.logger.Error("ошибка удаления назначения", "assignment_id", assignmentID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "assignments.not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "assignments.delete_failed")
+	}
+
+	h.logger.Info("назначение удалено", "assignment_id", assignmentID)
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "assignments.deleted"),
+	})
+}
+
+func (h *Handler) SetTeacherMaxLoad(c echo.Context) error {
+	teacherID, ok := h.teacherIDParam(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	var req models.TeacherMaxLoadRequest
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	if err := h.repo.SetTeacherMaxLoad(c.Request().Context(), teacherID, req.MaxWeeklyHours); err != nil {
+		h.logger.Error("ошибка обновления нагрузки", "teacher_id", teacherID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "teachers.not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "teachers.max_load_failed")
+	}
+
+	h.logger.Info("максимальная нагрузка обновлена", "teacher_id", teacherID, "hours", req.MaxWeeklyHours)
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "teachers.max_load_updated"),
+	})
+}
+
+func (h *Handler) GetTeachingLoad(c echo.Context) error {
+	term, ok := queryTerm(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_term")
+	}
+
+	loads, err := h.repo.GetTeachingLoad(c.Request().Context(), term)
+	if err != nil {
+		h.logger.Error("ошибка расчёта нагрузки", "term", term, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "teachers.load_failed")
+	}
+
+	h.logger.Info("отчёт о нагрузке сформирован", "term", term, "teachers", len(loads))
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   loads,
+	})
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/terms"
+	"hw_5_jwt/internal/timetable"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) GetTeacherAvailability(c echo.Context) error {
+	teacherID, ok := h.teacherIDParam(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	windows, err := h.repo.GetTeacherAvailability(c.Request().Context(), teacherID)
+	if err != nil {
+		h.logger.Error("ошибка получения доступности учителя", "teacher_id", teacherID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "availability.get_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   windows,
+	})
+}
+
+// SetTeacherAvailability заменяет окна доступности; пустой список — учитель доступен всегда.
+func (h *Handler) SetTeacherAvailability(c echo.Context) error {
+	teacherID, ok := h.teacherIDParam(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	var windows []models.TeacherAvailability
+	if ok, err := h.bind(c, &windows); !ok {
+		return err
+	}
+
+	for i, w := range windows {
+		start, _ := parseLessonTime(w.StartTime)
+		end, _ := parseLessonTime(w.EndTime)
+		if start >= end {
+			return h.fail(c, http.StatusBadRequest, "availability.invalid_window", i+1)
+		}
+		windows[i].StartTime, windows[i].EndTime = start, end
+	}
+
+	if err := h.repo.SetTeacherAvailability(c.Request().Context(), teacherID, windows); err != nil {
+		h.logger.Error("ошибка сохранения доступности учителя", "teacher_id", teacherID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "teachers.not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "availability.save_failed")
+	}
+
+	h.logger.Info("доступность учителя обновлена", "teacher_id", teacherID, "windows", len(windows))
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "availability.updated"),
+		Data:    windows,
+	})
+}
+
+// GenerateTimetable строит черновик расписания. Если ограничения невыполнимы,
+// черновик не сохраняется, а в ответе перечисляются невыполненные требования.
+func (h *Handler) GenerateTimetable(c echo.Context) error {
+	var req models.TimetableRequest
+
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	if req.Term == "" {
+		req.Term = terms.For(time.Now())
+	}
+
+	useRooms := req.UseRooms == nil || *req.UseRooms
+	ctx := c.Request().Context()
+
+	problem, err := h.repo.LoadTimetableProblem(ctx, req.Term, req.Requirements, useRooms)
+	if err != nil {
+		h.logger.Error("ошибка подготовки данных для расписания", "term", req.Term, "error", err)
+
+		switch {
+		case strings.Contains(err.Error(), "не найден"):
+			return h.fail(c, http.StatusNotFound, "timetable.refs_not_found")
+		case strings.Contains(err.Error(), "не назначен"):
+			return h.fail(c, http.StatusUnprocessableEntity, "timetable.no_lecturer")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "timetable.prepare_failed")
+	}
+
+	problem.Days = req.Days
+	problem.MaxLessonsPerDay = req.MaxLessonsPerDay
+	problem.Seed = req.Seed
+	problem.MaxSteps = req.MaxSteps
+	for _, per := range req.Periods {
+		problem.Periods = append(problem.Periods, timetable.Period{Start: per.Start, End: per.End})
+	}
+
+	result, err := timetable.Solve(problem)
+	if err != nil {
+		h.logger.Warn("неверные параметры генерации расписания", "error", err)
+		return h.fail(c, http.StatusBadRequest, "timetable.invalid_params")
+	}
+
+	if !result.Feasible() {
+		h.logger.Warn("расписание не составлено",
+			"term", req.Term,
+			"seed", req.Seed,
+			"unplaced", len(result.Unplaced),
+			"steps", result.Steps,
+		)
+		return h.problem(c, models.Problem{Status: http.StatusUnprocessableEntity, Code: "timetable.infeasible", Data: result})
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	draft := &models.TimetableDraft{
+		Term:      req.Term,
+		Seed:      req.Seed,
+		Penalty:   result.Penalty,
+		Steps:     result.Steps,
+		CreatedBy: user.ID,
+	}
+	for _, l := range result.Lessons {
+		lesson := models.TimetableDraftLesson{
+			GroupID:    l.GroupID,
+			SubjectID:  l.SubjectID,
+			TeacherID:  l.TeacherID,
+			LessonName: l.LessonName,
+			DayOfWeek:  l.Day,
+			StartTime:  l.Start,
+			EndTime:    l.End,
+		}
+		if l.RoomID != 0 {
+			roomID := l.RoomID
+			lesson.RoomID = &roomID
+		}
+		draft.Lessons = append(draft.Lessons, lesson)
+	}
+
+	if err := h.repo.CreateTimetableDraft(ctx, draft); err != nil {
+		h.logger.Error("ошибка сохранения черновика расписания", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "timetable.draft_save_failed")
+	}
+
+	h.logger.Info("черновик расписания создан",
+		"draft_id", draft.DraftID,
+		"term", draft.Term,
+		"seed", draft.Seed,
+		"lessons", len(draft.Lessons),
+		"penalty", draft.Penalty,
+	)
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "timetable.draft_created"),
+		Data:    draft,
+	})
+}
+
+func (h *Handler) GetTimetableDrafts(c echo.Context) error {
+	drafts, err := h.repo.ListTimetableDrafts(c.Request().Context(), c.QueryParam("status"))
+	if err != nil {
+		h.logger.Error("ошибка получения черновиков расписания", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "timetable.drafts_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   drafts,
+	})
+}
+
+func (h *Handler) draftIDParam(c echo.Context) (int, bool) {
+	idStr := c.Param("id")
+	draftID, err := strconv.Atoi(idStr)
+	if err != nil || draftID <= 0 {
+		h.logger.Warn("неверный формат ID черновика", "id", idStr)
+		return 0, false
+	}
+	return draftID, true
+}
+
+func (h *Handler) GetTimetableDraft(c echo.Context) error {
+	draftID, ok := h.draftIDParam(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	draft, err := h.repo.GetTimetableDraft(c.Request().Context(), draftID)
+	if err != nil {
+		h.logger.Error("ошибка получения черновика расписания", "draft_id", draftID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "timetable.draft_not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "timetable.draft_get_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   draft,
+	})
+}
+
+func (h *Handler) PublishTimetableDraft(c echo.Context) error {
+	draftID, ok := h.draftIDParam(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	entries, err := h.repo.PublishTimetableDraft(c.Request().Context(), draftID)
+	if err != nil {
+		h.logger.Error("ошибка публикации черновика расписания", "draft_id", draftID, "error", err)
+
+		switch {
+		case strings.Contains(err.Error(), "не найден"):
+			return h.fail(c, http.StatusNotFound, "timetable.draft_not_found")
+		case strings.Contains(err.Error(), "уже"), strings.Contains(err.Error(), "превышена"):
+			return h.fail(c, http.StatusConflict, "timetable.draft_not_publishable")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "timetable.publish_failed")
+	}
+
+	h.logger.Info("расписание опубликовано", "draft_id", draftID, "lessons", len(entries))
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "timetable.published"),
+		Data:    entries,
+	})
+}
+
+func (h *Handler) DiscardTimetableDraft(c echo.Context) error {
+	draftID, ok := h.draftIDParam(c)
+	if !ok {
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	if err := h.repo.DiscardTimetableDraft(c.Request().Context(), draftID); err != nil {
+		h.logger.Error("ошибка отклонения черновика расписания", "draft_id", draftID, "error", err)
+
+		switch {
+		case strings.Contains(err.Error(), "не найден"):
+			return h.fail(c, http.StatusNotFound, "timetable.draft_not_found")
+		case strings.Contains(err.Error(), "уже"):
+			return h.fail(c, http.StatusConflict, "timetable.draft_closed")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "timetable.discard_failed")
+	}
+
+	h.logger.Info("черновик расписания отклонён", "draft_id", draftID)
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "timetable.discarded"),
+	})
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/pdf"
+	"hw_5_jwt/internal/terms"
+
+	"github.com/labstack/echo/v4"
+)
+
+// buildTranscript группирует итоговые оценки по семестрам. В GPA попадают только предметы,
+// по которым оценены все мероприятия; остальные помечаются in_progress.
+func buildTranscript(student *models.Student, groupName string, finals []models.FinalGrade) models.Transcript {
+	transcript := models.Transcript{
+		StudentID:   student.StudentID,
+		Name:        student.Name,
+		Surname:     student.Surname,
+		GroupName:   groupName,
+		Terms:       []models.TranscriptTerm{},
+		GeneratedAt: time.Now(),
+	}
+
+	byTerm := map[string]*models.TranscriptTerm{}
+	var order []string
+	for _, f := range finals {
+		term, ok := byTerm[f.Term]
+		if !ok {
+			term = &models.TranscriptTerm{Term: f.Term}
+			byTerm[f.Term] = term
+			order = append(order, f.Term)
+		}
+		term.Subjects = append(term.Subjects, models.TranscriptEntry{
+			SubjectID:   f.SubjectID,
+			SubjectName: f.SubjectName,
+			Credits:     f.Credits,
+			Percent:     f.Percent,
+			Letter:      f.Letter,
+			GPAPoints:   f.GPAPoints,
+			InProgress:  f.GradedWeight < f.TotalWeight,
+		})
+	}
+
+	// ключи семестров сравниваются по дате начала: "2026-spring" раньше "2026-fall"
+	sort.Slice(order, func(i, j int) bool {
+		a, _, _ := terms.Bounds(order[i])
+		b, _, _ := terms.Bounds(order[j])
+		return a.Before(b)
+	})
+
+	var totalPoints float64
+	for _, key := range order {
+		term := byTerm[key]
+		var points float64
+		for _, entry := range term.Subjects {
+			if entry.InProgress {
+				continue
+			}
+			term.Credits += entry.Credits
+			points += entry.GPAPoints * float64(entry.Credits)
+		}
+		if term.Credits > 0 {
+			term.GPA = roundGPA(points / float64(term.Credits))
+		}
+		transcript.TotalCredits += term.Credits
+		totalPoints += points
+		transcript.Terms = append(transcript.Terms, *term)
+	}
+
+	if transcript.TotalCredits > 0 {
+		transcript.CumulativeGPA = roundGPA(totalPoints / float64(transcript.TotalCredits))
+	}
+
+	return transcript
+}
+
+func roundGPA(gpa float64) float64 {
+	return math.Round(gpa*100) / 100
+}
+
+func renderTranscriptPDF(t models.Transcript) []byte {
+	doc := pdf.New()
+	columns := []float64{pdf.Margin, 330, 390, 450, 505}
+
+	doc.Row([]float64{pdf.Margin}, 16, true, "Academic transcript")
+	doc.Space(6)
+	doc.Row([]float64{pdf.Margin}, 10, false, fmt.Sprintf("Student: %s %s (ID %d)", t.Surname, t.Name, t.StudentID))
+	doc.Row([]float64{pdf.Margin}, 10, false, "Group: "+t.GroupName)
+	doc.Row([]float64{pdf.Margin}, 10, false, "Issued: "+t.GeneratedAt.Format("02.01.2006"))
+
+	for _, term := range t.Terms {
+		doc.Space(10)
+		doc.Row([]float64{pdf.Margin}, 12, true, "Term "+term.Term)
+		doc.Row(columns, 9, true, "Subject", "Credits", "Score", "Grade", "Points")
+		doc.Rule()
+		for _, entry := range term.Subjects {
+			letter, points := entry.Letter, strconv.FormatFloat(entry.GPAPoints, 'f', 2, 64)
+			if entry.InProgress {
+				letter, points = "IP", "-"
+			}
+			doc.Row(columns, 9, false,
+				entry.SubjectName,
+				strconv.Itoa(entry.Credits),
+				strconv.FormatFloat(entry.Percent, 'f', 2, 64),
+				letter,
+				points,
+			)
+		}
+		doc.Rule()
+		doc.Row([]float64{pdf.Margin, 330}, 9, true,
+			fmt.Sprintf("Term GPA: %.2f", term.GPA),
+			fmt.Sprintf("Credits: %d", term.Credits),
+		)
+	}
+
+	doc.Space(14)
+	doc.Row([]float64{pdf.Margin, 330}, 11, true,
+		fmt.Sprintf("Cumulative GPA: %.2f", t.CumulativeGPA),
+		fmt.Sprintf("Total credits: %d", t.TotalCredits),
+	)
+	doc.Row([]float64{pdf.Margin}, 8, false, "IP - in progress, not included in GPA")
+
+	var buf bytes.Buffer
+	doc.WriteTo(&buf)
+	return buf.Bytes()
+}
+
+// GetTranscript отдаёт выписку в JSON или PDF (?format=pdf либо Accept: application/pdf).
+// Студенту доступна только собственная выписка.
+func (h *Handler) GetTranscript(c echo.Context) error {
+	idStr := c.Param("id")
+
+	studentID, err := strconv.Atoi(idStr)
+	if err != nil || studentID <= 0 {
+		h.logger.Warn("неверный формат ID студента", "id", idStr)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil || user == nil {
+		h.logger.Error("ошибка получения пользователя", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	if user.Role == RoleStudent {
+		own, err := h.repo.GetStudentByUserID(c.Request().Context(), user.ID)
+		if err != nil || own.StudentID != studentID {
+			h.logger.Warn("нет доступа к выписке", "student_id", studentID, "user_id", user.ID)
+			return h.fail(c, http.StatusForbidden, "common.forbidden")
+		}
+	}
+
+	student, err := h.repo.GetStudent(c.Request().Context(), studentID)
+	if err != nil {
+		h.logger.Error("ошибка получения студента", "id", studentID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "students.not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "transcript.get_failed")
+	}
+
+	var groupName string
+	if group, err := h.repo.GetGroup(c.Request().Context(), student.GroupID); err == nil {
+		groupName = group.GroupName
+	}
+
+	finals, err := h.repo.GetFinalGrades(c.Request().Context(), models.GradeFilter{StudentID: studentID})
+	if err != nil {
+		h.logger.Error("ошибка расчёта итоговых оценок", "student_id", studentID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "transcript.get_failed")
+	}
+
+	transcript := buildTranscript(student, groupName, finals)
+	h.logger.Info("выписка сформирована",
+		"student_id", studentID,
+		"terms", len(transcript.Terms),
+		"cumulative_gpa", transcript.CumulativeGPA,
+	)
+
+	if c.QueryParam("format") == "pdf" || strings.Contains(c.Request().Header.Get(echo.HeaderAccept), "application/pdf") {
+		filename := fmt.Sprintf("transcript-%d.pdf", studentID)
+		c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+strconv.Quote(filename))
+		return c.Blob(http.StatusOK, "application/pdf", renderTranscriptPDF(transcript))
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   transcript,
+	})
+}
+
+func (h *Handler) SetSubjectCredits(c echo.Context) error {
+	idStr := c.Param("id")
+
+	subjectID, err := strconv.Atoi(idStr)
+	if err != nil || subjectID <= 0 {
+		h.logger.Warn("неверный формат ID предмета", "id", idStr)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	var req models.SubjectCreditsRequest
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	if err := h.repo.SetSubjectCredits(c.Request().Context(), subjectID, req.Credits); err != nil {
+		h.logger.Error("ошибка обновления кредитов", "subject_id", subjectID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "subjects.not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "transcript.credits_update_failed")
+	}
+
+	h.logger.Info("кредиты предмета обновлены", "subject_id", subjectID, "credits", req.Credits)
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "transcript.credits_updated"),
+	})
+}
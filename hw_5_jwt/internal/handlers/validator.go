@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"hw_5_jwt/internal/i18n"
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/terms"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// requestValidator проверяет модели запросов по тегам validate. Поля в ошибках
+// называются так же, как в JSON: students[0].status.
+type requestValidator struct {
+	v *validator.Validate
+}
+
+func newRequestValidator() *requestValidator {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+
+	v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+	v.RegisterValidation("date", func(fl validator.FieldLevel) bool {
+		_, err := normalizeDate(fl.Field().String())
+		return err == nil
+	})
+	v.RegisterValidation("clock", func(fl validator.FieldLevel) bool {
+		_, ok := parseLessonTime(fl.Field().String())
+		return ok
+	})
+	v.RegisterValidation("term", func(fl validator.FieldLevel) bool {
+		_, _, err := terms.Bounds(fl.Field().String())
+		return err == nil
+	})
+
+	return &requestValidator{v: v}
+}
+
+// fieldError — ошибка поля до перевода: текст собирается на языке запроса в bind.
+type fieldError struct {
+	Field   string
+	Code    string
+	Message *i18n.Message
+}
+
+// ValidationErrors — ошибки всех полей запроса, не прошедших проверку.
+type ValidationErrors []fieldError
+
+func (e ValidationErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Field + ": " + fe.Code
+	}
+	return "ошибка валидации: " + strings.Join(parts, ", ")
+}
+
+// Validate реализует echo.Validator. Срезы (например, расписание доступности)
+// проверяются поэлементно, имя поля начинается с индекса: [2].start_time.
+func (rv *requestValidator) Validate(i any) error {
+	val := reflect.ValueOf(i)
+	for val.Kind() == reflect.Pointer {
+		val = val.Elem()
+	}
+
+	if val.Kind() == reflect.Slice {
+		var all ValidationErrors
+		for n := 0; n < val.Len(); n++ {
+			err := rv.Validate(val.Index(n).Addr().Interface())
+			var fields ValidationErrors
+			if errors.As(err, &fields) {
+				for _, fe := range fields {
+					fe.Field = fmt.Sprintf("[%d].%s", n, fe.Field)
+					all = append(all, fe)
+				}
+			} else if err != nil {
+				return err
+			}
+		}
+		if len(all) > 0 {
+			return all
+		}
+		return nil
+	}
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	err := rv.v.Struct(val.Addr().Interface())
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return err
+	}
+
+	fields := make(ValidationErrors, 0, len(invalid))
+	for _, fe := range invalid {
+		// пространство имён начинается с имени типа: AttendanceRequest.students[0].status
+		_, field, _ := strings.Cut(fe.Namespace(), ".")
+		fields = append(fields, fieldError{
+			Field:   field,
+			Code:    fe.Tag(),
+			Message: fieldMessage(fe),
+		})
+	}
+	return fields
+}
+
+// fieldMessages — идентификаторы текстов ошибок по коду проверки; параметр тега
+// подставляется в шаблон. Для строк и списков min/max означают длину.
+var fieldMessages = map[string]string{
+	"required": "validation.required",
+	"notblank": "validation.notblank",
+	"email":    "validation.email",
+	"http_url": "validation.http_url",
+	"oneof":    "validation.oneof",
+	"min":      "validation.min",
+	"max":      "validation.max",
+	"gt":       "validation.gt",
+	"gte":      "validation.gte",
+	"lt":       "validation.lt",
+	"lte":      "validation.lte",
+	"date":     "validation.date",
+	"clock":    "validation.clock",
+	"term":     "validation.term",
+	"unique":   "validation.unique",
+}
+
+var lengthMessages = map[string]string{
+	"min": "validation.min_length",
+	"max": "validation.max_length",
+}
+
+func fieldMessage(fe validator.FieldError) *i18n.Message {
+	id, ok := fieldMessages[fe.Tag()]
+	if !ok {
+		return &i18n.Message{ID: "validation.invalid"}
+	}
+	if kind := fe.Kind(); kind == reflect.String || kind == reflect.Slice || kind == reflect.Map {
+		if lengthID, ok := lengthMessages[fe.Tag()]; ok {
+			id = lengthID
+		}
+	}
+
+	param := fe.Param()
+	if fe.Tag() == "oneof" {
+		param = strings.Join(strings.Fields(param), ", ")
+	}
+	if !strings.Contains(i18n.T(i18n.Default, id), "%s") {
+		return &i18n.Message{ID: id}
+	}
+	return &i18n.Message{ID: id, Args: []any{param}}
+}
+
+// bind читает тело запроса и проверяет его по тегам validate. При ошибке ответ уже
+// записан: 400, если тело не разобрано, и 422 со списком полей, если не прошла проверка.
+func (h *Handler) bind(c echo.Context, req any) (bool, error) {
+	if err := c.Bind(req); err != nil {
+		h.logger.Warn("ошибка привязки данных", "path", c.Path(), "error", err)
+		return false, h.fail(c, http.StatusBadRequest, "common.invalid_body")
+	}
+
+	err := c.Validate(req)
+	if err == nil {
+		return true, nil
+	}
+
+	var fields ValidationErrors
+	if !errors.As(err, &fields) {
+		h.logger.Error("ошибка проверки данных", "path", c.Path(), "error", err)
+		return false, h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	h.logger.Warn("данные запроса не прошли проверку", "path", c.Path(), "error", err)
+	errs := make([]models.FieldError, len(fields))
+	for i, fe := range fields {
+		errs[i] = models.FieldError{
+			Field:   fe.Field,
+			Code:    fe.Code,
+			Message: h.tr(c, fe.Message.ID, fe.Message.Args...),
+		}
+	}
+	return false, h.problem(c, models.Problem{Status: http.StatusUnprocessableEntity, Code: "common.validation_failed", Errors: errs})
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// v1 — прежние пути API: без версии (/api/...), на них работает мобильное
+// приложение, и те же пути под /api/v1. v2 использует те же обработчики, но
+// ресурсные пути вместо исторических имён.
+const (
+	legacyPrefix = "/api"
+	v1Prefix     = "/api/v1"
+	v2Prefix     = "/api/v2"
+)
+
+// v2Paths — пути v1 (относительно префикса версии), которые в v2 заменены.
+// Остальные маршруты в обеих версиях совпадают; календари /schedule/group/:id.ics
+// остаются на прежних путях, потому что ссылки на них уже выданы пользователям.
+var v2Paths = map[string]string{
+	"/attendance/subject":        "/attendance",
+	"/attendance/roster/:id":     "/lessons/:id/roster",
+	"/attendanceBySubjectId/:id": "/subjects/:id/attendance",
+	"/attendanceByStudentId/:id": "/students/:id/attendance",
+	"/teachers/subject":          "/teachers/:id/subjects",
+}
+
+// Даты вывода v1 из эксплуатации: заголовки Deprecation (RFC 9745) и Sunset (RFC 8594).
+var (
+	v1DeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	v1SunsetAt     = time.Date(2027, time.September, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func v2Path(path string) string {
+	if renamed, ok := v2Paths[path]; ok {
+		return renamed
+	}
+	return path
+}
+
+// apiGroup регистрирует маршруты одной версии API. Пути в registerAPI записаны
+// как в v1; paths переводит их в пути версии по полному пути от префикса версии.
+type apiGroup struct {
+	group  *echo.Group
+	prefix string
+	paths  map[string]string
+}
+
+func (g apiGroup) path(path string) string {
+	renamed, ok := g.paths[g.prefix+path]
+	if !ok {
+		return path
+	}
+	if !strings.HasPrefix(renamed, g.prefix) {
+		panic("handlers: путь " + renamed + " выходит за группу " + g.prefix)
+	}
+	return strings.TrimPrefix(renamed, g.prefix)
+}
+
+func (g apiGroup) Group(prefix string, m ...echo.MiddlewareFunc) apiGroup {
+	return apiGroup{group: g.group.Group(prefix, m...), prefix: g.prefix + prefix, paths: g.paths}
+}
+
+func (g apiGroup) GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.group.GET(g.path(path), h, m...)
+}
+
+func (g apiGroup) POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.group.POST(g.path(path), h, m...)
+}
+
+func (g apiGroup) PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.group.PUT(g.path(path), h, m...)
+}
+
+func (g apiGroup) DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.group.DELETE(g.path(path), h, m...)
+}
+
+// DeprecatedV1 помечает ответы v1 заголовками Deprecation, Sunset и ссылкой на
+// маршрут v2 и пишет обращение в журнал, чтобы видеть, кто ещё не перешёл.
+func (h *Handler) DeprecatedV1(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// группа /api перехватывает неизвестные пути /api/v2/... своим маршрутом 404,
+		// такие ответы относятся к v2 и не помечаются
+		if isV2Path(c.Request().URL.Path) {
+			return next(c)
+		}
+
+		header := c.Response().Header()
+		header.Set("Deprecation", "@"+strconv.FormatInt(v1DeprecatedAt.Unix(), 10))
+		header.Set("Sunset", v1SunsetAt.Format(http.TimeFormat))
+		if successor := v2Successor(c); successor != "" {
+			header.Add("Link", "<"+successor+`>; rel="successor-version"`)
+		}
+
+		err := next(c)
+
+		// userID появляется после AuthMiddleware, поэтому пишем после обработчика
+		userID, _ := c.Get("userID").(int)
+		h.logger.Info("запрос к API v1",
+			"method", c.Request().Method,
+			"route", c.Path(),
+			"user_id", userID,
+			"user_agent", c.Request().UserAgent(),
+		)
+		return err
+	}
+}
+
+func isV2Path(path string) bool {
+	return path == v2Prefix || strings.HasPrefix(path, v2Prefix+"/")
+}
+
+// v2Successor строит путь v2 для текущего запроса v1 с подставленными параметрами.
+func v2Successor(c echo.Context) string {
+	route := c.Path()
+	for _, prefix := range []string{v1Prefix, legacyPrefix} {
+		if rest, ok := strings.CutPrefix(route, prefix); ok {
+			route = rest
+			break
+		}
+	}
+	if route == "" || strings.HasSuffix(route, "*") {
+		return ""
+	}
+
+	successor := v2Prefix + v2Path(route)
+	for i, name := range c.ParamNames() {
+		successor = strings.Replace(successor, ":"+name, c.ParamValues()[i], 1)
+	}
+	return successor
+}
+
+// apiPaths возвращает пути маршрута v1 из таблицы apiRoutes во всех версиях:
+// /api/students → /api/students, /api/v1/students и /api/v2/students.
+func apiPaths(path string) (legacy, v1, v2 string, ok bool) {
+	rest, ok := strings.CutPrefix(path, legacyPrefix+"/")
+	if !ok {
+		return "", "", "", false
+	}
+	rest = "/" + rest
+	return path, v1Prefix + rest, v2Prefix + v2Path(rest), true
+}
+
+// documentedPath — путь маршрута в спецификации: пути без версии описаны как /api/v1.
+func documentedPath(path string) string {
+	if strings.HasPrefix(path, v1Prefix+"/") || strings.HasPrefix(path, v2Prefix+"/") {
+		return path
+	}
+	if rest, ok := strings.CutPrefix(path, legacyPrefix+"/"); ok {
+		return v1Prefix + "/" + rest
+	}
+	return path
+}
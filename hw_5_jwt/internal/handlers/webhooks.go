@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"hw_5_jwt/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) CreateWebhookSubscription(c echo.Context) error {
+	var req models.WebhookSubscriptionRequest
+
+	if ok, err := h.bind(c, &req); !ok {
+		return err
+	}
+
+	// адрес уже проверен валидатором (http_url)
+	target, _ := url.Parse(req.URL)
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		h.logger.Error("ошибка генерации секрета", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "common.server_error")
+	}
+
+	sub := &models.WebhookSubscription{
+		URL:    target.String(),
+		Secret: "whsec_" + hex.EncodeToString(secret),
+		Events: req.Events,
+	}
+
+	userID, _ := c.Get("userID").(int)
+	if err := h.repo.CreateWebhookSubscription(c.Request().Context(), sub, userID); err != nil {
+		h.logger.Error("ошибка создания подписки", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "webhooks.create_failed")
+	}
+
+	h.logger.Info("подписка на вебхуки создана", "subscription_id", sub.SubscriptionID, "events", sub.Events)
+
+	// секрет показывается только при создании
+	return c.JSON(http.StatusCreated, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "webhooks.created"),
+		Data:    sub,
+	})
+}
+
+func (h *Handler) GetWebhookSubscriptions(c echo.Context) error {
+	subs, err := h.repo.GetWebhookSubscriptions(c.Request().Context())
+	if err != nil {
+		h.logger.Error("ошибка получения подписок", "error", err)
+		return h.fail(c, http.StatusInternalServerError, "webhooks.list_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   subs,
+	})
+}
+
+func (h *Handler) DeactivateWebhookSubscription(c echo.Context) error {
+	idStr := c.Param("id")
+
+	subID, err := strconv.Atoi(idStr)
+	if err != nil || subID <= 0 {
+		h.logger.Warn("неверный формат ID подписки", "id", idStr)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	if err := h.repo.DeactivateWebhookSubscription(c.Request().Context(), subID); err != nil {
+		h.logger.Error("ошибка отключения подписки", "subscription_id", subID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "webhooks.not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "webhooks.disable_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "webhooks.disabled"),
+	})
+}
+
+func (h *Handler) GetWebhookDeliveries(c echo.Context) error {
+	idStr := c.Param("id")
+
+	subID, err := strconv.Atoi(idStr)
+	if err != nil || subID <= 0 {
+		h.logger.Warn("неверный формат ID подписки", "id", idStr)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	deliveries, err := h.repo.GetWebhookDeliveries(c.Request().Context(), subID, c.QueryParam("status"))
+	if err != nil {
+		h.logger.Error("ошибка получения доставок", "subscription_id", subID, "error", err)
+		return h.fail(c, http.StatusInternalServerError, "webhooks.deliveries_failed")
+	}
+
+	return c.JSON(http.StatusOK, models.ServerResponse{
+		Status: "success",
+		Data:   deliveries,
+	})
+}
+
+func (h *Handler) RedeliverWebhook(c echo.Context) error {
+	idStr := c.Param("id")
+
+	deliveryID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || deliveryID <= 0 {
+		h.logger.Warn("неверный формат ID доставки", "id", idStr)
+		return h.fail(c, http.StatusBadRequest, "common.invalid_id")
+	}
+
+	if err := h.repo.RedeliverWebhook(c.Request().Context(), deliveryID); err != nil {
+		h.logger.Error("ошибка повторной доставки", "delivery_id", deliveryID, "error", err)
+
+		if strings.Contains(err.Error(), "не найден") {
+			return h.fail(c, http.StatusNotFound, "webhooks.delivery_not_found")
+		}
+
+		return h.fail(c, http.StatusInternalServerError, "webhooks.redeliver_failed")
+	}
+
+	h.logger.Info("доставка поставлена в очередь повторно", "delivery_id", deliveryID)
+	return c.JSON(http.StatusAccepted, models.ServerResponse{
+		Status:  "success",
+		Message: h.tr(c, "webhooks.redelivered"),
+	})
+}
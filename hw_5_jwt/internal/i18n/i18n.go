@@ -0,0 +1,202 @@
+// Package i18n переводит сообщения API по каталогам ru, kk и en. Каталоги —
+// JSON-файлы в locales/, ключи — стабильные идентификаторы сообщений
+// (auth.invalid_credentials), значения — шаблоны fmt с теми же глаголами во всех языках.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	RU = "ru"
+	KK = "kk"
+	EN = "en"
+
+	// Default — язык исходных текстов и запасной вариант для непереведённых сообщений.
+	Default = RU
+)
+
+// Languages — поддерживаемые языки в порядке предпочтения при равном q.
+var Languages = []string{RU, KK, EN}
+
+//go:embed locales/*.json
+var files embed.FS
+
+var (
+	catalogs = map[string]map[string]string{}
+	logs     = map[string]map[string]string{}
+)
+
+func init() {
+	for _, lang := range Languages {
+		catalogs[lang] = load("locales/" + lang + ".json")
+		if lang != Default {
+			logs[lang] = load("locales/log." + lang + ".json")
+		}
+	}
+}
+
+// load читает встроенный каталог; битый JSON — ошибка сборки, поэтому паника.
+func load(name string) map[string]string {
+	data, err := files.ReadFile(name)
+	if err != nil {
+		panic(fmt.Sprintf("i18n: каталог %s не найден: %v", name, err))
+	}
+	catalog := map[string]string{}
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		panic(fmt.Sprintf("i18n: ошибка разбора каталога %s: %v", name, err))
+	}
+	return catalog
+}
+
+// Supported сообщает, есть ли каталог для языка.
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// T возвращает сообщение id на языке lang. Если перевода нет, берётся русский текст,
+// а если нет и его — сам идентификатор, чтобы ошибка в ключе была видна в ответе.
+func T(lang, id string, args ...any) string {
+	format, ok := catalogs[lang][id]
+	if !ok {
+		if format, ok = catalogs[Default][id]; !ok {
+			return id
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Has сообщает, описан ли идентификатор в основном каталоге.
+func Has(id string) bool {
+	_, ok := catalogs[Default][id]
+	return ok
+}
+
+// Message — переводимая ошибка: вспомогательные функции возвращают её вместо
+// готового текста, а обработчик переводит на язык запроса.
+type Message struct {
+	ID   string
+	Args []any
+}
+
+func (m *Message) Error() string {
+	return T(Default, m.ID, m.Args...)
+}
+
+// Errorf создаёт переводимую ошибку с аргументами шаблона.
+func Errorf(id string, args ...any) error {
+	return &Message{ID: id, Args: args}
+}
+
+// Negotiate выбирает язык по заголовку Accept-Language (ru-RU, kk;q=0.9, en;q=0.5).
+// Сравнивается основной подтег; kz считается kk. Пустая строка — подходящего языка нет.
+func Negotiate(header string) string {
+	type choice struct {
+		lang string
+		q    float64
+		pos  int
+	}
+	var choices []choice
+	for i, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if primary == "kz" {
+			primary = KK
+		}
+		if !Supported(primary) {
+			continue
+		}
+
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(p), "=")
+			if ok && strings.TrimSpace(name) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			choices = append(choices, choice{primary, q, i})
+		}
+	}
+	if len(choices) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+	return choices[0].lang
+}
+
+// Check сверяет каталоги с русским: возвращает ключи, которых нет в переводах,
+// лишние ключи и переводы с другим набором глаголов fmt. Каталоги журнала
+// сверяются между собой: сообщение, переведённое на один язык, должно быть
+// переведено и на остальные.
+func Check() []string {
+	var problems []string
+	base := catalogs[Default]
+	for _, lang := range Languages {
+		if lang == Default {
+			continue
+		}
+		catalog := catalogs[lang]
+		for id, format := range base {
+			translated, ok := catalog[id]
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("%s: нет перевода %s", lang, id))
+			case verbs(translated) != verbs(format):
+				problems = append(problems, fmt.Sprintf("%s: %s: глаголы fmt не совпадают с ru", lang, id))
+			}
+		}
+		for id := range catalog {
+			if _, ok := base[id]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: лишний ключ %s", lang, id))
+			}
+		}
+	}
+
+	messages := map[string]bool{}
+	for _, catalog := range logs {
+		for message := range catalog {
+			messages[message] = true
+		}
+	}
+	for lang, catalog := range logs {
+		for message := range messages {
+			translated, ok := catalog[message]
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("log.%s: нет перевода %q", lang, message))
+			case verbs(translated) != verbs(message):
+				problems = append(problems, fmt.Sprintf("log.%s: %q: глаголы fmt не совпадают с ru", lang, message))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// verbs возвращает глаголы fmt шаблона по порядку: "%s %d" → "sd".
+func verbs(format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format)-1; i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if format[i] != '%' {
+			b.WriteByte(format[i])
+		}
+	}
+	return b.String()
+}
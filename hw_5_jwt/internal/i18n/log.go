@@ -0,0 +1,50 @@
+package i18n
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Журнал пишется из многих пакетов строками на русском, поэтому каталоги журнала
+// (locales/log.<язык>.json) ключуются самим исходным сообщением. Непереведённые
+// сообщения остаются как есть.
+
+type logHandler struct {
+	next    slog.Handler
+	catalog map[string]string
+}
+
+// NewLogHandler переводит сообщения записей журнала на lang. Для русского и
+// неподдерживаемых языков возвращает next без обёртки.
+func NewLogHandler(next slog.Handler, lang string) slog.Handler {
+	catalog, ok := logs[lang]
+	if !ok {
+		return next
+	}
+	return &logHandler{next: next, catalog: catalog}
+}
+
+func (h *logHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *logHandler) Handle(ctx context.Context, r slog.Record) error {
+	if translated, ok := h.catalog[r.Message]; ok {
+		// Record нельзя менять на месте: у копии общий срез атрибутов
+		out := slog.NewRecord(r.Time, r.Level, translated, r.PC)
+		r.Attrs(func(a slog.Attr) bool {
+			out.AddAttrs(a)
+			return true
+		})
+		r = out
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &logHandler{next: h.next.WithAttrs(attrs), catalog: h.catalog}
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	return &logHandler{next: h.next.WithGroup(name), catalog: h.catalog}
+}
@@ -0,0 +1,159 @@
+// Package ical формирует календари iCalendar (RFC 5545) из еженедельного расписания.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	localFormat = "20060102T150405"
+	utcFormat   = "20060102T150405Z"
+	lineLimit   = 75
+)
+
+// Event — еженедельно повторяющееся занятие: первое вхождение Start–End,
+// повтор до Until включительно, кроме дат ExDates.
+type Event struct {
+	UID         string
+	Summary     string
+	Location    string
+	Description string
+	Start       time.Time
+	End         time.Time
+	Until       time.Time
+	ExDates     []time.Time
+}
+
+type Calendar struct {
+	Name     string
+	Location *time.Location
+	Events   []Event
+}
+
+// escape экранирует TEXT-значения (RFC 5545, 3.3.11).
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+type writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line пишет строку содержимого, сворачивая её по 75 октетов без разрыва UTF-8 символов.
+func (w *writer) line(format string, args ...any) {
+	if w.err != nil {
+		return
+	}
+	s := fmt.Sprintf(format, args...)
+
+	limit := lineLimit
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		if _, w.err = w.w.WriteString(s[:cut] + "\r\n "); w.err != nil {
+			return
+		}
+		s = s[cut:]
+		limit = lineLimit - 1
+	}
+	_, w.err = w.w.WriteString(s + "\r\n")
+}
+
+// timezone описывает часовой пояс одним STANDARD-блоком со смещением на момент at;
+// для поясов без перехода на летнее время этого достаточно.
+func (w *writer) timezone(loc *time.Location, at time.Time) {
+	name, offset := at.In(loc).Zone()
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	tz := fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset%3600/60)
+
+	w.line("BEGIN:VTIMEZONE")
+	w.line("TZID:%s", loc.String())
+	w.line("BEGIN:STANDARD")
+	w.line("DTSTART:19700101T000000")
+	w.line("TZOFFSETFROM:%s", tz)
+	w.line("TZOFFSETTO:%s", tz)
+	w.line("TZNAME:%s", escape(name))
+	w.line("END:STANDARD")
+	w.line("END:VTIMEZONE")
+}
+
+func (c Calendar) WriteTo(out io.Writer) (int64, error) {
+	counter := &countingWriter{w: out}
+	w := &writer{w: bufio.NewWriter(counter)}
+	loc := c.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	tzid := loc.String()
+	stamp := time.Now().UTC().Format(utcFormat)
+
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:-//hw_5_jwt//University Schedule//RU")
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	w.line("X-WR-CALNAME:%s", escape(c.Name))
+	w.line("X-WR-TIMEZONE:%s", tzid)
+
+	at := time.Now()
+	if len(c.Events) > 0 {
+		at = c.Events[0].Start
+	}
+	w.timezone(loc, at)
+
+	for _, e := range c.Events {
+		w.line("BEGIN:VEVENT")
+		w.line("UID:%s", e.UID)
+		w.line("DTSTAMP:%s", stamp)
+		w.line("DTSTART;TZID=%s:%s", tzid, e.Start.In(loc).Format(localFormat))
+		w.line("DTEND;TZID=%s:%s", tzid, e.End.In(loc).Format(localFormat))
+		// при DTSTART с TZID значение UNTIL должно быть в UTC
+		w.line("RRULE:FREQ=WEEKLY;UNTIL=%s", e.Until.UTC().Format(utcFormat))
+		for _, ex := range e.ExDates {
+			w.line("EXDATE;TZID=%s:%s", tzid, ex.In(loc).Format(localFormat))
+		}
+		w.line("SUMMARY:%s", escape(e.Summary))
+		if e.Location != "" {
+			w.line("LOCATION:%s", escape(e.Location))
+		}
+		if e.Description != "" {
+			w.line("DESCRIPTION:%s", escape(e.Description))
+		}
+		w.line("END:VEVENT")
+	}
+
+	w.line("END:VCALENDAR")
+
+	if w.err == nil {
+		w.err = w.w.Flush()
+	}
+	return counter.n, w.err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
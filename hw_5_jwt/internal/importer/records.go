@@ -0,0 +1,329 @@
+package importer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	KindGroups   = "groups"
+	KindStudents = "students"
+	KindSchedule = "schedule"
+)
+
+// Fields — поля каждого вида импорта; обязательные отмечены true.
+var Fields = map[string][]Field{
+	KindGroups: {
+		{"group_name", true},
+		{"faculty", false},
+	},
+	KindStudents: {
+		{"name", true},
+		{"surname", true},
+		{"birthday", true},
+		{"group_name", true},
+		{"gender", false},
+		{"email", false},
+	},
+	KindSchedule: {
+		{"group_name", true},
+		{"subject_name", true},
+		{"day_of_week", true},
+		{"start_time", true},
+		{"end_time", true},
+		{"lesson_name", false},
+		{"room", false},
+	},
+}
+
+type Field struct {
+	Name     string
+	Required bool
+}
+
+// Row — строка файла после сопоставления колонок; Line — номер строки в файле.
+type Row struct {
+	Line   int
+	Values map[string]string
+}
+
+type Group struct {
+	Line      int
+	GroupName string
+	Faculty   string
+}
+
+type Student struct {
+	Line      int
+	Name      string
+	Surname   string
+	Gender    string
+	Birthday  time.Time
+	GroupName string
+	// Email — учётная запись студента: импорт дополняет её карточку, созданную при регистрации
+	Email string
+}
+
+type ScheduleRow struct {
+	Line        int
+	GroupName   string
+	SubjectName string
+	LessonName  string
+	DayOfWeek   int
+	StartTime   string
+	EndTime     string
+	Room        string
+}
+
+// Map сопоставляет колонки полям. mapping задаёт поле → заголовок колонки;
+// поля без сопоставления ищутся по заголовку, совпадающему с именем поля.
+func Map(kind string, table Table, mapping map[string]string) ([]Row, error) {
+	fields, ok := Fields[kind]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный вид импорта: %s", kind)
+	}
+	if len(table) == 0 {
+		return nil, fmt.Errorf("файл пустой")
+	}
+
+	headers := map[string]int{}
+	for i, h := range table[0] {
+		headers[normalizeHeader(h)] = i
+	}
+
+	for field := range mapping {
+		known := false
+		for _, f := range fields {
+			known = known || f.Name == field
+		}
+		if !known {
+			return nil, fmt.Errorf("неизвестное поле в сопоставлении: %s", field)
+		}
+	}
+
+	columns := map[string]int{}
+	var missing []string
+	for _, f := range fields {
+		header := f.Name
+		if h, ok := mapping[f.Name]; ok {
+			header = h
+		}
+		idx, ok := headers[normalizeHeader(header)]
+		if !ok {
+			if f.Required {
+				missing = append(missing, fmt.Sprintf("%s (колонка %q)", f.Name, header))
+			}
+			continue
+		}
+		columns[f.Name] = idx
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("не найдены колонки для обязательных полей: %s", strings.Join(missing, ", "))
+	}
+
+	rows := make([]Row, 0, len(table)-1)
+	for i, record := range table[1:] {
+		row := Row{Line: i + 2, Values: map[string]string{}}
+		for field, idx := range columns {
+			if idx < len(record) {
+				row.Values[field] = strings.TrimSpace(record[idx])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func normalizeHeader(h string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+}
+
+func required(row Row, kind string) []string {
+	var errs []string
+	for _, f := range Fields[kind] {
+		if f.Required && row.Values[f.Name] == "" {
+			errs = append(errs, fmt.Sprintf("%s: обязательное поле", f.Name))
+		}
+	}
+	return errs
+}
+
+func ParseGroups(rows []Row, report *Report) []Group {
+	var out []Group
+	seen := map[string]int{}
+	for _, row := range rows {
+		errs := required(row, KindGroups)
+		g := Group{Line: row.Line, GroupName: row.Values["group_name"], Faculty: row.Values["faculty"]}
+
+		key := NaturalKey(g.GroupName)
+		if line, dup := seen[key]; dup && key != "" {
+			errs = append(errs, fmt.Sprintf("группа повторяется в строке %d", line))
+		}
+		seen[key] = row.Line
+
+		if len(errs) > 0 {
+			report.Fail(row.Line, g.GroupName, errs...)
+			continue
+		}
+		out = append(out, g)
+	}
+	return out
+}
+
+func ParseStudents(rows []Row, report *Report) []Student {
+	var out []Student
+	seen := map[string]int{}
+	for _, row := range rows {
+		errs := required(row, KindStudents)
+		s := Student{
+			Line:      row.Line,
+			Name:      row.Values["name"],
+			Surname:   row.Values["surname"],
+			Gender:    row.Values["gender"],
+			GroupName: row.Values["group_name"],
+			Email:     row.Values["email"],
+		}
+
+		if v := row.Values["birthday"]; v != "" {
+			birthday, err := parseDate(v)
+			if err != nil {
+				errs = append(errs, "birthday: "+err.Error())
+			}
+			s.Birthday = birthday
+		}
+		if len([]rune(s.Gender)) > 10 {
+			errs = append(errs, "gender: не длиннее 10 символов")
+		}
+		if s.Email != "" && (!strings.Contains(s.Email, "@") || len(s.Email) > 255) {
+			errs = append(errs, "email: неверный адрес")
+		}
+
+		key := s.Key()
+		if line, dup := seen[NaturalKey(key)]; dup {
+			errs = append(errs, fmt.Sprintf("студент повторяется в строке %d", line))
+		}
+		seen[NaturalKey(key)] = row.Line
+
+		if len(errs) > 0 {
+			report.Fail(row.Line, key, errs...)
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func ParseSchedule(rows []Row, report *Report) []ScheduleRow {
+	var out []ScheduleRow
+	seen := map[string]int{}
+	for _, row := range rows {
+		errs := required(row, KindSchedule)
+		s := ScheduleRow{
+			Line:        row.Line,
+			GroupName:   row.Values["group_name"],
+			SubjectName: row.Values["subject_name"],
+			LessonName:  row.Values["lesson_name"],
+			Room:        row.Values["room"],
+		}
+
+		var err error
+		if v := row.Values["day_of_week"]; v != "" {
+			if s.DayOfWeek, err = parseDay(v); err != nil {
+				errs = append(errs, "day_of_week: "+err.Error())
+			}
+		}
+		if v := row.Values["start_time"]; v != "" {
+			if s.StartTime, err = parseTime(v); err != nil {
+				errs = append(errs, "start_time: "+err.Error())
+			}
+		}
+		if v := row.Values["end_time"]; v != "" {
+			if s.EndTime, err = parseTime(v); err != nil {
+				errs = append(errs, "end_time: "+err.Error())
+			}
+		}
+		if s.StartTime != "" && s.EndTime != "" && s.StartTime >= s.EndTime {
+			errs = append(errs, "start_time должен быть раньше end_time")
+		}
+
+		key := s.Key()
+		if line, dup := seen[NaturalKey(key)]; dup {
+			errs = append(errs, fmt.Sprintf("занятие повторяется в строке %d", line))
+		}
+		seen[NaturalKey(key)] = row.Line
+
+		if len(errs) > 0 {
+			report.Fail(row.Line, key, errs...)
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// NaturalKey нормализует название для сравнения без учёта регистра и пробелов.
+func NaturalKey(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// Key — естественный ключ студента: имя, фамилия и дата рождения.
+func (s Student) Key() string {
+	return fmt.Sprintf("%s %s %s", s.Surname, s.Name, s.Birthday.Format("02.01.2006"))
+}
+
+// Key — естественный ключ занятия: группа, предмет, день и время начала.
+func (s ScheduleRow) Key() string {
+	return fmt.Sprintf("%s / %s / %d %s", s.GroupName, s.SubjectName, s.DayOfWeek, s.StartTime)
+}
+
+// excelEpoch — нулевой день дат Excel (с учётом ошибки 1900 года).
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+func parseDate(v string) (time.Time, error) {
+	if serial, err := strconv.ParseFloat(v, 64); err == nil && serial > 0 && serial < 100000 {
+		return excelEpoch.AddDate(0, 0, int(serial)), nil
+	}
+	for _, layout := range []string{"02.01.2006", "2006-01-02", "02/01/2006", "2.1.2006"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("неверная дата %q, используйте DD.MM.YYYY", v)
+}
+
+func parseTime(v string) (string, error) {
+	// время в XLSX хранится долей суток
+	if frac, err := strconv.ParseFloat(v, 64); err == nil && frac >= 0 && frac < 1 {
+		total := int(math.Round(frac * 24 * 60))
+		return fmt.Sprintf("%02d:%02d", total/60, total%60), nil
+	}
+	for _, layout := range []string{"15:04", "15:04:05", "15.04"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.Format("15:04"), nil
+		}
+	}
+	return "", fmt.Errorf("неверное время %q, используйте HH:MM", v)
+}
+
+var dayNames = map[string]int{
+	"понедельник": 1, "пн": 1, "monday": 1, "mon": 1,
+	"вторник": 2, "вт": 2, "tuesday": 2, "tue": 2,
+	"среда": 3, "ср": 3, "wednesday": 3, "wed": 3,
+	"четверг": 4, "чт": 4, "thursday": 4, "thu": 4,
+	"пятница": 5, "пт": 5, "friday": 5, "fri": 5,
+	"суббота": 6, "сб": 6, "saturday": 6, "sat": 6,
+	"воскресенье": 7, "вс": 7, "sunday": 7, "sun": 7,
+}
+
+func parseDay(v string) (int, error) {
+	if n, err := strconv.Atoi(v); err == nil && n >= 1 && n <= 7 {
+		return n, nil
+	}
+	if n, ok := dayNames[strings.ToLower(v)]; ok {
+		return n, nil
+	}
+	return 0, fmt.Errorf("неверный день недели %q", v)
+}
@@ -0,0 +1,62 @@
+package importer
+
+import "sort"
+
+const (
+	ActionCreated   = "created"
+	ActionUpdated   = "updated"
+	ActionUnchanged = "unchanged"
+	ActionFailed    = "failed"
+)
+
+type RowResult struct {
+	Line   int      `json:"line"`
+	Key    string   `json:"key"`
+	Action string   `json:"action"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Report — итог проверки или импорта по строкам.
+type Report struct {
+	Kind      string      `json:"kind"`
+	DryRun    bool        `json:"dry_run"`
+	Committed bool        `json:"committed"`
+	Total     int         `json:"total"`
+	Created   int         `json:"created"`
+	Updated   int         `json:"updated"`
+	Unchanged int         `json:"unchanged"`
+	Failed    int         `json:"failed"`
+	Rows      []RowResult `json:"rows"`
+}
+
+func NewReport(kind string, dryRun bool, total int) *Report {
+	return &Report{Kind: kind, DryRun: dryRun, Total: total, Rows: []RowResult{}}
+}
+
+func (r *Report) Fail(line int, key string, errs ...string) {
+	r.Failed++
+	r.Rows = append(r.Rows, RowResult{Line: line, Key: key, Action: ActionFailed, Errors: errs})
+}
+
+func (r *Report) Done(line int, key, action string) {
+	switch action {
+	case ActionCreated:
+		r.Created++
+	case ActionUpdated:
+		r.Updated++
+	case ActionUnchanged:
+		r.Unchanged++
+	}
+	r.Rows = append(r.Rows, RowResult{Line: line, Key: key, Action: action})
+}
+
+func (r *Report) HasErrors() bool {
+	return r.Failed > 0
+}
+
+// Sort упорядочивает результаты по номеру строки файла.
+func (r *Report) Sort() {
+	sort.SliceStable(r.Rows, func(i, j int) bool {
+		return r.Rows[i].Line < r.Rows[j].Line
+	})
+}
@@ -0,0 +1,47 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidFile — файл не читается или колонки не сопоставляются; строки не проверялись.
+var ErrInvalidFile = errors.New("неверный файл импорта")
+
+type Store interface {
+	ImportGroups(ctx context.Context, groups []Group, report *Report) error
+	ImportStudents(ctx context.Context, students []Student, report *Report) error
+	ImportSchedule(ctx context.Context, rows []ScheduleRow, report *Report) error
+}
+
+// Run проверяет файл и импортирует его. Строки с ошибками разбора не отменяют
+// проверку остальных по базе, так что отчёт сразу содержит все ошибки; записи
+// фиксируются только при полностью корректном файле и dryRun == false.
+func Run(ctx context.Context, store Store, kind string, data []byte, mapping map[string]string, dryRun bool) (*Report, error) {
+	table, err := ReadTable(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	rows, err := Map(kind, table, mapping)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	report := NewReport(kind, dryRun, len(rows))
+	switch kind {
+	case KindGroups:
+		err = store.ImportGroups(ctx, ParseGroups(rows, report), report)
+	case KindStudents:
+		err = store.ImportStudents(ctx, ParseStudents(rows, report), report)
+	case KindSchedule:
+		err = store.ImportSchedule(ctx, ParseSchedule(rows, report), report)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report.Sort()
+	return report, nil
+}
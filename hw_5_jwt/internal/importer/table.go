@@ -0,0 +1,196 @@
+// Package importer читает CSV и XLSX, сопоставляет колонки полям и проверяет строки
+// до записи в базу.
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Table — строки файла; первая строка содержит заголовки.
+type Table [][]string
+
+// ReadTable определяет формат по содержимому: XLSX — zip-архив, иначе CSV.
+func ReadTable(data []byte) (Table, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return readXLSX(data)
+	}
+	return readCSV(data)
+}
+
+func readCSV(data []byte) (Table, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	// Excel в русской локали сохраняет CSV через точку с запятой
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	comma := ','
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		comma = ';'
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения CSV: %w", err)
+	}
+	return trimEmpty(rows), nil
+}
+
+func trimEmpty(rows [][]string) Table {
+	out := make(Table, 0, len(rows))
+	for _, row := range rows {
+		empty := true
+		for _, cell := range row {
+			if strings.TrimSpace(cell) != "" {
+				empty = false
+				break
+			}
+		}
+		if !empty {
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+type xlsxSharedStrings struct {
+	Items []struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline struct {
+				Text string `xml:"t"`
+			} `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX читает первый лист книги. Даты и время остаются числами Excel,
+// их разбирают parseDate и parseTime.
+func readXLSX(data []byte) (Table, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения XLSX: %w", err)
+	}
+
+	files := map[string]*zip.File{}
+	var sheets []string
+	for _, f := range zr.File {
+		files[f.Name] = f
+		if path.Dir(f.Name) == "xl/worksheets" && strings.HasSuffix(f.Name, ".xml") {
+			sheets = append(sheets, f.Name)
+		}
+	}
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("ошибка чтения XLSX: в книге нет листов")
+	}
+	sort.Slice(sheets, func(i, j int) bool {
+		return sheetNumber(sheets[i]) < sheetNumber(sheets[j])
+	})
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst xlsxSharedStrings
+		if err := decodeZipXML(f, &sst); err != nil {
+			return nil, err
+		}
+		for _, si := range sst.Items {
+			text := si.Text
+			for _, r := range si.Runs {
+				text += r.Text
+			}
+			shared = append(shared, text)
+		}
+	}
+
+	var sheet xlsxSheet
+	if err := decodeZipXML(files[sheets[0]], &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, xr := range sheet.Rows {
+		var row []string
+		for i, c := range xr.Cells {
+			col := i
+			if c.Ref != "" {
+				col = columnIndex(c.Ref)
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(c.Value)
+				if err != nil || idx < 0 || idx >= len(shared) {
+					return nil, fmt.Errorf("ошибка чтения XLSX: неверная ссылка на строку в ячейке %s", c.Ref)
+				}
+				row[col] = shared[idx]
+			case "inlineStr":
+				row[col] = c.Inline.Text
+			default:
+				row[col] = c.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return trimEmpty(rows), nil
+}
+
+func decodeZipXML(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("ошибка чтения XLSX: %w", err)
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(rc, 64<<20)).Decode(v); err != nil {
+		return fmt.Errorf("ошибка разбора XLSX (%s): %w", f.Name, err)
+	}
+	return nil
+}
+
+func sheetNumber(name string) int {
+	base := strings.TrimSuffix(path.Base(name), ".xml")
+	n, err := strconv.Atoi(strings.TrimPrefix(base, "sheet"))
+	if err != nil {
+		return 1 << 30
+	}
+	return n
+}
+
+// columnIndex переводит ссылку вида "AB12" в номер колонки с нуля.
+func columnIndex(ref string) int {
+	col := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A') + 1
+	}
+	return col - 1
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware считает запросы и время ответа по шаблону маршрута (c.Path()), а не по
+// URI, чтобы ID в пути не размножали ряды. Подключается раньше RequestLogger: к
+// возврату из него ошибка уже отдана HandleHTTPError и статус ответа окончательный.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			started := time.Now()
+			err := next(c)
+
+			status := c.Response().Status
+			if err != nil && !c.Response().Committed {
+				status = http.StatusInternalServerError
+				var he *echo.HTTPError
+				if errors.As(err, &he) {
+					status = he.Code
+				}
+			}
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+
+			labels := []string{c.Request().Method, route, strconv.Itoa(status)}
+			httpRequests.WithLabelValues(labels...).Inc()
+			httpDuration.WithLabelValues(labels...).Observe(time.Since(started).Seconds())
+			return err
+		}
+	}
+}
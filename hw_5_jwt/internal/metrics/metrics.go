@@ -0,0 +1,106 @@
+// Package metrics — метрики Prometheus: HTTP-запросы, пул соединений и запросы
+// к базе по методам Repository, входы и записи посещаемости.
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "university"
+
+// Registry — отдельный реестр вместо глобального, чтобы в /metrics попадало только
+// зарегистрированное здесь.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP-запросы по шаблону маршрута и статусу.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Время обработки HTTP-запроса по шаблону маршрута и статусу.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Время запросов к базе по методам Repository.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"method"})
+
+	queryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "Запросы к базе, завершившиеся ошибкой, по методам Repository.",
+	}, []string{"method"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_logins_total",
+		Help:      "Попытки входа: result = success или failure.",
+	}, []string{"result"})
+
+	// записей в минуту: rate(university_attendance_writes_total[5m]) * 60
+	attendanceWrites = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "attendance_writes_total",
+		Help:      "Записанные отметки посещаемости по источнику: mark, rollcall, checkin.",
+	}, []string{"source"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, queryDuration, queryErrors, logins, attendanceWrites,
+	)
+	// нулевые ряды, чтобы rate() и алерты видели метрику до первого события
+	for _, result := range []string{"success", "failure"} {
+		logins.WithLabelValues(result)
+	}
+	for _, source := range []string{"mark", "rollcall", "checkin"} {
+		attendanceWrites.WithLabelValues(source)
+	}
+}
+
+// Login учитывает попытку входа.
+func Login(ok bool) {
+	result := "failure"
+	if ok {
+		result = "success"
+	}
+	logins.WithLabelValues(result).Inc()
+}
+
+// AttendanceWritten учитывает n записанных отметок.
+func AttendanceWritten(source string, n int) {
+	attendanceWrites.WithLabelValues(source).Add(float64(n))
+}
+
+// Handler отдаёт метрики в формате Prometheus. Если token не пуст, нужен заголовок
+// Authorization: Bearer <token>.
+func Handler(token string) http.Handler {
+	next := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+	if token == "" {
+		return next
+	}
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,162 @@
+package metrics
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"hw_5_jwt/internal/postgres"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	repositoryPackage = reflect.TypeFor[postgres.Repository]().PkgPath()
+	repositoryPrefix  = repositoryPackage + ".(*Repository)."
+)
+
+// QueryTracer замеряет запросы pgx и относит их к методу Repository, из которого
+// они выполнены: pgxpool.Config.ConnConfig.Tracer = metrics.QueryTracer{}. Пакет
+// SendBatch учитывается как один запрос от начала отправки до закрытия результатов.
+type QueryTracer struct{}
+
+var (
+	_ pgx.QueryTracer = QueryTracer{}
+	_ pgx.BatchTracer = QueryTracer{}
+)
+
+type queryKey struct{}
+
+type queryStart struct {
+	method  string
+	started time.Time
+}
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryKey{}, queryStart{method: repositoryMethod(), started: time.Now()})
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	queryDuration.WithLabelValues(start.method).Observe(time.Since(start.started).Seconds())
+	if data.Err != nil {
+		queryErrors.WithLabelValues(start.method).Inc()
+	}
+}
+
+type batchKey struct{}
+
+// batchStart — как queryStart, но ошибку отдельного запроса пакета нужно запомнить
+// до TraceBatchEnd, поэтому в контексте лежит указатель.
+type batchStart struct {
+	queryStart
+	failed bool
+}
+
+func (QueryTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	return context.WithValue(ctx, batchKey{}, &batchStart{queryStart: queryStart{method: repositoryMethod(), started: time.Now()}})
+}
+
+func (QueryTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	if start, ok := ctx.Value(batchKey{}).(*batchStart); ok && data.Err != nil {
+		start.failed = true
+	}
+}
+
+func (QueryTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	start, ok := ctx.Value(batchKey{}).(*batchStart)
+	if !ok {
+		return
+	}
+	queryDuration.WithLabelValues(start.method).Observe(time.Since(start.started).Seconds())
+	if start.failed || data.Err != nil {
+		queryErrors.WithLabelValues(start.method).Inc()
+	}
+}
+
+// repositoryMethod ищет в стеке внешний метод Repository: запросы вспомогательных
+// методов и замыканий учитываются в вызвавшем их публичном методе.
+func repositoryMethod() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+
+	method := ""
+	for {
+		frame, more := frames.Next()
+		if name, ok := strings.CutPrefix(frame.Function, repositoryPrefix); ok {
+			method, _, _ = strings.Cut(name, ".")
+		} else if method != "" && !strings.HasPrefix(frame.Function, repositoryPackage+".") {
+			break
+		}
+		if !more {
+			break
+		}
+	}
+	if method == "" {
+		return "other"
+	}
+	return method
+}
+
+// poolCollector снимает pgxpool.Stat при каждом сборе метрик.
+type poolCollector struct {
+	pool *pgxpool.Pool
+}
+
+// RegisterPool добавляет в реестр состояние пула соединений.
+func RegisterPool(pool *pgxpool.Pool) {
+	Registry.MustRegister(poolCollector{pool: pool})
+}
+
+func poolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+}
+
+var (
+	poolAcquiredConns    = poolDesc("acquired_conns", "Соединения, занятые запросами.")
+	poolIdleConns        = poolDesc("idle_conns", "Свободные соединения.")
+	poolConstructing     = poolDesc("constructing_conns", "Соединения, которые сейчас открываются.")
+	poolTotalConns       = poolDesc("total_conns", "Все соединения пула.")
+	poolMaxConns         = poolDesc("max_conns", "Предел размера пула.")
+	poolAcquires         = poolDesc("acquires_total", "Выданные соединения.")
+	poolAcquireSeconds   = poolDesc("acquire_duration_seconds_total", "Суммарное ожидание соединения.")
+	poolEmptyAcquires    = poolDesc("empty_acquires_total", "Выдачи, которым пришлось ждать соединения.")
+	poolCanceledAcquires = poolDesc("canceled_acquires_total", "Ожидания соединения, отменённые контекстом.")
+	poolNewConns         = poolDesc("new_conns_total", "Открытые соединения.")
+	poolLifetimeDestroys = poolDesc("max_lifetime_destroys_total", "Соединения, закрытые по MaxConnLifetime.")
+	poolIdleDestroys     = poolDesc("max_idle_destroys_total", "Соединения, закрытые по MaxConnIdleTime.")
+)
+
+func (c poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	gauge := func(desc *prometheus.Desc, v int32) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(v))
+	}
+	counter := func(desc *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, v)
+	}
+
+	gauge(poolAcquiredConns, stat.AcquiredConns())
+	gauge(poolIdleConns, stat.IdleConns())
+	gauge(poolConstructing, stat.ConstructingConns())
+	gauge(poolTotalConns, stat.TotalConns())
+	gauge(poolMaxConns, stat.MaxConns())
+	counter(poolAcquires, float64(stat.AcquireCount()))
+	counter(poolAcquireSeconds, stat.AcquireDuration().Seconds())
+	counter(poolEmptyAcquires, float64(stat.EmptyAcquireCount()))
+	counter(poolCanceledAcquires, float64(stat.CanceledAcquireCount()))
+	counter(poolNewConns, float64(stat.NewConnsCount()))
+	counter(poolLifetimeDestroys, float64(stat.MaxLifetimeDestroyCount()))
+	counter(poolIdleDestroys, float64(stat.MaxIdleDestroyCount()))
+}
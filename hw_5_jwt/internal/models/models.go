@@ -23,30 +23,70 @@ type User struct {
 	CreatedAt time.Time      `json:"created_at" db:"created_at"`
 	Role      string         `json:"role,omitempty" db:"role"`
 	Status    sql.NullString `json:"status,omitempty" db:"status"`
+	Language  string         `json:"language,omitempty" db:"language"`
 }
 
 type SetInfoToTeacher struct {
-	TeacherID int `json:"teacher_id" validate:"required"`
-	SubjectID int `json:"subject_id" validate:"required"`
+	TeacherID int `json:"teacher_id" param:"id" validate:"required,gt=0"`
+	SubjectID int `json:"subject_id" validate:"required,gt=0"`
 }
+
+// RegisterRequest — самостоятельная регистрация, только студентом; учителей и
+// администраторов создаёт администратор через CreateUserRequest.
 type RegisterRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Role     string `json:"role, omitempty"`
-	Name     string `json:"name"`
-	Surname  string `json:"surname"`
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,min=6,max=72"`
+	Role     string `json:"role,omitempty" validate:"omitempty,oneof=student"`
+	Name     string `json:"name" validate:"max=100"`
+	Surname  string `json:"surname" validate:"max=100"`
+	Language string `json:"language,omitempty" validate:"omitempty,oneof=ru kk en"`
+}
+
+type CreateUserRequest struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,min=6,max=72"`
+	Role     string `json:"role" validate:"required,oneof=student teacher admin"`
+	Name     string `json:"name" validate:"max=100"`
+	Surname  string `json:"surname" validate:"max=100"`
+	Language string `json:"language,omitempty" validate:"omitempty,oneof=ru kk en"`
+}
+
+// LanguageRequest — язык сообщений API, сохраняемый в профиле пользователя.
+type LanguageRequest struct {
+	Language string `json:"language" validate:"required,oneof=ru kk en"`
 }
 
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 type ServerResponse struct {
 	Status  string      `json:"status"`
 	Message string      `json:"message,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+}
+
+// Problem — тело ошибки по RFC 7807 (application/problem+json). Code — стабильный
+// идентификатор ошибки для клиентов, detail — его текст на языке запроса.
+// Подробности внутренних ошибок в ответ не попадают, только в журнал.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	Data      interface{}  `json:"data,omitempty"`
+}
+
+// FieldError — поле запроса, не прошедшее проверку: код правила и текст для пользователя.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 type Schedule struct {
@@ -66,26 +106,45 @@ type Attendance struct {
 	AttendanceDate time.Time `json:"attendance_date"`
 	IsPresent      bool      `json:"is_present"`
 }
+
+const (
+	AttendancePresent = "present"
+	AttendanceLate    = "late"
+	AttendanceAbsent  = "absent"
+	AttendanceExcused = "excused"
+	AttendanceRemote  = "remote"
+)
+
 type AttendanceRequest struct {
-	ScheduleID int    `json:"schedule_id"`
-	VisitDay   string `json:"visit_day"`
-	Visited    bool   `json:"visited"`
-	StudentID  int    `json:"student_id"`
+	ScheduleID  int    `json:"schedule_id" validate:"required,gt=0"`
+	VisitDay    string `json:"visit_day" validate:"required,date"`
+	Visited     bool   `json:"visited"`
+	StudentID   int    `json:"student_id" validate:"required,gt=0"`
+	Status      string `json:"status,omitempty" validate:"omitempty,oneof=present late absent excused remote"`
+	MinutesLate *int   `json:"minutes_late,omitempty" validate:"omitempty,gte=0"`
+	Note        string `json:"note,omitempty" validate:"max=500"`
 }
 type AttendanceBySubject struct {
-	StudentID      int    `json:"student_id"`
-	StudentName    string `json:"student_name"`
-	StudentSurname string `json:"student_surname"`
-	GroupName      string `json:"group_name"`
-	VisitDay       string `json:"visit_day"`
-	Visited        bool   `json:"visited"`
+	StudentID      int     `json:"student_id"`
+	StudentName    string  `json:"student_name"`
+	StudentSurname string  `json:"student_surname"`
+	GroupName      string  `json:"group_name"`
+	VisitDay       string  `json:"visit_day"`
+	Visited        bool    `json:"visited"`
+	Status         string  `json:"status"`
+	MinutesLate    *int    `json:"minutes_late,omitempty"`
+	Note           *string `json:"note,omitempty"`
 }
 
 type AttendanceByStudent struct {
-	SubjectID   int    `json:"subject_id"`
-	SubjectName string `json:"subject_name"`
-	VisitDay    string `json:"visit_day"`
-	Visited     bool   `json:"visited"`
+	AttendanceID int     `json:"attendance_id"`
+	SubjectID    int     `json:"subject_id"`
+	SubjectName  string  `json:"subject_name"`
+	VisitDay     string  `json:"visit_day"`
+	Visited      bool    `json:"visited"`
+	Status       string  `json:"status"`
+	MinutesLate  *int    `json:"minutes_late,omitempty"`
+	Note         *string `json:"note,omitempty"`
 }
 
 type Student struct {
@@ -103,3 +162,718 @@ type Group struct {
 	GroupName string `json:"name"`
 	Faculty   string `json:"department"`
 }
+
+type BulkAttendanceRequest struct {
+	ScheduleID int                     `json:"schedule_id" validate:"required,gt=0"`
+	VisitDay   string                  `json:"visit_day" validate:"required,date"`
+	Students   []BulkAttendanceStudent `json:"students" validate:"required,min=1,dive"`
+}
+
+type BulkAttendanceStudent struct {
+	StudentID   int    `json:"student_id" validate:"required,gt=0"`
+	Visited     bool   `json:"visited"`
+	Status      string `json:"status,omitempty" validate:"omitempty,oneof=present late absent excused remote"`
+	MinutesLate *int   `json:"minutes_late,omitempty" validate:"omitempty,gte=0"`
+	Note        string `json:"note,omitempty" validate:"max=500"`
+}
+
+type BulkAttendanceResult struct {
+	StudentID int    `json:"student_id"`
+	Status    string `json:"status"`
+	Code      string `json:"code,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type RosterEntry struct {
+	StudentID      int    `json:"student_id"`
+	StudentName    string `json:"student_name"`
+	StudentSurname string `json:"student_surname"`
+	Visited        bool   `json:"visited"`
+	Status         string `json:"status"`
+	Marked         bool   `json:"marked"`
+}
+
+type LessonRoster struct {
+	ScheduleID int           `json:"schedule_id"`
+	GroupID    int           `json:"group_id"`
+	LessonName string        `json:"lesson_name"`
+	VisitDay   string        `json:"visit_day"`
+	Students   []RosterEntry `json:"students"`
+}
+
+const (
+	ExcusePending  = "pending"
+	ExcuseApproved = "approved"
+	ExcuseRejected = "rejected"
+)
+
+type ExcuseRequest struct {
+	Reason        string `json:"reason" form:"reason" validate:"required,notblank,max=2000"`
+	AttendanceIDs []int  `json:"attendance_ids" form:"attendance_ids" validate:"required,min=1,dive,gt=0"`
+}
+
+type ExcuseDecision struct {
+	Comment string `json:"comment" validate:"max=2000"`
+}
+
+type Excuse struct {
+	ExcuseID      int             `json:"excuse_id"`
+	StudentID     int             `json:"student_id"`
+	Reason        string          `json:"reason"`
+	Status        string          `json:"status"`
+	DocumentName  *string         `json:"document_name,omitempty"`
+	AttendanceIDs []int           `json:"attendance_ids"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	History       []ExcuseHistory `json:"history,omitempty"`
+}
+
+type ExcuseHistory struct {
+	Status    string    `json:"status"`
+	ChangedBy *int      `json:"changed_by,omitempty"`
+	Comment   *string   `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExcuseDocument struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+type CheckinWindowRequest struct {
+	ScheduleID       int `json:"schedule_id" validate:"required,gt=0"`
+	DurationMinutes  int `json:"duration_minutes" validate:"gte=0,lte=180"`
+	LateAfterMinutes int `json:"late_after_minutes" validate:"gte=0"`
+}
+
+type CheckinWindow struct {
+	WindowID   int        `json:"window_id"`
+	ScheduleID int        `json:"schedule_id"`
+	GroupID    int        `json:"group_id"`
+	VisitDay   string     `json:"visit_day"`
+	OpenedBy   int        `json:"opened_by"`
+	OpensAt    time.Time  `json:"opens_at"`
+	LateAfter  time.Time  `json:"late_after"`
+	ClosesAt   time.Time  `json:"closes_at"`
+	ClosedAt   *time.Time `json:"closed_at,omitempty"`
+	// LessonStart — начало занятия по расписанию, от него считается опоздание
+	LessonStart *time.Time `json:"lesson_start,omitempty"`
+	Secret      []byte     `json:"-"`
+}
+
+type CheckinCode struct {
+	WindowID  int       `json:"window_id"`
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+	QRPayload string    `json:"qr_payload"`
+}
+
+type CheckinRequest struct {
+	WindowID int    `json:"window_id" validate:"required,gt=0"`
+	Code     string `json:"code" validate:"required,notblank"`
+}
+
+type CheckinResult struct {
+	WindowID    int    `json:"window_id"`
+	StudentID   int    `json:"student_id"`
+	Status      string `json:"status"`
+	MinutesLate *int   `json:"minutes_late,omitempty"`
+}
+
+type StatsFilter struct {
+	From      time.Time
+	To        time.Time
+	GroupID   int
+	SubjectID int
+	StudentID int
+	Faculty   string
+}
+
+type AttendanceCounts struct {
+	Total   int      `json:"total"`
+	Present int      `json:"present"`
+	Late    int      `json:"late"`
+	Absent  int      `json:"absent"`
+	Excused int      `json:"excused"`
+	Remote  int      `json:"remote"`
+	Rate    *float64 `json:"attendance_rate"`
+}
+
+type StudentAttendanceStats struct {
+	StudentID      int    `json:"student_id"`
+	StudentName    string `json:"student_name"`
+	StudentSurname string `json:"student_surname"`
+	GroupName      string `json:"group_name"`
+	AttendanceCounts
+}
+
+type SubjectAttendanceStats struct {
+	SubjectID   int    `json:"subject_id"`
+	SubjectName string `json:"subject_name"`
+	AttendanceCounts
+}
+
+type GroupAttendanceStats struct {
+	GroupID   int    `json:"group_id"`
+	GroupName string `json:"group_name"`
+	Faculty   string `json:"faculty"`
+	AttendanceCounts
+}
+
+type FacultyAttendanceStats struct {
+	Faculty string `json:"faculty"`
+	AttendanceCounts
+}
+
+type WeeklyAttendanceStats struct {
+	WeekStart string `json:"week_start"`
+	AttendanceCounts
+}
+
+type AtRiskStudent struct {
+	StudentID      int    `json:"student_id"`
+	StudentName    string `json:"student_name"`
+	StudentSurname string `json:"student_surname"`
+	GroupName      string `json:"group_name"`
+	SubjectID      int    `json:"subject_id"`
+	SubjectName    string `json:"subject_name"`
+	AttendanceCounts
+}
+
+type AlertRule struct {
+	RuleID     int       `json:"rule_id"`
+	Name       string    `json:"name"`
+	Threshold  float64   `json:"threshold"`
+	MinLessons int       `json:"min_lessons"`
+	Channels   []string  `json:"channels"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type AlertRuleRequest struct {
+	Name       string   `json:"name" validate:"required,notblank,max=200"`
+	Threshold  float64  `json:"threshold" validate:"gt=0,lte=1"`
+	MinLessons int      `json:"min_lessons"`
+	Channels   []string `json:"channels" validate:"dive,oneof=inbox email webhook"`
+}
+
+type AlertEvent struct {
+	EventID        int      `json:"event_id"`
+	RuleID         int      `json:"rule_id"`
+	RuleName       string   `json:"rule_name"`
+	Threshold      float64  `json:"threshold"`
+	Channels       []string `json:"channels"`
+	StudentID      int      `json:"student_id"`
+	StudentName    string   `json:"student_name"`
+	StudentSurname string   `json:"student_surname"`
+	SubjectID      int      `json:"subject_id"`
+	SubjectName    string   `json:"subject_name"`
+	MissedRate     float64  `json:"missed_rate"`
+	Term           string   `json:"term"`
+}
+
+type AlertRecipient struct {
+	UserID   int    `json:"user_id"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	Language string `json:"language,omitempty"`
+}
+
+type Notification struct {
+	NotificationID int        `json:"notification_id"`
+	UserID         int        `json:"user_id"`
+	Kind           string     `json:"kind"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body"`
+	ReadAt         *time.Time `json:"read_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// StudentGroupRequest — группа, в которую администратор переводит студента.
+type StudentGroupRequest struct {
+	GroupID int `json:"group_id" validate:"required,gt=0"`
+}
+
+type CuratorRequest struct {
+	UserID int `json:"user_id" validate:"required,gt=0"`
+}
+
+const (
+	EventAttendanceRecorded = "attendance.recorded"
+	EventStudentCreated     = "student.created"
+	EventScheduleChanged    = "schedule.changed"
+	EventUserRegistered     = "user.registered"
+)
+
+type WebhookSubscriptionRequest struct {
+	URL    string   `json:"url" validate:"required,http_url"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=attendance.recorded student.created schedule.changed user.registered"`
+}
+
+type WebhookSubscription struct {
+	SubscriptionID int       `json:"subscription_id"`
+	URL            string    `json:"url"`
+	Secret         string    `json:"secret,omitempty"`
+	Events         []string  `json:"events"`
+	Active         bool      `json:"active"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	DeliveryID     int64     `json:"delivery_id"`
+	EventID        int64     `json:"event_id"`
+	SubscriptionID int       `json:"subscription_id"`
+	EventType      string    `json:"event_type"`
+	Status         string    `json:"status"`
+	Attempts       int       `json:"attempts"`
+	NextAttemptAt  time.Time `json:"next_attempt_at"`
+	LastStatusCode *int      `json:"last_status_code,omitempty"`
+	LastError      *string   `json:"last_error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// OutboundWebhook — доставка, захваченная воркером для отправки.
+type OutboundWebhook struct {
+	DeliveryID int64
+	EventID    int64
+	Attempts   int
+	URL        string
+	Secret     string
+	EventType  string
+	Payload    []byte
+	CreatedAt  time.Time
+}
+
+type AttendanceRecordedEvent struct {
+	StudentID   int    `json:"student_id"`
+	ScheduleID  int    `json:"schedule_id"`
+	VisitDay    string `json:"visit_day"`
+	Status      string `json:"status"`
+	Visited     bool   `json:"visited"`
+	MinutesLate *int   `json:"minutes_late,omitempty"`
+}
+
+// AttendanceStreamEvent — отметка в потоке посещаемости: событие outbox с предметом,
+// группой и именем студента (функция attendance_stream_event в schema.sql).
+type AttendanceStreamEvent struct {
+	EventID    int64     `json:"event_id"`
+	SubjectID  int       `json:"subject_id"`
+	GroupID    int       `json:"group_id"`
+	Name       string    `json:"name"`
+	Surname    string    `json:"surname"`
+	RecordedAt time.Time `json:"recorded_at"`
+	AttendanceRecordedEvent
+}
+
+// StreamMessage — сообщение WebSocket-потока: событие или проверка связи (type = ping).
+type StreamMessage struct {
+	Type string                 `json:"type"`
+	ID   int64                  `json:"id,omitempty"`
+	Data *AttendanceStreamEvent `json:"data,omitempty"`
+}
+
+const (
+	AssessmentExam    = "exam"
+	AssessmentMidterm = "midterm"
+	AssessmentLab     = "lab"
+	AssessmentQuiz    = "quiz"
+)
+
+type AssessmentRequest struct {
+	SubjectID int     `json:"subject_id" validate:"required,gt=0"`
+	GroupID   int     `json:"group_id" validate:"required,gt=0"`
+	Kind      string  `json:"kind" validate:"required,oneof=exam midterm lab quiz"`
+	Title     string  `json:"title" validate:"required,notblank,max=200"`
+	Term      string  `json:"term" validate:"omitempty,term"`
+	Weight    float64 `json:"weight" validate:"gt=0"`
+	MaxScore  float64 `json:"max_score" validate:"gt=0"`
+	HeldOn    string  `json:"held_on,omitempty" validate:"omitempty,date"`
+}
+
+type Assessment struct {
+	AssessmentID int       `json:"assessment_id"`
+	SubjectID    int       `json:"subject_id"`
+	GroupID      int       `json:"group_id"`
+	Kind         string    `json:"kind"`
+	Title        string    `json:"title"`
+	Term         string    `json:"term"`
+	Weight       float64   `json:"weight"`
+	MaxScore     float64   `json:"max_score"`
+	HeldOn       *string   `json:"held_on,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type GradeRequest struct {
+	StudentID int     `json:"student_id" param:"student_id" validate:"required,gt=0"`
+	Score     float64 `json:"score" validate:"gte=0"`
+	Comment   string  `json:"comment,omitempty" validate:"max=1000"`
+}
+
+type BulkGradeRequest struct {
+	Grades []GradeRequest `json:"grades" validate:"required,min=1,dive"`
+}
+
+type BulkGradeResult struct {
+	StudentID int    `json:"student_id"`
+	Status    string `json:"status"`
+	Code      string `json:"code,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type Grade struct {
+	AssessmentID   int       `json:"assessment_id"`
+	StudentID      int       `json:"student_id"`
+	StudentName    string    `json:"student_name,omitempty"`
+	StudentSurname string    `json:"student_surname,omitempty"`
+	SubjectID      int       `json:"subject_id,omitempty"`
+	SubjectName    string    `json:"subject_name,omitempty"`
+	Kind           string    `json:"kind,omitempty"`
+	Title          string    `json:"title,omitempty"`
+	Term           string    `json:"term,omitempty"`
+	Weight         float64   `json:"weight,omitempty"`
+	MaxScore       float64   `json:"max_score,omitempty"`
+	Score          float64   `json:"score"`
+	Comment        *string   `json:"comment,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type GradeFilter struct {
+	StudentID    int
+	SubjectID    int
+	GroupID      int
+	AssessmentID int
+	Term         string
+}
+
+type FinalGrade struct {
+	StudentID      int     `json:"student_id"`
+	StudentName    string  `json:"student_name"`
+	StudentSurname string  `json:"student_surname"`
+	SubjectID      int     `json:"subject_id"`
+	SubjectName    string  `json:"subject_name"`
+	Credits        int     `json:"credits"`
+	Term           string  `json:"term"`
+	Percent        float64 `json:"percent"`
+	GradedWeight   float64 `json:"graded_weight"`
+	TotalWeight    float64 `json:"total_weight"`
+	Letter         string  `json:"letter"`
+	GPAPoints      float64 `json:"gpa_points"`
+}
+
+type GradingScaleEntry struct {
+	Letter     string  `json:"letter" validate:"required,notblank,max=3"`
+	MinPercent float64 `json:"min_percent" validate:"gte=0,lte=100"`
+	GPAPoints  float64 `json:"gpa_points" validate:"gte=0"`
+}
+
+type StudentGrades struct {
+	StudentID int          `json:"student_id"`
+	Grades    []Grade      `json:"grades"`
+	Finals    []FinalGrade `json:"finals"`
+}
+
+type SubjectCreditsRequest struct {
+	Credits int `json:"credits" validate:"required,gt=0"`
+}
+
+type TranscriptEntry struct {
+	SubjectID   int     `json:"subject_id"`
+	SubjectName string  `json:"subject_name"`
+	Credits     int     `json:"credits"`
+	Percent     float64 `json:"percent"`
+	Letter      string  `json:"letter"`
+	GPAPoints   float64 `json:"gpa_points"`
+	InProgress  bool    `json:"in_progress"`
+}
+
+type TranscriptTerm struct {
+	Term     string            `json:"term"`
+	Subjects []TranscriptEntry `json:"subjects"`
+	Credits  int               `json:"credits"`
+	GPA      float64           `json:"gpa"`
+}
+
+type Transcript struct {
+	StudentID     int              `json:"student_id"`
+	Name          string           `json:"name"`
+	Surname       string           `json:"surname"`
+	GroupName     string           `json:"group_name"`
+	Terms         []TranscriptTerm `json:"terms"`
+	TotalCredits  int              `json:"total_credits"`
+	CumulativeGPA float64          `json:"cumulative_gpa"`
+	GeneratedAt   time.Time        `json:"generated_at"`
+}
+
+const (
+	EnrollmentEnrolled   = "enrolled"
+	EnrollmentWaitlisted = "waitlisted"
+	EnrollmentDropped    = "dropped"
+)
+
+type OfferingRequest struct {
+	SubjectID int    `json:"subject_id" validate:"required,gt=0"`
+	GroupID   int    `json:"group_id" validate:"required,gt=0"`
+	Term      string `json:"term" validate:"required,term"`
+	Capacity  *int   `json:"capacity,omitempty" validate:"omitempty,gt=0"`
+}
+
+type Offering struct {
+	OfferingID  int       `json:"offering_id"`
+	SubjectID   int       `json:"subject_id"`
+	SubjectName string    `json:"subject_name"`
+	GroupID     int       `json:"group_id"`
+	GroupName   string    `json:"group_name"`
+	Term        string    `json:"term"`
+	Capacity    *int      `json:"capacity,omitempty"`
+	Enrolled    int       `json:"enrolled"`
+	Waitlisted  int       `json:"waitlisted"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type EnrollmentRequest struct {
+	StudentID int `json:"student_id" validate:"gte=0"`
+}
+
+type EnrollGroupRequest struct {
+	GroupID int `json:"group_id" validate:"gte=0"`
+}
+
+type Enrollment struct {
+	EnrollmentID     int       `json:"enrollment_id"`
+	OfferingID       int       `json:"offering_id"`
+	StudentID        int       `json:"student_id"`
+	StudentName      string    `json:"student_name"`
+	StudentSurname   string    `json:"student_surname"`
+	SubjectID        int       `json:"subject_id"`
+	SubjectName      string    `json:"subject_name"`
+	Term             string    `json:"term"`
+	Status           string    `json:"status"`
+	WaitlistPosition int       `json:"waitlist_position,omitempty"`
+	RequestedAt      time.Time `json:"requested_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type DropResult struct {
+	Enrollment *Enrollment `json:"enrollment"`
+	Promoted   *Enrollment `json:"promoted,omitempty"`
+}
+
+const (
+	AssignmentLecturer     = "lecturer"
+	AssignmentLabAssistant = "lab_assistant"
+)
+
+type TeacherAssignmentRequest struct {
+	SubjectID int    `json:"subject_id" validate:"required,gt=0"`
+	GroupID   int    `json:"group_id" validate:"required,gt=0"`
+	Term      string `json:"term" validate:"omitempty,term"`
+	Role      string `json:"role" validate:"omitempty,oneof=lecturer lab_assistant"`
+}
+
+type TeacherAssignment struct {
+	AssignmentID int       `json:"assignment_id"`
+	TeacherID    int       `json:"teacher_id"`
+	SubjectID    int       `json:"subject_id"`
+	SubjectName  string    `json:"subject_name"`
+	GroupID      int       `json:"group_id"`
+	GroupName    string    `json:"group_name"`
+	Term         string    `json:"term"`
+	Role         string    `json:"role"`
+	WeeklyHours  float64   `json:"weekly_hours"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type TeacherLoad struct {
+	TeacherID      int     `json:"teacher_id"`
+	Name           *string `json:"name"`
+	Surname        *string `json:"surname"`
+	Term           string  `json:"term"`
+	Assignments    int     `json:"assignments"`
+	WeeklyHours    float64 `json:"weekly_hours"`
+	MaxWeeklyHours float64 `json:"max_weekly_hours"`
+	Overloaded     bool    `json:"overloaded"`
+}
+
+type TeacherMaxLoadRequest struct {
+	MaxWeeklyHours float64 `json:"max_weekly_hours" validate:"gt=0,lt=1000"`
+}
+
+type RoomRequest struct {
+	Name      string   `json:"name" validate:"required,notblank,max=100"`
+	Building  string   `json:"building" validate:"required,notblank,max=100"`
+	Capacity  int      `json:"capacity" validate:"required,gt=0"`
+	Equipment []string `json:"equipment"`
+}
+
+type Room struct {
+	RoomID    int       `json:"room_id"`
+	Name      string    `json:"name"`
+	Building  string    `json:"building"`
+	Capacity  int       `json:"capacity"`
+	Equipment []string  `json:"equipment"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RoomFilter struct {
+	Building    string
+	MinCapacity int
+	Equipment   []string
+}
+
+type ScheduleEntryRequest struct {
+	GroupID    int    `json:"group_id" validate:"required,gt=0"`
+	SubjectID  int    `json:"subject_id" validate:"required,gt=0"`
+	LessonName string `json:"lesson_name" validate:"required,notblank,max=200"`
+	DayOfWeek  int    `json:"day_of_week" validate:"min=1,max=7"`
+	StartTime  string `json:"start_time" validate:"required,clock"`
+	EndTime    string `json:"end_time" validate:"required,clock"`
+	RoomID     *int   `json:"room_id,omitempty" validate:"omitempty,gt=0"`
+}
+
+type ScheduleEntry struct {
+	ScheduleID  int     `json:"schedule_id"`
+	GroupID     int     `json:"group_id"`
+	SubjectID   int     `json:"subject_id"`
+	SubjectName string  `json:"subject_name"`
+	LessonName  string  `json:"lesson_name"`
+	DayOfWeek   int     `json:"day_of_week"`
+	StartTime   string  `json:"start_time"`
+	EndTime     string  `json:"end_time"`
+	RoomID      *int    `json:"room_id,omitempty"`
+	RoomName    *string `json:"room_name,omitempty"`
+}
+
+type ScheduleRoomRequest struct {
+	RoomID *int `json:"room_id" validate:"omitempty,gt=0"`
+}
+
+type ScheduleChangedEvent struct {
+	Action string `json:"action"`
+	ScheduleEntry
+}
+
+type RoomOccupancy struct {
+	RoomID      int     `json:"room_id"`
+	Name        string  `json:"name"`
+	Building    string  `json:"building"`
+	Capacity    int     `json:"capacity"`
+	Lessons     int     `json:"lessons"`
+	WeeklyHours float64 `json:"weekly_hours"`
+	Utilization float64 `json:"utilization"`
+}
+
+type TeacherAvailability struct {
+	DayOfWeek int    `json:"day_of_week" validate:"min=1,max=7"`
+	StartTime string `json:"start_time" validate:"required,clock"`
+	EndTime   string `json:"end_time" validate:"required,clock"`
+	Preferred bool   `json:"preferred"`
+}
+
+const (
+	DraftStatusDraft     = "draft"
+	DraftStatusPublished = "published"
+	DraftStatusDiscarded = "discarded"
+)
+
+type LessonPeriod struct {
+	Start string `json:"start" validate:"required,clock"`
+	End   string `json:"end" validate:"required,clock"`
+}
+
+type TimetableRequirement struct {
+	GroupID        int    `json:"group_id" validate:"required,gt=0"`
+	SubjectID      int    `json:"subject_id" validate:"required,gt=0"`
+	LessonsPerWeek int    `json:"lessons_per_week" validate:"required,gt=0"`
+	TeacherID      int    `json:"teacher_id,omitempty" validate:"gte=0"`
+	LessonName     string `json:"lesson_name,omitempty" validate:"max=200"`
+}
+
+type TimetableRequest struct {
+	Term             string                 `json:"term" validate:"omitempty,term"`
+	Seed             int64                  `json:"seed"`
+	Days             []int                  `json:"days" validate:"dive,min=1,max=7"`
+	Periods          []LessonPeriod         `json:"periods" validate:"dive"`
+	MaxLessonsPerDay int                    `json:"max_lessons_per_day" validate:"gte=0"`
+	UseRooms         *bool                  `json:"use_rooms"`
+	MaxSteps         int                    `json:"max_steps" validate:"gte=0"`
+	Requirements     []TimetableRequirement `json:"requirements" validate:"required,min=1,dive"`
+}
+
+type TimetableDraftLesson struct {
+	GroupID     int    `json:"group_id"`
+	SubjectID   int    `json:"subject_id"`
+	SubjectName string `json:"subject_name,omitempty"`
+	TeacherID   int    `json:"teacher_id"`
+	LessonName  string `json:"lesson_name"`
+	DayOfWeek   int    `json:"day_of_week"`
+	StartTime   string `json:"start_time"`
+	EndTime     string `json:"end_time"`
+	RoomID      *int   `json:"room_id,omitempty"`
+}
+
+type TimetableDraft struct {
+	DraftID     int                    `json:"draft_id"`
+	Term        string                 `json:"term"`
+	Seed        int64                  `json:"seed"`
+	Status      string                 `json:"status"`
+	Penalty     int                    `json:"penalty"`
+	Steps       int                    `json:"steps"`
+	CreatedBy   int                    `json:"created_by"`
+	CreatedAt   time.Time              `json:"created_at"`
+	PublishedAt *time.Time             `json:"published_at,omitempty"`
+	Lessons     []TimetableDraftLesson `json:"lessons,omitempty"`
+}
+
+type HolidayRequest struct {
+	Date string `json:"date" validate:"required,date"`
+	Name string `json:"name" validate:"required,notblank,max=200"`
+}
+
+type Holiday struct {
+	Date string `json:"date"`
+	Name string `json:"name"`
+}
+
+type CalendarFeed struct {
+	Token string   `json:"token"`
+	URLs  []string `json:"urls"`
+}
+
+// AttendanceColumn — проведённое занятие, колонка журнала посещаемости.
+type AttendanceColumn struct {
+	ScheduleID  int       `json:"schedule_id"`
+	Date        time.Time `json:"date"`
+	StartTime   string    `json:"start_time"`
+	SubjectName string    `json:"subject_name"`
+}
+
+// AttendanceSheetRow — строка журнала: статусы отметок в порядке колонок, "" — отметки нет.
+type AttendanceSheetRow struct {
+	StudentID int      `json:"student_id"`
+	Name      string   `json:"name"`
+	Surname   string   `json:"surname"`
+	GroupName string   `json:"group_name"`
+	Marks     []string `json:"marks"`
+}
+
+type SubjectSummary struct {
+	SubjectID   int    `json:"subject_id"`
+	SubjectName string `json:"subject_name"`
+	Credits     int    `json:"credits"`
+}
+
+// AttendanceRecord — отметка посещаемости со ссылками на студента и занятие.
+type AttendanceRecord struct {
+	AttendanceID int     `json:"attendance_id"`
+	StudentID    int     `json:"student_id"`
+	ScheduleID   int     `json:"schedule_id"`
+	SubjectID    int     `json:"subject_id"`
+	VisitDay     string  `json:"visit_day"`
+	Visited      bool    `json:"visited"`
+	Status       string  `json:"status"`
+	MinutesLate  *int    `json:"minutes_late,omitempty"`
+	Note         *string `json:"note,omitempty"`
+}
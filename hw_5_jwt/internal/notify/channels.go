@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/postgres"
+)
+
+const (
+	ChannelInbox   = "inbox"
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+// Message — одно оповещение конкретному получателю.
+type Message struct {
+	Recipient models.AlertRecipient
+	Kind      string
+	Title     string
+	Body      string
+	Event     *models.AlertEvent
+}
+
+// Channel доставляет оповещение одним способом: во внутренний ящик, по почте, вебхуком.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
+
+type InboxChannel struct {
+	repo *postgres.Repository
+}
+
+func NewInboxChannel(repo *postgres.Repository) *InboxChannel {
+	return &InboxChannel{repo: repo}
+}
+
+func (ch *InboxChannel) Name() string { return ChannelInbox }
+
+func (ch *InboxChannel) Send(ctx context.Context, msg Message) error {
+	return ch.repo.CreateNotification(ctx, &models.Notification{
+		UserID: msg.Recipient.UserID,
+		Kind:   msg.Kind,
+		Title:  msg.Title,
+		Body:   msg.Body,
+	})
+}
+
+type EmailChannel struct {
+	mailer *Mailer
+}
+
+func NewEmailChannel(mailer *Mailer) *EmailChannel {
+	return &EmailChannel{mailer: mailer}
+}
+
+func (ch *EmailChannel) Name() string { return ChannelEmail }
+
+func (ch *EmailChannel) Send(ctx context.Context, msg Message) error {
+	if msg.Recipient.Email == "" {
+		return nil
+	}
+	return ch.mailer.Send(ctx, msg.Recipient.Email, msg.Title, msg.Body)
+}
+
+type WebhookChannel struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookChannel(url string) *WebhookChannel {
+	return &WebhookChannel{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (ch *WebhookChannel) Name() string { return ChannelWebhook }
+
+func (ch *WebhookChannel) Send(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(map[string]any{
+		"kind":      msg.Kind,
+		"title":     msg.Title,
+		"body":      msg.Body,
+		"recipient": msg.Recipient,
+		"event":     msg.Event,
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации вебхука: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ch.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("ошибка создания запроса вебхука: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := ch.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка отправки вебхука: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("вебхук вернул статус %d", resp.StatusCode)
+	}
+	return nil
+}
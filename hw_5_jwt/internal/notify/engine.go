@@ -0,0 +1,145 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"hw_5_jwt/internal/i18n"
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/postgres"
+	"hw_5_jwt/internal/terms"
+)
+
+// Engine проверяет правила оповещений после записи посещаемости и по расписанию,
+// а найденные события рассылает получателям через каналы правила.
+type Engine struct {
+	repo     *postgres.Repository
+	logger   *slog.Logger
+	channels map[string]Channel
+}
+
+func NewEngine(repo *postgres.Repository, logger *slog.Logger, channels ...Channel) *Engine {
+	e := &Engine{repo: repo, logger: logger, channels: make(map[string]Channel)}
+	for _, ch := range channels {
+		if ch != nil {
+			e.channels[ch.Name()] = ch
+		}
+	}
+	return e
+}
+
+func (e *Engine) HasChannel(name string) bool {
+	_, ok := e.channels[name]
+	return ok
+}
+
+// Evaluate проверяет правила для указанных студентов (nil — для всех) в текущем семестре
+// и возвращает число новых событий.
+func (e *Engine) Evaluate(ctx context.Context, studentIDs []int) (int, error) {
+	term := terms.For(time.Now())
+	from, to, err := terms.Bounds(term)
+	if err != nil {
+		return 0, err
+	}
+
+	events, err := e.repo.EvaluateAlertRules(ctx, studentIDs, term, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range events {
+		e.dispatch(ctx, &events[i])
+	}
+
+	return len(events), nil
+}
+
+// EvaluateAsync запускает проверку в фоне, чтобы не задерживать ответ на запись посещаемости.
+func (e *Engine) EvaluateAsync(studentIDs ...int) {
+	if e == nil || len(studentIDs) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		if _, err := e.Evaluate(ctx, studentIDs); err != nil {
+			e.logger.Error("ошибка проверки правил оповещений", "student_ids", studentIDs, "error", err)
+		}
+	}()
+}
+
+// Run периодически проверяет правила для всех студентов до отмены контекста.
+func (e *Engine) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := e.Evaluate(ctx, nil)
+			if err != nil {
+				e.logger.Error("ошибка плановой проверки правил оповещений", "error", err)
+				continue
+			}
+			e.logger.Info("плановая проверка правил оповещений завершена", "events", count)
+		}
+	}
+}
+
+func (e *Engine) dispatch(ctx context.Context, event *models.AlertEvent) {
+	recipients, err := e.repo.GetAlertRecipients(ctx, event.StudentID, event.SubjectID, event.Term)
+	if err != nil {
+		e.logger.Error("ошибка получения получателей оповещения", "event_id", event.EventID, "error", err)
+		return
+	}
+
+	for _, recipient := range recipients {
+		msg := Message{
+			Recipient: recipient,
+			Kind:      "attendance_alert",
+			Title:     i18n.T(recipient.Language, "alerts.notification_title", event.SubjectName),
+			Body:      alertBody(event, recipient),
+			Event:     event,
+		}
+
+		for _, name := range event.Channels {
+			ch, ok := e.channels[name]
+			if !ok {
+				continue
+			}
+			if err := ch.Send(ctx, msg); err != nil {
+				e.logger.Error("ошибка доставки оповещения",
+					"event_id", event.EventID,
+					"channel", name,
+					"user_id", recipient.UserID,
+					"error", err,
+				)
+			}
+		}
+	}
+
+	e.logger.Info("оповещение разослано",
+		"event_id", event.EventID,
+		"rule_id", event.RuleID,
+		"student_id", event.StudentID,
+		"subject_id", event.SubjectID,
+		"recipients", len(recipients),
+	)
+}
+
+// alertBody пишет текст оповещения на языке получателя; без выбранного языка — по-русски.
+func alertBody(event *models.AlertEvent, recipient models.AlertRecipient) string {
+	missed := int(event.MissedRate*100 + 0.5)
+	threshold := int(event.Threshold*100 + 0.5)
+	if recipient.Role == "student" {
+		return i18n.T(recipient.Language, "alerts.notification_student",
+			missed, event.SubjectName, event.Term, event.RuleName, threshold)
+	}
+	return i18n.T(recipient.Language, "alerts.notification_staff",
+		event.StudentSurname, event.StudentName, missed, event.SubjectName, event.Term, event.RuleName, threshold)
+}
@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// Mailer отправляет письма через SMTP. Настраивается переменными SMTP_HOST, SMTP_PORT,
+// SMTP_USER, SMTP_PASSWORD и SMTP_FROM.
+type Mailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewMailerFromEnv возвращает nil, если SMTP_HOST не задан — тогда почта отключена.
+func NewMailerFromEnv() *Mailer {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "noreply@university.local"
+	}
+
+	var auth smtp.Auth
+	if user := os.Getenv("SMTP_USER"); user != "" {
+		auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASSWORD"), host)
+	}
+
+	return &Mailer{addr: net.JoinHostPort(host, port), auth: auth, from: from}
+}
+
+func (m *Mailer) Send(ctx context.Context, to, subject, body string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(body)
+
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("ошибка отправки письма: %w", err)
+	}
+	return nil
+}
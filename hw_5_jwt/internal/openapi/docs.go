@@ -0,0 +1,9 @@
+package openapi
+
+import _ "embed"
+
+// DocsHTML — встроенная страница документации: читает /openapi.json и позволяет
+// выполнить запрос с Bearer-токеном. Внешние скрипты не подключаются.
+//
+//go:embed docs.html
+var DocsHTML []byte
@@ -0,0 +1,348 @@
+// Package openapi собирает документ OpenAPI 3.1 из описаний маршрутов и Go-типов
+// моделей: схемы строятся отражением по тегам json, поэтому не расходятся с ответами API.
+package openapi
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem — операции пути по HTTP-методу в нижнем регистре.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string              `json:"operationId,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	// глобальной схемы безопасности нет: операции без Security публичные
+	Security   []map[string][]string `json:"security,omitempty"`
+	Deprecated bool                  `json:"deprecated,omitempty"`
+	Roles      []string              `json:"x-roles,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+}
+
+// Builder накапливает операции и схемы компонентов.
+type Builder struct {
+	doc   *Document
+	names map[reflect.Type]string
+}
+
+func New(info Info) *Builder {
+	return &Builder{
+		doc: &Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   map[string]PathItem{},
+			Components: Components{
+				Schemas:         map[string]*Schema{},
+				SecuritySchemes: map[string]SecurityScheme{},
+			},
+		},
+		names: map[reflect.Type]string{},
+	}
+}
+
+func (b *Builder) SecurityScheme(name string, s SecurityScheme) {
+	b.doc.Components.SecuritySchemes[name] = s
+}
+
+// Add регистрирует операцию по пути в формате Echo (/students/:id).
+func (b *Builder) Add(method, echoPath string, op *Operation) {
+	path := Path(echoPath)
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = PathItem{}
+		b.doc.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = op
+}
+
+// Has сообщает, описана ли операция для пути в формате Echo.
+func (b *Builder) Has(method, echoPath string) bool {
+	_, ok := b.doc.Paths[Path(echoPath)][strings.ToLower(method)]
+	return ok
+}
+
+func (b *Builder) Document() *Document {
+	return b.doc
+}
+
+var echoParam = regexp.MustCompile(`:([A-Za-z_][A-Za-z0-9_]*)`)
+
+// Path переводит путь Echo в шаблон OpenAPI: /students/:id → /students/{id}.
+func Path(echoPath string) string {
+	return echoParam.ReplaceAllString(echoPath, "{$1}")
+}
+
+// PathParams возвращает имена параметров пути Echo по порядку.
+func PathParams(echoPath string) []string {
+	var names []string
+	for _, m := range echoParam.FindAllStringSubmatch(echoPath, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Schema возвращает схему значения v. Именованные структуры выносятся в components
+// и подставляются ссылкой; nil даёт пустую схему (любое значение).
+func (b *Builder) Schema(v any) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return b.schemaOf(reflect.TypeOf(v))
+}
+
+func (b *Builder) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return &Schema{Type: "string", Format: "byte"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + b.component(t)}
+	}
+	return &Schema{}
+}
+
+// component возвращает имя схемы типа, описывая её при первом обращении. При совпадении
+// имён из разных пакетов к имени добавляется пакет: timetable.Room → TimetableRoom.
+func (b *Builder) component(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	for other := range b.names {
+		if b.names[other] == name {
+			pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+			name = string(unicode.ToUpper(rune(pkg[0]))) + pkg[1:] + name
+			break
+		}
+	}
+	// имя запоминается до обхода полей, чтобы рекурсивные типы ссылались на себя
+	b.names[t] = name
+	b.doc.Components.Schemas[name] = b.structSchema(t)
+	return name
+}
+
+func (b *Builder) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	b.addFields(s, t)
+	return s
+}
+
+func (b *Builder) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		// встроенные структуры без имени в теге раскрываются, как в encoding/json
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.addFields(s, ft)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		prop := b.schemaOf(f.Type)
+		if rules := f.Tag.Get("validate"); rules != "" {
+			// ограничения нельзя дописывать в общую схему компонента по ссылке
+			if prop.Ref == "" && applyRules(prop, rules) {
+				s.Required = append(s.Required, name)
+			}
+		}
+		s.Properties[name] = prop
+	}
+}
+
+// applyRules переносит правила тега validate в ограничения схемы и сообщает,
+// обязательно ли поле. Правила после dive относятся к элементам списка.
+func applyRules(s *Schema, rules string) (required bool) {
+	target := s
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "dive" {
+			if s.Items == nil || s.Items.Ref != "" {
+				return required
+			}
+			target = s.Items
+			continue
+		}
+		if name == "required" && target == s {
+			required = true
+		}
+		constrain(target, name, param)
+	}
+	return required
+}
+
+func constrain(s *Schema, rule, param string) {
+	n, err := strconv.ParseFloat(param, 64)
+	hasNum := err == nil
+	count := int(n)
+
+	switch {
+	case rule == "oneof":
+		s.Enum = strings.Fields(param)
+	case rule == "email":
+		s.Format = "email"
+	case rule == "url" || rule == "http_url":
+		s.Format = "uri"
+	case rule == "date":
+		s.Description = "Дата DD.MM.YYYY"
+	case rule == "clock":
+		s.Description = "Время HH:MM"
+	case rule == "term":
+		s.Description = "Семестр: 2025-fall или 2026-spring"
+	case rule == "notblank" && s.Type == "string":
+		one := 1
+		s.MinLength = &one
+	case !hasNum:
+	case s.Type == "string" && rule == "min":
+		s.MinLength = &count
+	case s.Type == "string" && rule == "max":
+		s.MaxLength = &count
+	case s.Type == "array" && rule == "min":
+		s.MinItems = &count
+	case s.Type == "array" && rule == "max":
+		s.MaxItems = &count
+	case rule == "min" || rule == "gte":
+		s.Minimum = &n
+	case rule == "max" || rule == "lte":
+		s.Maximum = &n
+	case rule == "gt":
+		s.ExclusiveMinimum = &n
+	case rule == "lt":
+		s.ExclusiveMaximum = &n
+	}
+}
+
+// Envelope описывает ответ-обёртку (ServerResponse или Problem) с заданной схемой поля data.
+func Envelope(envelope, data *Schema) *Schema {
+	if data == nil {
+		return envelope
+	}
+	return &Schema{AllOf: []*Schema{
+		envelope,
+		{Type: "object", Properties: map[string]*Schema{"data": data}},
+	}}
+}
@@ -0,0 +1,152 @@
+// Package pdf — минимальный генератор текстовых PDF без внешних зависимостей.
+// Используются стандартные шрифты Helvetica в кодировке WinAnsi, поэтому
+// кириллица транслитерируется латиницей: встраивать TTF ради отчётов не нужно.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	PageWidth  = 595.0 // A4 в пунктах
+	PageHeight = 842.0
+	Margin     = 50.0
+)
+
+type Document struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+func New() *Document {
+	d := &Document{}
+	d.AddPage()
+	return d
+}
+
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = PageHeight - Margin
+}
+
+// Text выводит строку в точке (x, y) от левого нижнего угла страницы.
+func (d *Document) Text(x, y, size float64, bold bool, s string) {
+	writeText(d.pages[len(d.pages)-1], x, y, size, bold, s)
+}
+
+func writeText(w io.Writer, x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(w, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, encode(s))
+}
+
+// Row выводит ячейки по колонкам xs на текущей строке и переходит на следующую,
+// открывая новую страницу при нехватке места.
+func (d *Document) Row(xs []float64, size float64, bold bool, cells ...string) {
+	lineHeight := size * 1.5
+	if d.y-lineHeight < Margin {
+		d.AddPage()
+	}
+	d.y -= lineHeight
+	for i, cell := range cells {
+		if i < len(xs) {
+			d.Text(xs[i], d.y, size, bold, cell)
+		}
+	}
+}
+
+// Space добавляет вертикальный отступ.
+func (d *Document) Space(h float64) {
+	d.y -= h
+}
+
+// Rule рисует горизонтальную линию по ширине страницы на текущей строке.
+func (d *Document) Rule() {
+	d.y -= 4
+	fmt.Fprintf(d.pages[len(d.pages)-1], "0.5 w %.2f %.2f m %.2f %.2f l S\n", Margin, d.y, PageWidth-Margin, d.y)
+}
+
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// 1 — каталог, 2 — дерево страниц, 3 и 4 — шрифты, далее пары страница/содержимое
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, 6+i*2,
+		))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+// encode переводит строку в WinAnsi и экранирует спецсимволы строкового литерала PDF.
+func encode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if t, ok := translit[r]; ok {
+			b.WriteString(t)
+			continue
+		}
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '№':
+			b.WriteString("No.")
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+var translit = map[rune]string{
+	'А': "A", 'Б': "B", 'В': "V", 'Г': "G", 'Д': "D", 'Е': "E", 'Ё': "Yo", 'Ж': "Zh",
+	'З': "Z", 'И': "I", 'Й': "Y", 'К': "K", 'Л': "L", 'М': "M", 'Н': "N", 'О': "O",
+	'П': "P", 'Р': "R", 'С': "S", 'Т': "T", 'У': "U", 'Ф': "F", 'Х': "Kh", 'Ц': "Ts",
+	'Ч': "Ch", 'Ш': "Sh", 'Щ': "Shch", 'Ъ': "", 'Ы': "Y", 'Ь': "", 'Э': "E", 'Ю': "Yu",
+	'Я': "Ya",
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+	// казахские буквы
+	'Ә': "A", 'Ғ': "G", 'Қ': "Q", 'Ң': "N", 'Ө': "O", 'Ұ': "U", 'Ү': "U", 'Һ': "H", 'І': "I",
+	'ә': "a", 'ғ': "g", 'қ': "q", 'ң': "n", 'ө': "o", 'ұ': "u", 'ү': "u", 'һ': "h", 'і': "i",
+}
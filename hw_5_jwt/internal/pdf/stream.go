@@ -0,0 +1,138 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Stream пишет PDF постранично: в памяти держится только текущая страница, готовые
+// страницы сразу уходят в w. Каталог и дерево страниц записываются в Close —
+// номера объектов 1 и 2 резервируются заранее, таблица xref ссылается на них по смещению.
+type Stream struct {
+	w       io.Writer
+	written int64
+	err     error
+
+	width, height float64
+	offsets       []int64 // смещение объекта N хранится в offsets[N-1]
+	kids          []string
+	page          bytes.Buffer
+	open          bool
+	y             float64
+
+	// OnPage вызывается после открытия каждой новой страницы, например для повтора шапки таблицы.
+	OnPage func()
+}
+
+// NewStream начинает документ со страницами width × height; для альбомной A4
+// передаются PageHeight, PageWidth.
+func NewStream(w io.Writer, width, height float64) *Stream {
+	s := &Stream{w: w, width: width, height: height, offsets: make([]int64, 2)}
+	s.write("%PDF-1.4\n")
+	s.object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	s.object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	return s
+}
+
+func (s *Stream) Width() float64 {
+	return s.width
+}
+
+func (s *Stream) write(str string) {
+	if s.err != nil {
+		return
+	}
+	n, err := io.WriteString(s.w, str)
+	s.written += int64(n)
+	s.err = err
+}
+
+// object записывает следующий по номеру объект и возвращает его номер.
+func (s *Stream) object(body string) int {
+	s.offsets = append(s.offsets, s.written)
+	num := len(s.offsets)
+	s.write(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", num, body))
+	return num
+}
+
+func (s *Stream) flushPage() {
+	if !s.open {
+		return
+	}
+	// номер страницы известен заранее: сразу за ней идёт её содержимое
+	num := len(s.offsets) + 1
+	s.object(fmt.Sprintf(
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+		s.width, s.height, num+1,
+	))
+	s.object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", s.page.Len(), s.page.String()))
+	s.kids = append(s.kids, fmt.Sprintf("%d 0 R", num))
+	s.page.Reset()
+	s.open = false
+}
+
+// AddPage отправляет текущую страницу в w и открывает новую.
+func (s *Stream) AddPage() {
+	s.flushPage()
+	s.open = true
+	s.y = s.height - Margin
+	if s.OnPage != nil {
+		s.OnPage()
+	}
+}
+
+func (s *Stream) Text(x, y, size float64, bold bool, str string) {
+	if !s.open {
+		s.AddPage()
+	}
+	writeText(&s.page, x, y, size, bold, str)
+}
+
+// Row работает как Document.Row.
+func (s *Stream) Row(xs []float64, size float64, bold bool, cells ...string) {
+	lineHeight := size * 1.5
+	if !s.open || s.y-lineHeight < Margin {
+		s.AddPage()
+	}
+	s.y -= lineHeight
+	for i, cell := range cells {
+		if i < len(xs) {
+			writeText(&s.page, xs[i], s.y, size, bold, cell)
+		}
+	}
+}
+
+func (s *Stream) Space(h float64) {
+	s.y -= h
+}
+
+func (s *Stream) Rule() {
+	if !s.open {
+		s.AddPage()
+	}
+	s.y -= 4
+	fmt.Fprintf(&s.page, "0.5 w %.2f %.2f m %.2f %.2f l S\n", Margin, s.y, s.width-Margin, s.y)
+}
+
+// Close дописывает последнюю страницу, каталог, дерево страниц и xref.
+func (s *Stream) Close() error {
+	if len(s.kids) == 0 && !s.open {
+		s.AddPage()
+	}
+	s.flushPage()
+
+	s.offsets[0] = s.written
+	s.write("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	s.offsets[1] = s.written
+	s.write(fmt.Sprintf("2 0 obj\n<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(s.kids, " "), len(s.kids)))
+
+	xref := s.written
+	s.write(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", len(s.offsets)+1))
+	for _, offset := range s.offsets {
+		s.write(fmt.Sprintf("%010d 00000 n \n", offset))
+	}
+	s.write(fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(s.offsets)+1, xref))
+	return s.err
+}
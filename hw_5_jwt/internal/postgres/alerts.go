@@ -0,0 +1,224 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hw_5_jwt/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *Repository) GetAlertRules(ctx context.Context) ([]models.AlertRule, error) {
+	query := `
+		SELECT rule_id, name, threshold::float8, min_lessons, channels, active, created_at
+		FROM alert_rules
+		ORDER BY rule_id
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения правил оповещений: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []models.AlertRule{}
+	for rows.Next() {
+		var rule models.AlertRule
+		err := rows.Scan(
+			&rule.RuleID,
+			&rule.Name,
+			&rule.Threshold,
+			&rule.MinLessons,
+			&rule.Channels,
+			&rule.Active,
+			&rule.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования правила оповещений: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации правил оповещений: %w", err)
+	}
+
+	return rules, nil
+}
+
+func (r *Repository) CreateAlertRule(ctx context.Context, rule *models.AlertRule) error {
+	query := `
+		INSERT INTO alert_rules (name, threshold, min_lessons, channels)
+		VALUES ($1, $2, $3, $4)
+		RETURNING rule_id, active, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query, rule.Name, rule.Threshold, rule.MinLessons, rule.Channels).Scan(
+		&rule.RuleID,
+		&rule.Active,
+		&rule.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка создания правила оповещений: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeactivateAlertRule(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, `UPDATE alert_rules SET active = false WHERE rule_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка отключения правила оповещений: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("правило оповещений с ID %d не найдено", id)
+	}
+	return nil
+}
+
+// EvaluateAlertRules находит пары студент–предмет, впервые за семестр пересёкшие порог пропусков
+// активного правила, и фиксирует их в alert_events. Пустой studentIDs означает всех студентов.
+// Возвращаются только новые события, поэтому повторная проверка не дублирует оповещения.
+func (r *Repository) EvaluateAlertRules(ctx context.Context, studentIDs []int, term string, from, to time.Time) ([]models.AlertEvent, error) {
+	if studentIDs == nil {
+		studentIDs = []int{}
+	}
+
+	query := `
+		WITH rates AS (
+			SELECT
+				a.student_id,
+				sch.subject_id,
+				COUNT(*) FILTER (WHERE a.status <> 'excused') AS counted,
+				COUNT(*) FILTER (WHERE a.status = 'absent') AS missed
+			FROM attendance a
+			JOIN schedule sch ON sch.schedule_id = a.schedule_id
+			WHERE a.attendance_date BETWEEN $1 AND $2
+				AND sch.subject_id IS NOT NULL
+				AND (cardinality($3::int[]) = 0 OR a.student_id = ANY($3::int[]))
+			GROUP BY a.student_id, sch.subject_id
+		),
+		inserted AS (
+			INSERT INTO alert_events (rule_id, student_id, subject_id, term, missed_rate)
+			SELECT r.rule_id, rt.student_id, rt.subject_id, $4, rt.missed::float8 / rt.counted
+			FROM rates rt
+			JOIN alert_rules r ON r.active
+			WHERE rt.counted >= r.min_lessons
+				AND rt.missed::numeric / rt.counted >= r.threshold
+			ON CONFLICT (rule_id, student_id, subject_id, term) DO NOTHING
+			RETURNING event_id, rule_id, student_id, subject_id, missed_rate, term
+		)
+		SELECT
+			i.event_id,
+			i.rule_id,
+			r.name,
+			r.threshold::float8,
+			r.channels,
+			i.student_id,
+			s.name,
+			s.surname,
+			i.subject_id,
+			COALESCE(subj.subject_name, ''),
+			i.missed_rate,
+			i.term
+		FROM inserted i
+		JOIN alert_rules r ON r.rule_id = i.rule_id
+		JOIN students s ON s.student_id = i.student_id
+		LEFT JOIN subjects subj ON subj.subject_id = i.subject_id
+		ORDER BY i.event_id
+	`
+
+	rows, err := r.db.Query(ctx, query, from, to, studentIDs, term)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки правил оповещений: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.AlertEvent
+	for rows.Next() {
+		var event models.AlertEvent
+		err := rows.Scan(
+			&event.EventID,
+			&event.RuleID,
+			&event.RuleName,
+			&event.Threshold,
+			&event.Channels,
+			&event.StudentID,
+			&event.StudentName,
+			&event.StudentSurname,
+			&event.SubjectID,
+			&event.SubjectName,
+			&event.MissedRate,
+			&event.Term,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования события оповещения: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации событий оповещений: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetAlertRecipients возвращает самого студента, куратора его группы и учителей,
+// назначенных на предмет в той группе, куда студент записан в семестре.
+func (r *Repository) GetAlertRecipients(ctx context.Context, studentID, subjectID int, term string) ([]models.AlertRecipient, error) {
+	query := `
+		SELECT u.id, u.email, 'student', COALESCE(u.language, '')
+		FROM students s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.student_id = $1
+		UNION
+		SELECT u.id, u.email, 'curator', COALESCE(u.language, '')
+		FROM students s
+		JOIN groups g ON g.group_id = s.group_id
+		JOIN users u ON u.id = g.curator_user_id
+		WHERE s.student_id = $1
+		UNION
+		SELECT u.id, u.email, 'teacher', COALESCE(u.language, '')
+		FROM enrollments e
+		JOIN subject_offerings o ON o.offering_id = e.offering_id
+		JOIN teacher_assignments ta
+			ON ta.subject_id = o.subject_id
+			AND ta.group_id = o.group_id
+			AND ta.term = o.term
+		JOIN teachers t ON t.id = ta.teacher_id
+		JOIN users u ON u.id = t.user_id
+		WHERE e.student_id = $1 AND e.status = 'enrolled'
+			AND o.subject_id = $2 AND o.term = $3
+	`
+
+	rows, err := r.db.Query(ctx, query, studentID, subjectID, term)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения получателей оповещения: %w", err)
+	}
+	defer rows.Close()
+
+	recipients, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.AlertRecipient, error) {
+		var recipient models.AlertRecipient
+		err := row.Scan(&recipient.UserID, &recipient.Email, &recipient.Role, &recipient.Language)
+		return recipient, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сканирования получателей оповещения: %w", err)
+	}
+
+	return recipients, nil
+}
+
+func (r *Repository) SetGroupCurator(ctx context.Context, groupID, userID int) error {
+	tag, err := r.db.Exec(ctx, `UPDATE groups SET curator_user_id = $1 WHERE group_id = $2`, userID, groupID)
+	if err != nil {
+		return fmt.Errorf("ошибка назначения куратора: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("группа с ID %d не найдена", groupID)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hw_5_jwt/internal/models"
+)
+
+// Пакетные выборки по списку ключей для GraphQL: один запрос на уровень
+// вложенности вместо запроса на каждый родительский объект.
+
+const studentColumns = `
+	student_id, name, surname, COALESCE(gender, ''), COALESCE(birthday, DATE '0001-01-01'),
+	COALESCE(group_id, 0), COALESCE(user_id, 0)
+`
+
+func (r *Repository) queryStudents(ctx context.Context, where string, args ...any) ([]models.Student, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+studentColumns+` FROM students `+where+` ORDER BY surname, name, student_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения студентов: %w", err)
+	}
+	defer rows.Close()
+
+	students := []models.Student{}
+	for rows.Next() {
+		var s models.Student
+		err := rows.Scan(&s.StudentID, &s.Name, &s.Surname, &s.Gender, &s.Birthday, &s.GroupID, &s.UserId)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования студента: %w", err)
+		}
+		students = append(students, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации студентов: %w", err)
+	}
+
+	return students, nil
+}
+
+func (r *Repository) GetStudentsByIDs(ctx context.Context, ids []int) ([]models.Student, error) {
+	return r.queryStudents(ctx, `WHERE student_id = ANY($1)`, ids)
+}
+
+func (r *Repository) GetStudentsByGroupIDs(ctx context.Context, groupIDs []int) ([]models.Student, error) {
+	return r.queryStudents(ctx, `WHERE group_id = ANY($1)`, groupIDs)
+}
+
+func (r *Repository) GetStudentsByUserIDs(ctx context.Context, userIDs []int) ([]models.Student, error) {
+	return r.queryStudents(ctx, `WHERE user_id = ANY($1)`, userIDs)
+}
+
+func (r *Repository) GetGroupsByIDs(ctx context.Context, ids []int) ([]models.Group, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT group_id, group_name, COALESCE(faculty, '')
+		FROM groups
+		WHERE group_id = ANY($1)
+		ORDER BY group_id
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения групп: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []models.Group{}
+	for rows.Next() {
+		var g models.Group
+		if err := rows.Scan(&g.GroupID, &g.GroupName, &g.Faculty); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования группы: %w", err)
+		}
+		groups = append(groups, g)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации групп: %w", err)
+	}
+
+	return groups, nil
+}
+
+func (r *Repository) queryTeachers(ctx context.Context, where string, args ...any) ([]models.Teacher, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			t.id,
+			COALESCE(t.user_id, 0),
+			t.name,
+			t.surname,
+			t.gender,
+			(
+				SELECT STRING_AGG(DISTINCT sub.subject_name, ', ')
+				FROM teacher_assignments ta
+				JOIN subjects sub ON sub.subject_id = ta.subject_id
+				WHERE ta.teacher_id = t.id
+			) AS subject
+		FROM teachers t
+		`+where+`
+		ORDER BY t.id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения учителей: %w", err)
+	}
+	defer rows.Close()
+
+	teachers := []models.Teacher{}
+	for rows.Next() {
+		var t models.Teacher
+		if err := rows.Scan(&t.ID, &t.UserId, &t.Name, &t.Surname, &t.Gender, &t.Subject); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования учителей: %w", err)
+		}
+		teachers = append(teachers, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации учителей: %w", err)
+	}
+
+	return teachers, nil
+}
+
+func (r *Repository) GetTeachersByIDs(ctx context.Context, ids []int) ([]models.Teacher, error) {
+	return r.queryTeachers(ctx, `WHERE t.id = ANY($1)`, ids)
+}
+
+func (r *Repository) GetTeachersByUserIDs(ctx context.Context, userIDs []int) ([]models.Teacher, error) {
+	return r.queryTeachers(ctx, `WHERE t.user_id = ANY($1)`, userIDs)
+}
+
+// GetSubjects возвращает предметы с указанными ID; при ids == nil — все предметы.
+func (r *Repository) GetSubjects(ctx context.Context, ids []int) ([]models.SubjectSummary, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT subject_id, subject_name, credits
+		FROM subjects
+		WHERE $1::int[] IS NULL OR subject_id = ANY($1)
+		ORDER BY subject_name, subject_id
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения предметов: %w", err)
+	}
+	defer rows.Close()
+
+	subjects := []models.SubjectSummary{}
+	for rows.Next() {
+		var s models.SubjectSummary
+		if err := rows.Scan(&s.SubjectID, &s.SubjectName, &s.Credits); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования предмета: %w", err)
+		}
+		subjects = append(subjects, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации предметов: %w", err)
+	}
+
+	return subjects, nil
+}
+
+func (r *Repository) GetScheduleEntriesByIDs(ctx context.Context, ids []int) ([]models.ScheduleEntry, error) {
+	return r.queryScheduleEntries(ctx, r.db, `WHERE s.schedule_id = ANY($1)`, ids)
+}
+
+func (r *Repository) GetScheduleEntriesByGroupIDs(ctx context.Context, groupIDs []int) ([]models.ScheduleEntry, error) {
+	return r.queryScheduleEntries(ctx, r.db, `WHERE s.group_id = ANY($1)`, groupIDs)
+}
+
+func (r *Repository) GetScheduleEntriesBySubjectIDs(ctx context.Context, subjectIDs []int) ([]models.ScheduleEntry, error) {
+	return r.queryScheduleEntries(ctx, r.db, `WHERE s.subject_id = ANY($1)`, subjectIDs)
+}
+
+func (r *Repository) queryAttendanceRecords(ctx context.Context, where string, args ...any) ([]models.AttendanceRecord, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			a.attendance_id,
+			a.student_id,
+			a.schedule_id,
+			COALESCE(sch.subject_id, 0),
+			TO_CHAR(a.attendance_date, 'DD.MM.YYYY'),
+			a.is_present,
+			a.status,
+			a.minutes_late,
+			a.note
+		FROM attendance a
+		JOIN schedule sch ON sch.schedule_id = a.schedule_id
+		`+where+`
+		ORDER BY a.attendance_date DESC, sch.start_time, a.student_id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения посещаемости: %w", err)
+	}
+	defer rows.Close()
+
+	records := []models.AttendanceRecord{}
+	for rows.Next() {
+		var a models.AttendanceRecord
+		err := rows.Scan(
+			&a.AttendanceID,
+			&a.StudentID,
+			&a.ScheduleID,
+			&a.SubjectID,
+			&a.VisitDay,
+			&a.Visited,
+			&a.Status,
+			&a.MinutesLate,
+			&a.Note,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования посещаемости: %w", err)
+		}
+		records = append(records, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации посещаемости: %w", err)
+	}
+
+	return records, nil
+}
+
+// GetAttendanceByStudentIDs возвращает отметки студентов; subjectID = 0 — по всем предметам.
+func (r *Repository) GetAttendanceByStudentIDs(ctx context.Context, studentIDs []int, subjectID int) ([]models.AttendanceRecord, error) {
+	return r.queryAttendanceRecords(ctx, `WHERE a.student_id = ANY($1) AND ($2 = 0 OR sch.subject_id = $2)`, studentIDs, subjectID)
+}
+
+// GetAttendanceByScheduleIDs возвращает отметки на занятиях; date == nil — за все дни.
+func (r *Repository) GetAttendanceByScheduleIDs(ctx context.Context, scheduleIDs []int, date *time.Time) ([]models.AttendanceRecord, error) {
+	return r.queryAttendanceRecords(ctx, `WHERE a.schedule_id = ANY($1) AND ($2::date IS NULL OR a.attendance_date = $2)`, scheduleIDs, date)
+}
+
+func (r *Repository) GetAssignmentsByTeacherIDs(ctx context.Context, teacherIDs []int, term string) ([]models.TeacherAssignment, error) {
+	return r.queryAssignments(ctx, `WHERE ta.teacher_id = ANY($1) AND ($2 = '' OR ta.term = $2)`, teacherIDs, term)
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hw_5_jwt/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *Repository) CreateHoliday(ctx context.Context, date time.Time, name string) error {
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO holidays (holiday_date, name)
+		VALUES ($1, $2)
+		ON CONFLICT (holiday_date) DO NOTHING
+	`, date, name)
+	if err != nil {
+		return fmt.Errorf("ошибка создания праздника: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("праздник на %s уже существует", date.Format("02.01.2006"))
+	}
+	return nil
+}
+
+func (r *Repository) DeleteHoliday(ctx context.Context, date time.Time) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM holidays WHERE holiday_date = $1`, date)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления праздника: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("праздник на %s не найден", date.Format("02.01.2006"))
+	}
+	return nil
+}
+
+func (r *Repository) GetHolidays(ctx context.Context, from, to time.Time) ([]models.Holiday, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT TO_CHAR(holiday_date, 'DD.MM.YYYY'), name
+		FROM holidays
+		WHERE holiday_date BETWEEN $1 AND $2
+		ORDER BY holiday_date
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения праздников: %w", err)
+	}
+
+	holidays, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.Holiday])
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сканирования праздников: %w", err)
+	}
+	return holidays, nil
+}
+
+func (r *Repository) GetGroupScheduleEntries(ctx context.Context, groupID int) ([]models.ScheduleEntry, error) {
+	return r.queryScheduleEntries(ctx, r.db, `WHERE s.group_id = $1`, groupID)
+}
+
+// GetTeacherScheduleEntries возвращает занятия, на которые учитель назначен в семестре.
+func (r *Repository) GetTeacherScheduleEntries(ctx context.Context, teacherID int, term string) ([]models.ScheduleEntry, error) {
+	return r.queryScheduleEntries(ctx, r.db, `
+		WHERE EXISTS (
+			SELECT 1 FROM teacher_assignments ta
+			WHERE ta.teacher_id = $1
+				AND ta.subject_id = s.subject_id
+				AND ta.group_id = s.group_id
+				AND ta.term = $2
+		)
+	`, teacherID, term)
+}
+
+func (r *Repository) GetTeacherIDByUserID(ctx context.Context, userID int) (int, error) {
+	var teacherID int
+	err := r.db.QueryRow(ctx, `SELECT id FROM teachers WHERE user_id = $1`, userID).Scan(&teacherID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, fmt.Errorf("учитель для пользователя %d не найден", userID)
+		}
+		return 0, fmt.Errorf("ошибка получения учителя: %w", err)
+	}
+	return teacherID, nil
+}
+
+// SetFeedTokenHash заменяет секрет ссылки на календарь; старые ссылки перестают работать.
+func (r *Repository) SetFeedTokenHash(ctx context.Context, userID int, hash string) error {
+	tag, err := r.db.Exec(ctx, `UPDATE users SET feed_token_hash = $1 WHERE id = $2`, hash, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения ссылки на календарь: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("пользователь с ID %d не найден", userID)
+	}
+	return nil
+}
+
+func (r *Repository) GetUserByFeedTokenHash(ctx context.Context, hash string) (*models.User, error) {
+	var userID int
+	err := r.db.QueryRow(ctx, `SELECT id FROM users WHERE feed_token_hash = $1`, hash).Scan(&userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка получения пользователя по ссылке на календарь: %w", err)
+	}
+	return r.GetUserByID(ctx, userID)
+}
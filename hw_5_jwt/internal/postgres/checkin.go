@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hw_5_jwt/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *Repository) CreateCheckinWindow(ctx context.Context, window *models.CheckinWindow) error {
+	visitDate, err := time.Parse("02.01.2006", window.VisitDay)
+	if err != nil {
+		return fmt.Errorf("неверный формат даты: %w", err)
+	}
+
+	var startTime *string
+	err = r.db.QueryRow(ctx, `
+		SELECT group_id, TO_CHAR(start_time, 'HH24:MI') FROM schedule WHERE schedule_id = $1
+	`, window.ScheduleID).Scan(&window.GroupID, &startTime)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("занятие с ID %d не найдено", window.ScheduleID)
+		}
+		return fmt.Errorf("ошибка получения занятия: %w", err)
+	}
+	window.LessonStart = lessonStart(visitDate, startTime)
+
+	err = r.db.QueryRow(ctx, `
+		INSERT INTO checkin_windows (schedule_id, attendance_date, secret, opened_by, opens_at, late_after, closes_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING window_id
+	`, window.ScheduleID, visitDate, window.Secret, window.OpenedBy,
+		window.OpensAt, window.LateAfter, window.ClosesAt,
+	).Scan(&window.WindowID)
+	if err != nil {
+		return fmt.Errorf("ошибка создания окна отметки: %w", err)
+	}
+
+	return nil
+}
+
+// lessonStart собирает начало занятия из даты и времени по расписанию (местное время
+// сервера, как и в расписании); nil, если время не задано.
+func lessonStart(visitDate time.Time, startTime *string) *time.Time {
+	if startTime == nil {
+		return nil
+	}
+	clock, err := time.Parse("15:04", *startTime)
+	if err != nil {
+		return nil
+	}
+	start := time.Date(visitDate.Year(), visitDate.Month(), visitDate.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local)
+	return &start
+}
+
+func (r *Repository) GetCheckinWindow(ctx context.Context, id int) (*models.CheckinWindow, error) {
+	query := `
+		SELECT
+			w.window_id,
+			w.schedule_id,
+			sch.group_id,
+			TO_CHAR(w.attendance_date, 'DD.MM.YYYY'),
+			COALESCE(w.opened_by, 0),
+			w.opens_at,
+			w.late_after,
+			w.closes_at,
+			w.closed_at,
+			TO_CHAR(sch.start_time, 'HH24:MI'),
+			w.secret
+		FROM checkin_windows w
+		JOIN schedule sch ON sch.schedule_id = w.schedule_id
+		WHERE w.window_id = $1
+	`
+
+	var window models.CheckinWindow
+	var startTime *string
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&window.WindowID,
+		&window.ScheduleID,
+		&window.GroupID,
+		&window.VisitDay,
+		&window.OpenedBy,
+		&window.OpensAt,
+		&window.LateAfter,
+		&window.ClosesAt,
+		&window.ClosedAt,
+		&startTime,
+		&window.Secret,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("окно отметки с ID %d не найдено", id)
+		}
+		return nil, fmt.Errorf("ошибка получения окна отметки: %w", err)
+	}
+	if visitDate, err := time.Parse("02.01.2006", window.VisitDay); err == nil {
+		window.LessonStart = lessonStart(visitDate, startTime)
+	}
+
+	return &window, nil
+}
+
+func (r *Repository) CloseCheckinWindow(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE checkin_windows
+		SET closed_at = CURRENT_TIMESTAMP
+		WHERE window_id = $1 AND closed_at IS NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка закрытия окна отметки: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("окно отметки с ID %d не найдено или уже закрыто", id)
+	}
+	return nil
+}
+
+// SubmitCheckin фиксирует самоотметку студента: одна отметка на окно, повтор кода отклоняется.
+func (r *Repository) SubmitCheckin(ctx context.Context, window *models.CheckinWindow, studentID int, step int64, req models.AttendanceRequest) error {
+	visitDate, err := time.Parse("02.01.2006", window.VisitDay)
+	if err != nil {
+		return fmt.Errorf("неверный формат даты: %w", err)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO checkin_submissions (window_id, student_id, code_step)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (window_id, student_id) DO NOTHING
+	`, window.WindowID, studentID, step)
+	if err != nil {
+		return fmt.Errorf("ошибка записи самоотметки: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("студент %d уже отмечен в окне %d", studentID, window.WindowID)
+	}
+
+	_, err = tx.Exec(ctx, upsertAttendanceQuery,
+		studentID, visitDate, req.Visited, window.ScheduleID,
+		req.Status, req.MinutesLate, req.Note,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка создания записи посещаемости: %w", err)
+	}
+
+	req.VisitDay = window.VisitDay
+	if err := insertOutboxEvent(ctx, tx, models.EventAttendanceRecorded, attendanceRecordedEvent(studentID, window.ScheduleID, req)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,462 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hw_5_jwt/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const offeringColumns = `
+	o.offering_id,
+	o.subject_id,
+	sub.subject_name,
+	o.group_id,
+	g.group_name,
+	o.term,
+	o.capacity,
+	(SELECT COUNT(*) FROM enrollments e WHERE e.offering_id = o.offering_id AND e.status = 'enrolled'),
+	(SELECT COUNT(*) FROM enrollments e WHERE e.offering_id = o.offering_id AND e.status = 'waitlisted'),
+	o.created_at
+`
+
+const offeringFrom = `
+	FROM subject_offerings o
+	JOIN subjects sub ON sub.subject_id = o.subject_id
+	JOIN groups g ON g.group_id = o.group_id
+`
+
+func scanOffering(row pgx.Row, o *models.Offering) error {
+	return row.Scan(
+		&o.OfferingID,
+		&o.SubjectID,
+		&o.SubjectName,
+		&o.GroupID,
+		&o.GroupName,
+		&o.Term,
+		&o.Capacity,
+		&o.Enrolled,
+		&o.Waitlisted,
+		&o.CreatedAt,
+	)
+}
+
+func (r *Repository) CreateOffering(ctx context.Context, req models.OfferingRequest) (*models.Offering, error) {
+	var id int
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO subject_offerings (subject_id, group_id, term, capacity)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (subject_id, group_id, term) DO NOTHING
+		RETURNING offering_id
+	`, req.SubjectID, req.GroupID, req.Term, req.Capacity).Scan(&id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("предмет %d для группы %d в семестре %s уже существует", req.SubjectID, req.GroupID, req.Term)
+		}
+		return nil, fmt.Errorf("ошибка создания предложения предмета: %w", err)
+	}
+
+	return r.GetOffering(ctx, id)
+}
+
+func (r *Repository) GetOffering(ctx context.Context, id int) (*models.Offering, error) {
+	var o models.Offering
+	err := scanOffering(r.db.QueryRow(ctx, `SELECT `+offeringColumns+offeringFrom+` WHERE o.offering_id = $1`, id), &o)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("предложение предмета с ID %d не найдено", id)
+		}
+		return nil, fmt.Errorf("ошибка получения предложения предмета: %w", err)
+	}
+	return &o, nil
+}
+
+func (r *Repository) ListOfferings(ctx context.Context, subjectID, groupID int, term string) ([]models.Offering, error) {
+	query := `SELECT ` + offeringColumns + offeringFrom + `
+		WHERE ($1 = 0 OR o.subject_id = $1)
+			AND ($2 = 0 OR o.group_id = $2)
+			AND ($3 = '' OR o.term = $3)
+		ORDER BY o.term DESC, sub.subject_name, g.group_name
+	`
+
+	rows, err := r.db.Query(ctx, query, subjectID, groupID, term)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения предложений предметов: %w", err)
+	}
+	defer rows.Close()
+
+	offerings := []models.Offering{}
+	for rows.Next() {
+		var o models.Offering
+		if err := scanOffering(rows, &o); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования предложения предмета: %w", err)
+		}
+		offerings = append(offerings, o)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации предложений предметов: %w", err)
+	}
+
+	return offerings, nil
+}
+
+// lockOffering блокирует предложение до конца транзакции, чтобы параллельные записи
+// не превысили вместимость.
+func lockOffering(ctx context.Context, tx pgx.Tx, offeringID int) (*int, int, error) {
+	var capacity *int
+	err := tx.QueryRow(ctx, `
+		SELECT capacity FROM subject_offerings WHERE offering_id = $1 FOR UPDATE
+	`, offeringID).Scan(&capacity)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, 0, fmt.Errorf("предложение предмета с ID %d не найдено", offeringID)
+		}
+		return nil, 0, fmt.Errorf("ошибка блокировки предложения предмета: %w", err)
+	}
+
+	var enrolled int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM enrollments WHERE offering_id = $1 AND status = 'enrolled'
+	`, offeringID).Scan(&enrolled)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка подсчёта записанных студентов: %w", err)
+	}
+
+	return capacity, enrolled, nil
+}
+
+// Enroll записывает студента на предмет, а при заполненной группе ставит в лист ожидания.
+// Повторная запись после отчисления с предмета допускается и ставит студента в конец очереди.
+func (r *Repository) Enroll(ctx context.Context, offeringID, studentID int) (*models.Enrollment, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	capacity, enrolled, err := lockOffering(ctx, tx, offeringID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := models.EnrollmentEnrolled
+	if capacity != nil && enrolled >= *capacity {
+		status = models.EnrollmentWaitlisted
+	}
+
+	var enrollmentID int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO enrollments (offering_id, student_id, status)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (offering_id, student_id) DO UPDATE
+		SET status = EXCLUDED.status,
+			requested_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE enrollments.status = 'dropped'
+		RETURNING enrollment_id
+	`, offeringID, studentID, status).Scan(&enrollmentID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("студент %d уже записан на предмет или стоит в листе ожидания", studentID)
+		}
+		return nil, fmt.Errorf("ошибка записи на предмет: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return r.getEnrollment(ctx, enrollmentID)
+}
+
+// EnrollGroup записывает на предмет всех студентов группы, кроме уже записанных.
+// Сверх вместимости студенты попадают в лист ожидания в порядке фамилий.
+func (r *Repository) EnrollGroup(ctx context.Context, offeringID, groupID int) ([]models.Enrollment, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	capacity, enrolled, err := lockOffering(ctx, tx, offeringID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		WITH candidates AS (
+			SELECT s.student_id, ROW_NUMBER() OVER (ORDER BY s.surname, s.name, s.student_id) AS n
+			FROM students s
+			WHERE s.group_id = $2
+				AND NOT EXISTS (
+					SELECT 1 FROM enrollments e
+					WHERE e.offering_id = $1 AND e.student_id = s.student_id AND e.status <> 'dropped'
+				)
+		)
+		INSERT INTO enrollments (offering_id, student_id, status)
+		SELECT $1, student_id,
+			CASE WHEN $3::int IS NULL OR $4 + n <= $3::int THEN 'enrolled' ELSE 'waitlisted' END
+		FROM candidates
+		ON CONFLICT (offering_id, student_id) DO UPDATE
+		SET status = EXCLUDED.status,
+			requested_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING enrollment_id
+	`, offeringID, groupID, capacity, enrolled)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка записи группы на предмет: %w", err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("ошибка записи группы на предмет: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return []models.Enrollment{}, nil
+	}
+	return r.queryEnrollments(ctx, `WHERE e.enrollment_id = ANY($1)`, ids)
+}
+
+// enrollInGroupOfferings записывает студента на предметы его группы в текущем семестре:
+// сверх вместимости — в лист ожидания, отчисленные и уже записанные не трогаются.
+func enrollInGroupOfferings(ctx context.Context, tx pgx.Tx, studentID, groupID int) error {
+	rows, err := tx.Query(ctx, `
+		SELECT offering_id FROM subject_offerings
+		WHERE group_id = $1 AND term = term_of(CURRENT_DATE)
+		ORDER BY offering_id
+	`, groupID)
+	if err != nil {
+		return fmt.Errorf("ошибка получения предметов группы: %w", err)
+	}
+	offeringIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return fmt.Errorf("ошибка получения предметов группы: %w", err)
+	}
+
+	for _, offeringID := range offeringIDs {
+		capacity, enrolled, err := lockOffering(ctx, tx, offeringID)
+		if err != nil {
+			return err
+		}
+		status := models.EnrollmentEnrolled
+		if capacity != nil && enrolled >= *capacity {
+			status = models.EnrollmentWaitlisted
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO enrollments (offering_id, student_id, status)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (offering_id, student_id) DO NOTHING
+		`, offeringID, studentID, status)
+		if err != nil {
+			return fmt.Errorf("ошибка записи студента %d на предмет: %w", studentID, err)
+		}
+	}
+	return nil
+}
+
+// Drop отчисляет студента с предмета. Если освободилось место, первый из листа ожидания
+// переводится в записанные в той же транзакции.
+func (r *Repository) Drop(ctx context.Context, offeringID, studentID int) (*models.DropResult, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	capacity, _, err := lockOffering(ctx, tx, offeringID)
+	if err != nil {
+		return nil, err
+	}
+
+	var enrollmentID int
+	var previous string
+	err = tx.QueryRow(ctx, `
+		UPDATE enrollments e
+		SET status = 'dropped', updated_at = CURRENT_TIMESTAMP
+		FROM (
+			SELECT enrollment_id, status FROM enrollments
+			WHERE offering_id = $1 AND student_id = $2
+			FOR UPDATE
+		) old
+		WHERE e.enrollment_id = old.enrollment_id AND old.status <> 'dropped'
+		RETURNING e.enrollment_id, old.status
+	`, offeringID, studentID).Scan(&enrollmentID, &previous)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("запись студента %d на предмет не найдена", studentID)
+		}
+		return nil, fmt.Errorf("ошибка отчисления с предмета: %w", err)
+	}
+
+	promotedID := 0
+	if previous == models.EnrollmentEnrolled {
+		_, enrolled, err := lockOffering(ctx, tx, offeringID)
+		if err != nil {
+			return nil, err
+		}
+
+		if capacity == nil || enrolled < *capacity {
+			err = tx.QueryRow(ctx, `
+				UPDATE enrollments
+				SET status = 'enrolled', updated_at = CURRENT_TIMESTAMP
+				WHERE enrollment_id = (
+					SELECT enrollment_id FROM enrollments
+					WHERE offering_id = $1 AND status = 'waitlisted'
+					ORDER BY requested_at, enrollment_id
+					LIMIT 1
+				)
+				RETURNING enrollment_id
+			`, offeringID).Scan(&promotedID)
+			if err != nil && err != pgx.ErrNoRows {
+				return nil, fmt.Errorf("ошибка перевода из листа ожидания: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	result := &models.DropResult{}
+	if result.Enrollment, err = r.getEnrollment(ctx, enrollmentID); err != nil {
+		return nil, err
+	}
+	if promotedID > 0 {
+		if result.Promoted, err = r.getEnrollment(ctx, promotedID); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (r *Repository) queryEnrollments(ctx context.Context, where string, args ...any) ([]models.Enrollment, error) {
+	query := `
+		SELECT
+			e.enrollment_id,
+			e.offering_id,
+			e.student_id,
+			st.name,
+			st.surname,
+			o.subject_id,
+			sub.subject_name,
+			o.term,
+			e.status,
+			CASE WHEN e.status = 'waitlisted' THEN (
+				SELECT COUNT(*) FROM enrollments w
+				WHERE w.offering_id = e.offering_id AND w.status = 'waitlisted'
+					AND (w.requested_at, w.enrollment_id) <= (e.requested_at, e.enrollment_id)
+			) ELSE 0 END,
+			e.requested_at,
+			e.updated_at
+		FROM enrollments e
+		JOIN subject_offerings o ON o.offering_id = e.offering_id
+		JOIN subjects sub ON sub.subject_id = o.subject_id
+		JOIN students st ON st.student_id = e.student_id
+		` + where + `
+		ORDER BY o.term DESC, sub.subject_name, e.status, st.surname, st.name
+	`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения записей на предметы: %w", err)
+	}
+	defer rows.Close()
+
+	enrollments := []models.Enrollment{}
+	for rows.Next() {
+		var e models.Enrollment
+		err := rows.Scan(
+			&e.EnrollmentID,
+			&e.OfferingID,
+			&e.StudentID,
+			&e.StudentName,
+			&e.StudentSurname,
+			&e.SubjectID,
+			&e.SubjectName,
+			&e.Term,
+			&e.Status,
+			&e.WaitlistPosition,
+			&e.RequestedAt,
+			&e.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования записи на предмет: %w", err)
+		}
+		enrollments = append(enrollments, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации записей на предметы: %w", err)
+	}
+
+	return enrollments, nil
+}
+
+func (r *Repository) getEnrollment(ctx context.Context, id int) (*models.Enrollment, error) {
+	enrollments, err := r.queryEnrollments(ctx, `WHERE e.enrollment_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(enrollments) == 0 {
+		return nil, fmt.Errorf("запись на предмет с ID %d не найдена", id)
+	}
+	return &enrollments[0], nil
+}
+
+func (r *Repository) GetOfferingEnrollments(ctx context.Context, offeringID int, status string) ([]models.Enrollment, error) {
+	return r.queryEnrollments(ctx, `WHERE e.offering_id = $1 AND ($2 = '' OR e.status = $2)`, offeringID, status)
+}
+
+func (r *Repository) GetStudentEnrollments(ctx context.Context, studentID int, term string) ([]models.Enrollment, error) {
+	return r.queryEnrollments(ctx, `WHERE e.student_id = $1 AND ($2 = '' OR o.term = $2)`, studentID, term)
+}
+
+// IsEnrolledInLesson проверяет, что студент записан на предмет занятия в семестре даты занятия.
+func (r *Repository) IsEnrolledInLesson(ctx context.Context, studentID, scheduleID int, visitDate time.Time) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM schedule sch
+			JOIN subject_offerings o
+				ON o.subject_id = sch.subject_id
+				AND o.group_id = sch.group_id
+				AND o.term = term_of($3)
+			JOIN enrollments e ON e.offering_id = o.offering_id AND e.status = 'enrolled'
+			WHERE sch.schedule_id = $2 AND e.student_id = $1
+		)
+	`, studentID, scheduleID, visitDate).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки записи на предмет: %w", err)
+	}
+	return ok, nil
+}
+
+// GetEnrolledStudentIDs возвращает студентов, записанных на предмет группы в семестре.
+func (r *Repository) GetEnrolledStudentIDs(ctx context.Context, subjectID, groupID int, term string) ([]int, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT e.student_id
+		FROM subject_offerings o
+		JOIN enrollments e ON e.offering_id = o.offering_id AND e.status = 'enrolled'
+		WHERE o.subject_id = $1 AND o.group_id = $2 AND o.term = $3
+	`, subjectID, groupID, term)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения записанных студентов: %w", err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сканирования записанных студентов: %w", err)
+	}
+	return ids, nil
+}
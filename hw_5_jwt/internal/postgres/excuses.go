@@ -0,0 +1,333 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"hw_5_jwt/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *Repository) CreateExcuse(ctx context.Context, excuse *models.Excuse, document *models.ExcuseDocument, userID int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// объяснить можно только свои пропуски и опоздания
+	var matched int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM attendance
+		WHERE attendance_id = ANY($1)
+			AND student_id = $2
+			AND status IN ('absent', 'late')
+	`, excuse.AttendanceIDs, excuse.StudentID).Scan(&matched)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки записей посещаемости: %w", err)
+	}
+	if matched != len(excuse.AttendanceIDs) {
+		return fmt.Errorf("пропуски для объяснительной не найдены")
+	}
+
+	var docName, docType *string
+	var docData []byte
+	if document != nil {
+		docName, docType, docData = &document.Name, &document.ContentType, document.Data
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO excuses (student_id, reason, status, document_name, document_type, document)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING excuse_id, status, document_name, created_at, updated_at
+	`, excuse.StudentID, excuse.Reason, models.ExcusePending, docName, docType, docData).Scan(
+		&excuse.ExcuseID,
+		&excuse.Status,
+		&excuse.DocumentName,
+		&excuse.CreatedAt,
+		&excuse.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка создания объяснительной: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO excuse_attendance (excuse_id, attendance_id)
+		SELECT $1, UNNEST($2::int[])
+	`, excuse.ExcuseID, excuse.AttendanceIDs)
+	if err != nil {
+		return fmt.Errorf("ошибка привязки пропусков к объяснительной: %w", err)
+	}
+
+	if err := insertExcuseHistory(ctx, tx, excuse.ExcuseID, models.ExcusePending, userID, ""); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return nil
+}
+
+func insertExcuseHistory(ctx context.Context, tx pgx.Tx, excuseID int, status string, userID int, comment string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO excuse_history (excuse_id, status, changed_by, comment)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+	`, excuseID, status, userID, comment)
+	if err != nil {
+		return fmt.Errorf("ошибка записи истории объяснительной: %w", err)
+	}
+	return nil
+}
+
+const excuseColumns = `
+	e.excuse_id,
+	e.student_id,
+	e.reason,
+	e.status,
+	e.document_name,
+	e.created_at,
+	e.updated_at,
+	ARRAY(
+		SELECT ea.attendance_id FROM excuse_attendance ea
+		WHERE ea.excuse_id = e.excuse_id
+		ORDER BY ea.attendance_id
+	)
+`
+
+func scanExcuse(row pgx.Row, excuse *models.Excuse) error {
+	return row.Scan(
+		&excuse.ExcuseID,
+		&excuse.StudentID,
+		&excuse.Reason,
+		&excuse.Status,
+		&excuse.DocumentName,
+		&excuse.CreatedAt,
+		&excuse.UpdatedAt,
+		&excuse.AttendanceIDs,
+	)
+}
+
+func (r *Repository) GetExcuse(ctx context.Context, id int) (*models.Excuse, error) {
+	var excuse models.Excuse
+	err := scanExcuse(r.db.QueryRow(ctx, `SELECT `+excuseColumns+` FROM excuses e WHERE e.excuse_id = $1`, id), &excuse)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("объяснительная с ID %d не найдена", id)
+		}
+		return nil, fmt.Errorf("ошибка получения объяснительной: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT status, changed_by, comment, created_at
+		FROM excuse_history
+		WHERE excuse_id = $1
+		ORDER BY created_at, history_id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории объяснительной: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.ExcuseHistory
+		if err := rows.Scan(&item.Status, &item.ChangedBy, &item.Comment, &item.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования истории объяснительной: %w", err)
+		}
+		excuse.History = append(excuse.History, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации истории объяснительной: %w", err)
+	}
+
+	return &excuse, nil
+}
+
+// ListExcuses возвращает объяснительные студента (studentID > 0), объяснительные
+// по предметам учителя (teacherUserID > 0) или все, если оба фильтра пусты.
+func (r *Repository) ListExcuses(ctx context.Context, studentID, teacherUserID int, status string) ([]models.Excuse, error) {
+	query := `
+		SELECT ` + excuseColumns + `
+		FROM excuses e
+		WHERE ($1 = 0 OR e.student_id = $1)
+			AND ($2 = '' OR e.status = $2)
+			AND ($3 = 0 OR EXISTS (
+				SELECT 1
+				FROM excuse_attendance ea
+				JOIN attendance a ON a.attendance_id = ea.attendance_id
+				JOIN schedule sch ON sch.schedule_id = a.schedule_id
+				JOIN teacher_assignments ta
+					ON ta.subject_id = sch.subject_id
+					AND ta.group_id = sch.group_id
+					AND ta.term = term_of(a.attendance_date)
+				JOIN teachers t ON t.id = ta.teacher_id
+				WHERE ea.excuse_id = e.excuse_id AND t.user_id = $3
+			))
+		ORDER BY e.created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, studentID, status, teacherUserID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения объяснительных: %w", err)
+	}
+	defer rows.Close()
+
+	excuses := []models.Excuse{}
+	for rows.Next() {
+		var excuse models.Excuse
+		if err := scanExcuse(rows, &excuse); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования объяснительной: %w", err)
+		}
+		excuses = append(excuses, excuse)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации объяснительных: %w", err)
+	}
+
+	return excuses, nil
+}
+
+func (r *Repository) GetExcuseDocument(ctx context.Context, id int) (*models.ExcuseDocument, error) {
+	var doc models.ExcuseDocument
+	var name, contentType *string
+	err := r.db.QueryRow(ctx, `
+		SELECT document_name, document_type, document
+		FROM excuses
+		WHERE excuse_id = $1
+	`, id).Scan(&name, &contentType, &doc.Data)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("объяснительная с ID %d не найдена", id)
+		}
+		return nil, fmt.Errorf("ошибка получения документа: %w", err)
+	}
+
+	if name == nil || doc.Data == nil {
+		return nil, fmt.Errorf("документ объяснительной %d не найден", id)
+	}
+	doc.Name = *name
+	doc.ContentType = "application/octet-stream"
+	if contentType != nil && *contentType != "" {
+		doc.ContentType = *contentType
+	}
+
+	return &doc, nil
+}
+
+// IsExcuseTeacher проверяет, что пользователь назначен на предмет и группу хотя бы одного пропуска из объяснительной.
+func (r *Repository) IsExcuseTeacher(ctx context.Context, excuseID, userID int) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM excuse_attendance ea
+			JOIN attendance a ON a.attendance_id = ea.attendance_id
+			JOIN schedule sch ON sch.schedule_id = a.schedule_id
+			JOIN teacher_assignments ta
+				ON ta.subject_id = sch.subject_id
+				AND ta.group_id = sch.group_id
+				AND ta.term = term_of(a.attendance_date)
+			JOIN teachers t ON t.id = ta.teacher_id
+			WHERE ea.excuse_id = $1 AND t.user_id = $2
+		)
+	`, excuseID, userID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки учителя объяснительной: %w", err)
+	}
+	return ok, nil
+}
+
+// TeachesAllExcuseLessons проверяет, что пользователь назначен на предмет и группу
+// каждого пропуска из объяснительной: одобрение меняет все связанные отметки.
+func (r *Repository) TeachesAllExcuseLessons(ctx context.Context, excuseID, userID int) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `
+		SELECT NOT EXISTS (
+			SELECT 1
+			FROM excuse_attendance ea
+			JOIN attendance a ON a.attendance_id = ea.attendance_id
+			JOIN schedule sch ON sch.schedule_id = a.schedule_id
+			WHERE ea.excuse_id = $1 AND NOT EXISTS (
+				SELECT 1
+				FROM teacher_assignments ta
+				JOIN teachers t ON t.id = ta.teacher_id
+				WHERE ta.subject_id = sch.subject_id
+					AND ta.group_id = sch.group_id
+					AND ta.term = term_of(a.attendance_date)
+					AND t.user_id = $2
+			)
+		)
+	`, excuseID, userID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки учителя объяснительной: %w", err)
+	}
+	return ok, nil
+}
+
+// DecideExcuse переводит объяснительную из pending в approved или rejected.
+// При одобрении связанные записи посещаемости становятся excused.
+func (r *Repository) DecideExcuse(ctx context.Context, excuseID int, status string, userID int, comment string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var updatedID int
+	err = tx.QueryRow(ctx, `
+		UPDATE excuses
+		SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE excuse_id = $2 AND status = $3
+		RETURNING excuse_id
+	`, status, excuseID, models.ExcusePending).Scan(&updatedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("объяснительная с ID %d не найдена или уже рассмотрена", excuseID)
+		}
+		return fmt.Errorf("ошибка обновления объяснительной: %w", err)
+	}
+
+	if err := insertExcuseHistory(ctx, tx, excuseID, status, userID, comment); err != nil {
+		return err
+	}
+
+	if status == models.ExcuseApproved {
+		rows, err := tx.Query(ctx, `
+			UPDATE attendance
+			SET status = $1, is_present = false, minutes_late = NULL
+			WHERE attendance_id IN (
+				SELECT attendance_id FROM excuse_attendance WHERE excuse_id = $2
+			)
+			RETURNING student_id, schedule_id, TO_CHAR(attendance_date, 'DD.MM.YYYY')
+		`, models.AttendanceExcused, excuseID)
+		if err != nil {
+			return fmt.Errorf("ошибка обновления посещаемости: %w", err)
+		}
+
+		events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.AttendanceRecordedEvent, error) {
+			event := models.AttendanceRecordedEvent{Status: models.AttendanceExcused}
+			err := row.Scan(&event.StudentID, &event.ScheduleID, &event.VisitDay)
+			return event, err
+		})
+		if err != nil {
+			return fmt.Errorf("ошибка обновления посещаемости: %w", err)
+		}
+
+		for _, event := range events {
+			if err := insertOutboxEvent(ctx, tx, models.EventAttendanceRecorded, event); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return nil
+}
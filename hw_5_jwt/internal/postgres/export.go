@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hw_5_jwt/internal/models"
+)
+
+// exportMarks — отметки, попадающие в выгрузку журнала; фильтры те же, что в statsFromClause.
+const exportMarks = `
+	marks AS (
+		SELECT a.student_id, a.schedule_id, a.attendance_date, a.status
+		FROM attendance a
+		JOIN students s ON s.student_id = a.student_id
+		LEFT JOIN groups g ON g.group_id = s.group_id
+		JOIN schedule sch ON sch.schedule_id = a.schedule_id
+		WHERE a.attendance_date BETWEEN $1 AND $2
+			AND ($3 = 0 OR s.group_id = $3)
+			AND ($4 = 0 OR sch.subject_id = $4)
+			AND ($5 = 0 OR a.student_id = $5)
+			AND ($6 = '' OR g.faculty = $6)
+	)`
+
+func exportArgs(f models.StatsFilter) []any {
+	return []any{f.From, f.To, f.GroupID, f.SubjectID, f.StudentID, f.Faculty}
+}
+
+// GetAttendanceColumns возвращает проведённые занятия периода в порядке даты и времени.
+func (r *Repository) GetAttendanceColumns(ctx context.Context, f models.StatsFilter) ([]models.AttendanceColumn, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH `+exportMarks+`
+		SELECT DISTINCT
+			m.schedule_id,
+			m.attendance_date,
+			COALESCE(TO_CHAR(sch.start_time, 'HH24:MI'), ''),
+			COALESCE(sub.subject_name, '')
+		FROM marks m
+		JOIN schedule sch ON sch.schedule_id = m.schedule_id
+		LEFT JOIN subjects sub ON sub.subject_id = sch.subject_id
+		ORDER BY m.attendance_date, 3, m.schedule_id
+	`, exportArgs(f)...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения занятий для выгрузки: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []models.AttendanceColumn
+	for rows.Next() {
+		var col models.AttendanceColumn
+		if err := rows.Scan(&col.ScheduleID, &col.Date, &col.StartTime, &col.SubjectName); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования занятия: %w", err)
+		}
+		columns = append(columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации по занятиям: %w", err)
+	}
+	return columns, nil
+}
+
+// StreamAttendanceSheet читает журнал курсором и вызывает fn для каждого студента по мере
+// чтения: в памяти держится одна строка. В журнал попадают студенты группы или слушатели
+// предмета, даже без отметок, и все, у кого есть отметки за период.
+func (r *Repository) StreamAttendanceSheet(
+	ctx context.Context,
+	f models.StatsFilter,
+	columns []models.AttendanceColumn,
+	fn func(models.AttendanceSheetRow) error,
+) error {
+	type cell struct {
+		scheduleID int
+		date       string
+	}
+	index := make(map[cell]int, len(columns))
+	for i, col := range columns {
+		index[cell{col.ScheduleID, col.Date.Format(time.DateOnly)}] = i
+	}
+
+	rows, err := r.db.Query(ctx, `
+		WITH `+exportMarks+`,
+		roster AS (
+			SELECT st.student_id
+			FROM students st
+			LEFT JOIN groups g ON g.group_id = st.group_id
+			WHERE ($3 = 0 OR st.group_id = $3)
+				AND ($5 = 0 OR st.student_id = $5)
+				AND ($6 = '' OR g.faculty = $6)
+				AND ($4 = 0
+					OR EXISTS (
+						SELECT 1 FROM schedule sch
+						WHERE sch.group_id = st.group_id AND sch.subject_id = $4
+					)
+					OR EXISTS (
+						SELECT 1 FROM enrollments e
+						JOIN subject_offerings o ON o.offering_id = e.offering_id
+						WHERE e.student_id = st.student_id AND o.subject_id = $4 AND e.status = 'enrolled'
+					))
+				AND ($3 <> 0 OR $4 <> 0 OR $5 <> 0)
+			UNION
+			SELECT student_id FROM marks
+		)
+		SELECT
+			st.student_id,
+			st.name,
+			st.surname,
+			COALESCE(g.group_name, ''),
+			m.schedule_id,
+			m.attendance_date,
+			m.status
+		FROM roster
+		JOIN students st ON st.student_id = roster.student_id
+		LEFT JOIN groups g ON g.group_id = st.group_id
+		LEFT JOIN marks m ON m.student_id = st.student_id
+		ORDER BY st.surname, st.name, st.student_id
+	`, exportArgs(f)...)
+	if err != nil {
+		return fmt.Errorf("ошибка получения журнала посещаемости: %w", err)
+	}
+	defer rows.Close()
+
+	var current *models.AttendanceSheetRow
+	for rows.Next() {
+		var row models.AttendanceSheetRow
+		var scheduleID *int
+		var date *time.Time
+		var status *string
+		if err := rows.Scan(&row.StudentID, &row.Name, &row.Surname, &row.GroupName, &scheduleID, &date, &status); err != nil {
+			return fmt.Errorf("ошибка сканирования журнала: %w", err)
+		}
+
+		if current == nil || current.StudentID != row.StudentID {
+			if current != nil {
+				if err := fn(*current); err != nil {
+					return err
+				}
+			}
+			row.Marks = make([]string, len(columns))
+			current = &row
+		}
+
+		if scheduleID != nil && date != nil && status != nil {
+			if i, ok := index[cell{*scheduleID, date.Format(time.DateOnly)}]; ok {
+				current.Marks[i] = *status
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка итерации по журналу: %w", err)
+	}
+
+	if current != nil {
+		return fn(*current)
+	}
+	return nil
+}
@@ -0,0 +1,373 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hw_5_jwt/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const assessmentColumns = `
+	assessment_id,
+	subject_id,
+	group_id,
+	kind,
+	title,
+	term,
+	weight::float8,
+	max_score::float8,
+	TO_CHAR(held_on, 'DD.MM.YYYY'),
+	created_at
+`
+
+func scanAssessment(row pgx.Row, a *models.Assessment) error {
+	return row.Scan(
+		&a.AssessmentID,
+		&a.SubjectID,
+		&a.GroupID,
+		&a.Kind,
+		&a.Title,
+		&a.Term,
+		&a.Weight,
+		&a.MaxScore,
+		&a.HeldOn,
+		&a.CreatedAt,
+	)
+}
+
+func (r *Repository) CreateAssessment(ctx context.Context, a *models.Assessment, createdBy int) error {
+	var heldOn *time.Time
+	if a.HeldOn != nil {
+		date, err := time.Parse("02.01.2006", *a.HeldOn)
+		if err != nil {
+			return fmt.Errorf("неверный формат даты: %w", err)
+		}
+		heldOn = &date
+	}
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO assessments (subject_id, group_id, kind, title, term, weight, max_score, held_on, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING assessment_id, created_at
+	`, a.SubjectID, a.GroupID, a.Kind, a.Title, a.Term, a.Weight, a.MaxScore, heldOn, createdBy,
+	).Scan(&a.AssessmentID, &a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка создания оценочного мероприятия: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetAssessment(ctx context.Context, id int) (*models.Assessment, error) {
+	var a models.Assessment
+	err := scanAssessment(r.db.QueryRow(ctx, `SELECT `+assessmentColumns+` FROM assessments WHERE assessment_id = $1`, id), &a)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("оценочное мероприятие с ID %d не найдено", id)
+		}
+		return nil, fmt.Errorf("ошибка получения оценочного мероприятия: %w", err)
+	}
+	return &a, nil
+}
+
+func (r *Repository) ListAssessments(ctx context.Context, subjectID, groupID int, term string) ([]models.Assessment, error) {
+	query := `
+		SELECT ` + assessmentColumns + `
+		FROM assessments
+		WHERE ($1 = 0 OR subject_id = $1)
+			AND ($2 = 0 OR group_id = $2)
+			AND ($3 = '' OR term = $3)
+		ORDER BY term, subject_id, group_id, held_on NULLS LAST, assessment_id
+	`
+
+	rows, err := r.db.Query(ctx, query, subjectID, groupID, term)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения оценочных мероприятий: %w", err)
+	}
+	defer rows.Close()
+
+	assessments := []models.Assessment{}
+	for rows.Next() {
+		var a models.Assessment
+		if err := scanAssessment(rows, &a); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования оценочного мероприятия: %w", err)
+		}
+		assessments = append(assessments, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации оценочных мероприятий: %w", err)
+	}
+
+	return assessments, nil
+}
+
+func (r *Repository) DeleteAssessment(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM assessments WHERE assessment_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления оценочного мероприятия: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("оценочное мероприятие с ID %d не найдено", id)
+	}
+	return nil
+}
+
+// UpsertGrades сохраняет оценки за мероприятие одной транзакцией; повторная запись перезаписывает балл.
+func (r *Repository) UpsertGrades(ctx context.Context, assessmentID int, grades []models.GradeRequest, gradedBy int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for _, grade := range grades {
+		batch.Queue(`
+			INSERT INTO grades (assessment_id, student_id, score, comment, graded_by)
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+			ON CONFLICT (assessment_id, student_id)
+			DO UPDATE SET
+				score = EXCLUDED.score,
+				comment = EXCLUDED.comment,
+				graded_by = EXCLUDED.graded_by,
+				updated_at = CURRENT_TIMESTAMP
+		`, assessmentID, grade.StudentID, grade.Score, grade.Comment, gradedBy)
+	}
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("ошибка записи оценок: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetGrades(ctx context.Context, f models.GradeFilter) ([]models.Grade, error) {
+	query := `
+		SELECT
+			g.assessment_id,
+			g.student_id,
+			st.name,
+			st.surname,
+			a.subject_id,
+			sub.subject_name,
+			a.kind,
+			a.title,
+			a.term,
+			a.weight::float8,
+			a.max_score::float8,
+			g.score::float8,
+			g.comment,
+			g.updated_at
+		FROM grades g
+		JOIN assessments a ON a.assessment_id = g.assessment_id
+		JOIN students st ON st.student_id = g.student_id
+		JOIN subjects sub ON sub.subject_id = a.subject_id
+		WHERE ($1 = 0 OR g.student_id = $1)
+			AND ($2 = 0 OR a.subject_id = $2)
+			AND ($3 = 0 OR a.group_id = $3)
+			AND ($4 = '' OR a.term = $4)
+			AND ($5 = 0 OR g.assessment_id = $5)
+		ORDER BY a.term, sub.subject_name, a.held_on NULLS LAST, a.assessment_id, st.surname, st.name
+	`
+
+	rows, err := r.db.Query(ctx, query, f.StudentID, f.SubjectID, f.GroupID, f.Term, f.AssessmentID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения оценок: %w", err)
+	}
+	defer rows.Close()
+
+	grades := []models.Grade{}
+	for rows.Next() {
+		var g models.Grade
+		err := rows.Scan(
+			&g.AssessmentID,
+			&g.StudentID,
+			&g.StudentName,
+			&g.StudentSurname,
+			&g.SubjectID,
+			&g.SubjectName,
+			&g.Kind,
+			&g.Title,
+			&g.Term,
+			&g.Weight,
+			&g.MaxScore,
+			&g.Score,
+			&g.Comment,
+			&g.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования оценки: %w", err)
+		}
+		grades = append(grades, g)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации оценок: %w", err)
+	}
+
+	return grades, nil
+}
+
+// GetFinalGrades считает итоговый взвешенный балл по студенту и предмету за семестр.
+// Мероприятия без оценки идут в знаменатель с нулём, поэтому итог растёт по мере выставления оценок;
+// graded_weight показывает, какая часть веса уже оценена.
+func (r *Repository) GetFinalGrades(ctx context.Context, f models.GradeFilter) ([]models.FinalGrade, error) {
+	query := `
+		WITH totals AS (
+			SELECT
+				st.student_id,
+				st.name,
+				st.surname,
+				a.subject_id,
+				a.term,
+				SUM(a.weight) AS total_weight,
+				COALESCE(SUM(a.weight) FILTER (WHERE g.grade_id IS NOT NULL), 0) AS graded_weight,
+				COALESCE(SUM(g.score / a.max_score * a.weight), 0) AS earned
+			FROM assessments a
+			JOIN subject_offerings o
+				ON o.subject_id = a.subject_id AND o.group_id = a.group_id AND o.term = a.term
+			JOIN enrollments en ON en.offering_id = o.offering_id AND en.status = 'enrolled'
+			JOIN students st ON st.student_id = en.student_id
+			LEFT JOIN grades g ON g.assessment_id = a.assessment_id AND g.student_id = st.student_id
+			WHERE ($1 = 0 OR st.student_id = $1)
+				AND ($2 = 0 OR a.subject_id = $2)
+				AND ($3 = 0 OR a.group_id = $3)
+				AND ($4 = '' OR a.term = $4)
+			GROUP BY st.student_id, st.name, st.surname, a.subject_id, a.term
+		),
+		finals AS (
+			SELECT *, ROUND(earned / total_weight * 100, 2) AS percent
+			FROM totals
+		)
+		SELECT
+			f.student_id,
+			f.name,
+			f.surname,
+			f.subject_id,
+			sub.subject_name,
+			sub.credits,
+			f.term,
+			f.percent::float8,
+			f.graded_weight::float8,
+			f.total_weight::float8,
+			COALESCE(gs.letter, ''),
+			COALESCE(gs.gpa_points, 0)::float8
+		FROM finals f
+		JOIN subjects sub ON sub.subject_id = f.subject_id
+		LEFT JOIN LATERAL (
+			SELECT letter, gpa_points
+			FROM grading_scale
+			WHERE min_percent <= f.percent
+			ORDER BY min_percent DESC
+			LIMIT 1
+		) gs ON true
+		ORDER BY f.term, sub.subject_name, f.surname, f.name
+	`
+
+	rows, err := r.db.Query(ctx, query, f.StudentID, f.SubjectID, f.GroupID, f.Term)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расчёта итоговых оценок: %w", err)
+	}
+	defer rows.Close()
+
+	finals := []models.FinalGrade{}
+	for rows.Next() {
+		var fg models.FinalGrade
+		err := rows.Scan(
+			&fg.StudentID,
+			&fg.StudentName,
+			&fg.StudentSurname,
+			&fg.SubjectID,
+			&fg.SubjectName,
+			&fg.Credits,
+			&fg.Term,
+			&fg.Percent,
+			&fg.GradedWeight,
+			&fg.TotalWeight,
+			&fg.Letter,
+			&fg.GPAPoints,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования итоговой оценки: %w", err)
+		}
+		finals = append(finals, fg)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации итоговых оценок: %w", err)
+	}
+
+	return finals, nil
+}
+
+func (r *Repository) GetGradingScale(ctx context.Context) ([]models.GradingScaleEntry, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT letter, min_percent::float8, gpa_points::float8
+		FROM grading_scale
+		ORDER BY min_percent DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения шкалы оценивания: %w", err)
+	}
+
+	scale, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.GradingScaleEntry, error) {
+		var e models.GradingScaleEntry
+		err := row.Scan(&e.Letter, &e.MinPercent, &e.GPAPoints)
+		return e, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сканирования шкалы оценивания: %w", err)
+	}
+
+	return scale, nil
+}
+
+// ReplaceGradingScale заменяет шкалу целиком, чтобы в ней не оставалось пересекающихся порогов.
+func (r *Repository) ReplaceGradingScale(ctx context.Context, scale []models.GradingScaleEntry) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM grading_scale`); err != nil {
+		return fmt.Errorf("ошибка очистки шкалы оценивания: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for _, entry := range scale {
+		batch.Queue(`
+			INSERT INTO grading_scale (letter, min_percent, gpa_points)
+			VALUES ($1, $2, $3)
+		`, entry.Letter, entry.MinPercent, entry.GPAPoints)
+	}
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("ошибка записи шкалы оценивания: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) SetSubjectCredits(ctx context.Context, subjectID, credits int) error {
+	tag, err := r.db.Exec(ctx, `UPDATE subjects SET credits = $1 WHERE subject_id = $2`, credits, subjectID)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления кредитов предмета: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("предмет с ID %d не найден", subjectID)
+	}
+	return nil
+}
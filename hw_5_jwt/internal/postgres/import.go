@@ -0,0 +1,340 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"hw_5_jwt/internal/importer"
+	"hw_5_jwt/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// nameKeyExpr сравнивает названия так же, как importer.NaturalKey.
+const nameKeyExpr = `LOWER(REGEXP_REPLACE(TRIM(%s), '\s+', ' ', 'g'))`
+
+// runImport выполняет импорт одной транзакцией. Изменения фиксируются только если
+// все строки прошли проверку и это не пробный запуск.
+func (r *Repository) runImport(ctx context.Context, report *importer.Report, fn func(tx pgx.Tx) error) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// параллельные импорты по одним и тем же ключам создали бы дубликаты
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('import'))`); err != nil {
+		return fmt.Errorf("ошибка блокировки импорта: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	report.Sort()
+
+	if report.HasErrors() || report.DryRun {
+		return nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+	report.Committed = true
+	return nil
+}
+
+func findGroupID(ctx context.Context, tx pgx.Tx, name string) (int, error) {
+	var id int
+	err := tx.QueryRow(ctx, `
+		SELECT group_id FROM groups
+		WHERE `+fmt.Sprintf(nameKeyExpr, "group_name")+` = $1
+		ORDER BY group_id
+		LIMIT 1
+	`, importer.NaturalKey(name)).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка поиска группы: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Repository) ImportGroups(ctx context.Context, groups []importer.Group, report *importer.Report) error {
+	return r.runImport(ctx, report, func(tx pgx.Tx) error {
+		for _, g := range groups {
+			id, err := findGroupID(ctx, tx, g.GroupName)
+			if err != nil {
+				return err
+			}
+
+			if id == 0 {
+				_, err := tx.Exec(ctx, `
+					INSERT INTO groups (group_name, faculty) VALUES ($1, NULLIF($2, ''))
+				`, g.GroupName, g.Faculty)
+				if err != nil {
+					return fmt.Errorf("ошибка создания группы (строка %d): %w", g.Line, err)
+				}
+				report.Done(g.Line, g.GroupName, importer.ActionCreated)
+				continue
+			}
+
+			tag, err := tx.Exec(ctx, `
+				UPDATE groups SET faculty = $1
+				WHERE group_id = $2 AND $1 <> '' AND faculty IS DISTINCT FROM $1
+			`, g.Faculty, id)
+			if err != nil {
+				return fmt.Errorf("ошибка обновления группы (строка %d): %w", g.Line, err)
+			}
+			if tag.RowsAffected() > 0 {
+				report.Done(g.Line, g.GroupName, importer.ActionUpdated)
+			} else {
+				report.Done(g.Line, g.GroupName, importer.ActionUnchanged)
+			}
+		}
+		return nil
+	})
+}
+
+// ImportStudents создаёт или переводит студентов; для новых пишется событие student.created.
+// Строка с email дополняет карточку, созданную при регистрации этого пользователя.
+// Новые и переведённые студенты записываются на предметы группы в текущем семестре.
+func (r *Repository) ImportStudents(ctx context.Context, students []importer.Student, report *importer.Report) error {
+	return r.runImport(ctx, report, func(tx pgx.Tx) error {
+		for _, s := range students {
+			groupID, err := findGroupID(ctx, tx, s.GroupName)
+			if err != nil {
+				return err
+			}
+			if groupID == 0 {
+				report.Fail(s.Line, s.Key(), fmt.Sprintf("group_name: группа %q не найдена", s.GroupName))
+				continue
+			}
+
+			studentID, userID, problem, err := findImportedStudent(ctx, tx, s)
+			if err != nil {
+				return err
+			}
+			if problem != "" {
+				report.Fail(s.Line, s.Key(), problem)
+				continue
+			}
+
+			if studentID == 0 {
+				student := models.Student{
+					Name:     s.Name,
+					Surname:  s.Surname,
+					Gender:   s.Gender,
+					Birthday: s.Birthday,
+					GroupID:  groupID,
+					UserId:   userID,
+				}
+				err := tx.QueryRow(ctx, `
+					INSERT INTO students (name, surname, gender, birthday, group_id, user_id)
+					VALUES ($1, $2, NULLIF($3, ''), $4, $5, NULLIF($6, 0))
+					RETURNING student_id
+				`, s.Name, s.Surname, s.Gender, s.Birthday, groupID, userID).Scan(&student.StudentID)
+				if err != nil {
+					return fmt.Errorf("ошибка создания студента (строка %d): %w", s.Line, err)
+				}
+				if err := insertOutboxEvent(ctx, tx, models.EventStudentCreated, student); err != nil {
+					return err
+				}
+				if err := enrollInGroupOfferings(ctx, tx, student.StudentID, groupID); err != nil {
+					return err
+				}
+				report.Done(s.Line, s.Key(), importer.ActionCreated)
+				continue
+			}
+
+			// карточке из регистрации импорт добавляет дату рождения и привязку к учётной записи
+			tag, err := tx.Exec(ctx, `
+				UPDATE students
+				SET group_id = $1,
+					gender = COALESCE(NULLIF($2, ''), gender),
+					birthday = $4,
+					user_id = COALESCE(user_id, NULLIF($5, 0))
+				WHERE student_id = $3
+					AND (group_id IS DISTINCT FROM $1
+						OR ($2 <> '' AND gender IS DISTINCT FROM $2)
+						OR birthday IS DISTINCT FROM $4
+						OR (user_id IS NULL AND $5 <> 0))
+			`, groupID, s.Gender, studentID, s.Birthday, userID)
+			if err != nil {
+				return fmt.Errorf("ошибка обновления студента (строка %d): %w", s.Line, err)
+			}
+			if tag.RowsAffected() > 0 {
+				if err := enrollInGroupOfferings(ctx, tx, studentID, groupID); err != nil {
+					return err
+				}
+				report.Done(s.Line, s.Key(), importer.ActionUpdated)
+			} else {
+				report.Done(s.Line, s.Key(), importer.ActionUnchanged)
+			}
+		}
+		return nil
+	})
+}
+
+// findImportedStudent ищет карточку для строки импорта: по email — карточку учётной
+// записи студента, иначе по имени, фамилии и дате рождения. userID — учётная запись
+// из email, к которой надо привязать карточку; problem — ошибка строки для отчёта.
+func findImportedStudent(ctx context.Context, tx pgx.Tx, s importer.Student) (studentID, userID int, problem string, err error) {
+	if s.Email != "" {
+		var role string
+		var cardID *int
+		err := tx.QueryRow(ctx, `
+			SELECT u.id, COALESCE(u.role, 'student'),
+				(SELECT MIN(st.student_id) FROM students st WHERE st.user_id = u.id)
+			FROM users u
+			WHERE LOWER(u.email) = LOWER($1)
+		`, s.Email).Scan(&userID, &role, &cardID)
+		switch {
+		case err == pgx.ErrNoRows:
+			return 0, 0, fmt.Sprintf("email: пользователь %s не найден", s.Email), nil
+		case err != nil:
+			return 0, 0, "", fmt.Errorf("ошибка поиска пользователя (строка %d): %w", s.Line, err)
+		case role != "student":
+			return 0, 0, fmt.Sprintf("email: %s — учётная запись не студента", s.Email), nil
+		case cardID != nil:
+			return *cardID, userID, "", nil
+		}
+	}
+
+	// без email или для учётной записи без карточки: карточка, ещё не привязанная
+	// к другому пользователю
+	err = tx.QueryRow(ctx, `
+		SELECT student_id FROM students
+		WHERE LOWER(name) = LOWER($1) AND LOWER(surname) = LOWER($2) AND birthday = $3
+			AND ($4 = 0 OR user_id IS NULL)
+		ORDER BY student_id
+		LIMIT 1
+	`, s.Name, s.Surname, s.Birthday, userID).Scan(&studentID)
+	if err != nil && err != pgx.ErrNoRows {
+		return 0, 0, "", fmt.Errorf("ошибка поиска студента (строка %d): %w", s.Line, err)
+	}
+	return studentID, userID, "", nil
+}
+
+// ImportSchedule создаёт или обновляет занятия по ключу группа + предмет + день + начало.
+// Аудитории проверяются так же, как при ручном создании занятия.
+func (r *Repository) ImportSchedule(ctx context.Context, rows []importer.ScheduleRow, report *importer.Report) error {
+	return r.runImport(ctx, report, func(tx pgx.Tx) error {
+		for _, s := range rows {
+			key := s.Key()
+
+			groupID, err := findGroupID(ctx, tx, s.GroupName)
+			if err != nil {
+				return err
+			}
+
+			var subjectID int
+			var subjectName string
+			err = tx.QueryRow(ctx, `
+				SELECT subject_id, subject_name FROM subjects
+				WHERE `+fmt.Sprintf(nameKeyExpr, "subject_name")+` = $1
+				ORDER BY subject_id
+				LIMIT 1
+			`, importer.NaturalKey(s.SubjectName)).Scan(&subjectID, &subjectName)
+			if err != nil && err != pgx.ErrNoRows {
+				return fmt.Errorf("ошибка поиска предмета (строка %d): %w", s.Line, err)
+			}
+
+			var roomID *int
+			if s.Room != "" {
+				var id int
+				err := tx.QueryRow(ctx, `
+					SELECT room_id FROM rooms WHERE `+fmt.Sprintf(nameKeyExpr, "name")+` = $1 AND active
+				`, importer.NaturalKey(s.Room)).Scan(&id)
+				if err != nil && err != pgx.ErrNoRows {
+					return fmt.Errorf("ошибка поиска аудитории (строка %d): %w", s.Line, err)
+				}
+				if err == nil {
+					roomID = &id
+				}
+			}
+
+			var errs []string
+			if groupID == 0 {
+				errs = append(errs, fmt.Sprintf("group_name: группа %q не найдена", s.GroupName))
+			}
+			if subjectID == 0 {
+				errs = append(errs, fmt.Sprintf("subject_name: предмет %q не найден", s.SubjectName))
+			}
+			if s.Room != "" && roomID == nil {
+				errs = append(errs, fmt.Sprintf("room: аудитория %q не найдена", s.Room))
+			}
+			if len(errs) > 0 {
+				report.Fail(s.Line, key, errs...)
+				continue
+			}
+
+			if s.LessonName == "" {
+				s.LessonName = subjectName
+			}
+
+			var existingID int
+			var changed bool
+			err = tx.QueryRow(ctx, `
+				SELECT
+					schedule_id,
+					end_time IS DISTINCT FROM $5::time OR lesson_name IS DISTINCT FROM $6 OR room_id IS DISTINCT FROM $7
+				FROM schedule
+				WHERE group_id = $1 AND subject_id = $2 AND day_of_week = $3 AND start_time = $4::time
+				ORDER BY schedule_id
+				LIMIT 1
+			`, groupID, subjectID, s.DayOfWeek, s.StartTime, s.EndTime, s.LessonName, roomID).Scan(&existingID, &changed)
+			if err != nil && err != pgx.ErrNoRows {
+				return fmt.Errorf("ошибка поиска занятия (строка %d): %w", s.Line, err)
+			}
+			if existingID != 0 && !changed {
+				report.Done(s.Line, key, importer.ActionUnchanged)
+				continue
+			}
+
+			if roomID != nil {
+				if err := checkRoom(ctx, tx, *roomID, groupID, s.DayOfWeek, s.StartTime, s.EndTime, existingID); err != nil {
+					if strings.HasPrefix(err.Error(), "ошибка") {
+						return err
+					}
+					report.Fail(s.Line, key, "room: "+err.Error())
+					continue
+				}
+			}
+
+			action := importer.ActionUpdated
+			scheduleID := existingID
+			if existingID == 0 {
+				action = importer.ActionCreated
+				err = tx.QueryRow(ctx, `
+					INSERT INTO schedule (group_id, subject_id, lesson_name, day_of_week, start_time, end_time, room_id)
+					VALUES ($1, $2, $3, $4, $5::time, $6::time, $7)
+					RETURNING schedule_id
+				`, groupID, subjectID, s.LessonName, s.DayOfWeek, s.StartTime, s.EndTime, roomID).Scan(&scheduleID)
+			} else {
+				_, err = tx.Exec(ctx, `
+					UPDATE schedule
+					SET end_time = $1::time, lesson_name = $2, room_id = $3
+					WHERE schedule_id = $4
+				`, s.EndTime, s.LessonName, roomID, existingID)
+			}
+			if err != nil {
+				return fmt.Errorf("ошибка сохранения занятия (строка %d): %w", s.Line, err)
+			}
+
+			entry, err := r.getScheduleEntry(ctx, tx, scheduleID)
+			if err != nil {
+				return err
+			}
+			if err := insertOutboxEvent(ctx, tx, models.EventScheduleChanged, models.ScheduleChangedEvent{
+				Action:        action,
+				ScheduleEntry: *entry,
+			}); err != nil {
+				return err
+			}
+			report.Done(s.Line, key, action)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"hw_5_jwt/internal/models"
+)
+
+func (r *Repository) CreateNotification(ctx context.Context, n *models.Notification) error {
+	query := `
+		INSERT INTO notifications (user_id, kind, title, body)
+		VALUES ($1, $2, $3, $4)
+		RETURNING notification_id, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query, n.UserID, n.Kind, n.Title, n.Body).Scan(
+		&n.NotificationID,
+		&n.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка создания уведомления: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetNotifications(ctx context.Context, userID int, unreadOnly bool) ([]models.Notification, error) {
+	query := `
+		SELECT notification_id, user_id, kind, title, body, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC, notification_id DESC
+		LIMIT 200
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, unreadOnly)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения уведомлений: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		err := rows.Scan(
+			&n.NotificationID,
+			&n.UserID,
+			&n.Kind,
+			&n.Title,
+			&n.Body,
+			&n.ReadAt,
+			&n.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования уведомления: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации уведомлений: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (r *Repository) CountUnreadNotifications(ctx context.Context, userID int) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL
+	`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка подсчёта уведомлений: %w", err)
+	}
+	return count, nil
+}
+
+// MarkNotificationsRead отмечает прочитанными уведомления пользователя;
+// notificationID = 0 отмечает все.
+func (r *Repository) MarkNotificationsRead(ctx context.Context, userID, notificationID int) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE notifications
+		SET read_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1
+			AND ($2 = 0 OR notification_id = $2)
+			AND read_at IS NULL
+	`, userID, notificationID)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка обновления уведомлений: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -10,16 +10,17 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Repository struct {
-	db *pgx.Conn
+	db *pgxpool.Pool
 }
 
-func NewRepository(db *pgx.Conn) *Repository {
+func NewRepository(db *pgxpool.Pool) *Repository {
 	return &Repository{db: db}
 }
-func InitSchemaFromFile(ctx context.Context, conn *pgx.Conn, logger *slog.Logger) error {
+func InitSchemaFromFile(ctx context.Context, conn *pgxpool.Pool, logger *slog.Logger) error {
 	// Читаем SQL файл
 	sqlBytes, err := os.ReadFile("database/schema.sql")
 	if err != nil {
@@ -43,24 +44,64 @@ func InitSchemaFromFile(ctx context.Context, conn *pgx.Conn, logger *slog.Logger
 }
 func (r *Repository) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
 	query := `
-		INSERT INTO users (email, password_hash, role, name, surname ) 
-		VALUES ($1, $2, $3, $4, $5) 
-		RETURNING id, email, role, name, surname, created_at
+		INSERT INTO users (email, password_hash, role, name, surname, language) 
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')) 
+		RETURNING id, email, role, name, surname, created_at, COALESCE(language, '')
 	`
 
-	err := r.db.QueryRow(ctx, query, user.Email, user.Password, &user.Role, user.Name, user.Surname).Scan(
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, query, user.Email, user.Password, &user.Role, user.Name, user.Surname, user.Language).Scan(
 		&user.ID,
 		&user.Email,
 		&user.Role,
 		&user.Name,
 		&user.Surname,
 		&user.CreatedAt,
+		&user.Language,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("ошибка создания пользователя: %w", err)
 	}
 
+	err = insertOutboxEvent(ctx, tx, models.EventUserRegistered, map[string]any{
+		"user_id": user.ID,
+		"email":   user.Email,
+		"role":    user.Role,
+		"name":    user.Name.String,
+		"surname": user.Surname.String,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// у учётной записи студента сразу есть карточка без группы: группу задаёт
+	// PUT /students/:id/group или импорт строкой с email пользователя, а подписчики
+	// узнают о студенте так же, как после импорта
+	if user.Role == "student" {
+		student := models.Student{Name: user.Name.String, Surname: user.Surname.String, UserId: user.ID}
+		err := tx.QueryRow(ctx, `
+			INSERT INTO students (user_id, name, surname)
+			VALUES ($1, $2, $3)
+			RETURNING student_id
+		`, student.UserId, student.Name, student.Surname).Scan(&student.StudentID)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка создания студента: %w", err)
+		}
+		if err := insertOutboxEvent(ctx, tx, models.EventStudentCreated, student); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
 	return user, nil
 }
 func (r *Repository) CreateTeacher(ctx context.Context, teacher *models.Teacher) error {
@@ -81,26 +122,9 @@ func (r *Repository) CreateTeacher(ctx context.Context, teacher *models.Teacher)
 	return nil
 }
 
-func (r *Repository) SetInfoToTeacher(ctx context.Context, teacherID, subjectID int) error {
-	query := `
-		UPDATE teachers 
-		SET subject_id = $1 
-		WHERE id = $2
-		RETURNING id
-	`
-	var updatedID int
-	err := r.db.QueryRow(ctx, query, subjectID, teacherID).Scan(&updatedID)
-	if err != nil {
-		return fmt.Errorf("ошибка обновления предмета учителя: %w", err)
-	}
-
-	return nil
-
-}
-
 func (r *Repository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, email, password_hash, role, name, surname, created_at 
+		SELECT id, email, password_hash, role, name, surname, created_at, COALESCE(language, '')
 		FROM users 
 		WHERE email = $1
 	`
@@ -114,6 +138,7 @@ func (r *Repository) GetUserByEmail(ctx context.Context, email string) (*models.
 		&user.Name,
 		&user.Surname,
 		&user.CreatedAt,
+		&user.Language,
 	)
 
 	if err != nil {
@@ -128,14 +153,17 @@ func (r *Repository) GetUserByEmail(ctx context.Context, email string) (*models.
 
 func (r *Repository) GetUserByID(ctx context.Context, id int) (*models.User, error) {
 	query := `
-		SELECT id, email, password_hash, created_at 
+		SELECT id, email, password_hash, COALESCE(role, 'student'), name, surname, status, created_at,
+		       COALESCE(language, '')
 		FROM users 
 		WHERE id = $1
 	`
 
 	user := &models.User{}
 	err := r.db.QueryRow(ctx, query, id).Scan(
-		&user.ID, &user.Email, &user.Password, &user.CreatedAt,
+		&user.ID, &user.Email, &user.Password, &user.Role,
+		&user.Name, &user.Surname, &user.Status, &user.CreatedAt,
+		&user.Language,
 	)
 
 	if err != nil {
@@ -148,6 +176,29 @@ func (r *Repository) GetUserByID(ctx context.Context, id int) (*models.User, err
 	return user, nil
 }
 
+// SetUserLanguage сохраняет язык сообщений API, выбранный пользователем.
+func (r *Repository) SetUserLanguage(ctx context.Context, userID int, lang string) error {
+	tag, err := r.db.Exec(ctx, `UPDATE users SET language = $1 WHERE id = $2`, lang, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения языка: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("пользователь с ID %d не найден", userID)
+	}
+	return nil
+}
+
+const upsertAttendanceQuery = `
+	INSERT INTO attendance (student_id, attendance_date, is_present, schedule_id, status, minutes_late, note)
+	VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+	ON CONFLICT (student_id, schedule_id, attendance_date)
+	DO UPDATE SET
+		is_present = EXCLUDED.is_present,
+		status = EXCLUDED.status,
+		minutes_late = EXCLUDED.minutes_late,
+		note = EXCLUDED.note
+`
+
 func (r *Repository) CreateAttendance(ctx context.Context, req models.AttendanceRequest) error {
 
 	visitDate, err := time.Parse("02.01.2006", req.VisitDay)
@@ -155,21 +206,42 @@ func (r *Repository) CreateAttendance(ctx context.Context, req models.Attendance
 		return fmt.Errorf("неверный формат даты: %w", err)
 	}
 
-	query := `
-		INSERT INTO attendance (student_id, attendance_date, is_present, schedule_id)
-		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (student_id, schedule_id, attendance_date) 
-		DO UPDATE SET is_present = EXCLUDED.is_present
-	`
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-	_, err = r.db.Exec(ctx, query, req.StudentID, visitDate, req.Visited, req.ScheduleID)
+	_, err = tx.Exec(ctx, upsertAttendanceQuery,
+		req.StudentID, visitDate, req.Visited, req.ScheduleID,
+		req.Status, req.MinutesLate, req.Note,
+	)
 	if err != nil {
 		return fmt.Errorf("ошибка создания записи посещаемости: %w", err)
 	}
 
+	if err := insertOutboxEvent(ctx, tx, models.EventAttendanceRecorded, attendanceRecordedEvent(req.StudentID, req.ScheduleID, req)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
 	return nil
 }
 
+func attendanceRecordedEvent(studentID, scheduleID int, req models.AttendanceRequest) models.AttendanceRecordedEvent {
+	return models.AttendanceRecordedEvent{
+		StudentID:   studentID,
+		ScheduleID:  scheduleID,
+		VisitDay:    req.VisitDay,
+		Status:      req.Status,
+		Visited:     req.Visited,
+		MinutesLate: req.MinutesLate,
+	}
+}
+
 func (r *Repository) GetAttendanceBySubjectID(ctx context.Context, subjectID int) ([]models.AttendanceBySubject, error) {
 
 	query := `
@@ -179,7 +251,10 @@ func (r *Repository) GetAttendanceBySubjectID(ctx context.Context, subjectID int
 			s.surname,
 			g.group_name,
 			TO_CHAR(a.attendance_date, 'DD.MM.YYYY') as visit_day,
-			a.is_present as visited
+			a.is_present as visited,
+			a.status,
+			a.minutes_late,
+			a.note
 		FROM attendance a
 		JOIN students s ON a.student_id = s.student_id
 		JOIN groups g ON s.group_id = g.group_id
@@ -203,6 +278,9 @@ func (r *Repository) GetAttendanceBySubjectID(ctx context.Context, subjectID int
 			&attendance.GroupName,
 			&attendance.VisitDay,
 			&attendance.Visited,
+			&attendance.Status,
+			&attendance.MinutesLate,
+			&attendance.Note,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("ошибка сканирования посещаемости: %w", err)
@@ -221,10 +299,14 @@ func (r *Repository) GetAttendanceByStudentID(ctx context.Context, studentID int
 
 	query := `
 		SELECT 
+			a.attendance_id,
 			a.schedule_id as subject_id,
 			sch.lesson_name as subject_name,
 			TO_CHAR(a.attendance_date, 'DD.MM.YYYY') as visit_day,
-			a.is_present as visited
+			a.is_present as visited,
+			a.status,
+			a.minutes_late,
+			a.note
 		FROM attendance a
 		JOIN schedule sch ON a.schedule_id = sch.schedule_id
 		WHERE a.student_id = $1
@@ -241,10 +323,14 @@ func (r *Repository) GetAttendanceByStudentID(ctx context.Context, studentID int
 	for rows.Next() {
 		var attendance models.AttendanceByStudent
 		err := rows.Scan(
+			&attendance.AttendanceID,
 			&attendance.SubjectID,
 			&attendance.SubjectName,
 			&attendance.VisitDay,
 			&attendance.Visited,
+			&attendance.Status,
+			&attendance.MinutesLate,
+			&attendance.Note,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("ошибка сканирования посещаемости: %w", err)
@@ -261,7 +347,7 @@ func (r *Repository) GetAttendanceByStudentID(ctx context.Context, studentID int
 
 func (r *Repository) GetStudent(ctx context.Context, id int) (*models.Student, error) {
 	query := `
-		SELECT student_id, name, surname, gender, birthday, group_id 
+		SELECT ` + studentColumns + `
 		FROM students 
 		WHERE student_id = $1
 	`
@@ -274,6 +360,7 @@ func (r *Repository) GetStudent(ctx context.Context, id int) (*models.Student, e
 		&student.Gender,
 		&student.Birthday,
 		&student.GroupID,
+		&student.UserId,
 	)
 
 	if err != nil {
@@ -286,6 +373,68 @@ func (r *Repository) GetStudent(ctx context.Context, id int) (*models.Student, e
 	return &student, nil
 }
 
+func (r *Repository) GetStudentByUserID(ctx context.Context, userID int) (*models.Student, error) {
+	query := `
+		SELECT ` + studentColumns + `
+		FROM students 
+		WHERE user_id = $1
+	`
+
+	var student models.Student
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&student.StudentID,
+		&student.Name,
+		&student.Surname,
+		&student.Gender,
+		&student.Birthday,
+		&student.GroupID,
+		&student.UserId,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("студент для пользователя %d не найден", userID)
+		}
+		return nil, fmt.Errorf("ошибка получения студента: %w", err)
+	}
+
+	return &student, nil
+}
+
+// SetStudentGroup переводит студента в группу, например карточку из регистрации, и
+// записывает его на предметы группы в текущем семестре.
+func (r *Repository) SetStudentGroup(ctx context.Context, studentID, groupID int) (*models.Student, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM groups WHERE group_id = $1)`, groupID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("ошибка проверки группы: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("группа с ID %d не найдена", groupID)
+	}
+
+	tag, err := tx.Exec(ctx, `UPDATE students SET group_id = $1 WHERE student_id = $2`, groupID, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка перевода студента: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("студент с ID %d не найден", studentID)
+	}
+	if err := enrollInGroupOfferings(ctx, tx, studentID, groupID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+	return r.GetStudent(ctx, studentID)
+}
+
 func (r *Repository) GetAllSchedule(ctx context.Context) ([]models.Schedule, error) {
 	query := `
 		SELECT group_id, lesson_name, start_time, end_time
@@ -359,7 +508,7 @@ func (r *Repository) GetGroupSchedule(ctx context.Context, groupID int) ([]model
 
 func (r *Repository) GetAllStudents(ctx context.Context) ([]models.Student, error) {
 	query := `
-		SELECT student_id, name, surname, gender, birthday, group_id 
+		SELECT ` + studentColumns + `
 		FROM students 
 		ORDER BY student_id
 	`
@@ -380,6 +529,7 @@ func (r *Repository) GetAllStudents(ctx context.Context) ([]models.Student, erro
 			&student.Gender,
 			&student.Birthday,
 			&student.GroupID,
+			&student.UserId,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("ошибка сканирования студента: %w", err)
@@ -396,9 +546,20 @@ func (r *Repository) GetAllStudents(ctx context.Context) ([]models.Student, erro
 
 func (r *Repository) GetAllTeachers(ctx context.Context) ([]models.Teacher, error) {
 	query := `
-		SELECT id,user_id, name, surname, gender, subject
-		FROM teachers 
-		ORDER BY id
+		SELECT
+			t.id,
+			t.user_id,
+			t.name,
+			t.surname,
+			t.gender,
+			(
+				SELECT STRING_AGG(DISTINCT sub.subject_name, ', ')
+				FROM teacher_assignments ta
+				JOIN subjects sub ON sub.subject_id = ta.subject_id
+				WHERE ta.teacher_id = t.id
+			) AS subject
+		FROM teachers t
+		ORDER BY t.id
 	`
 
 	rows, err := r.db.Query(ctx, query)
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"hw_5_jwt/internal/models"
+)
+
+// AttendanceChannel — канал NOTIFY, в который триггер outbox_events публикует
+// события посещаемости (database/schema.sql).
+const AttendanceChannel = "attendance_events"
+
+// ListenAttendanceEvents занимает отдельное соединение, подписывается на
+// AttendanceChannel и передаёт содержимое уведомлений в handle. Возвращает ошибку
+// при обрыве соединения или отмене ctx.
+func (r *Repository) ListenAttendanceEvents(ctx context.Context, handle func(payload []byte)) error {
+	pooled, err := r.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка получения соединения: %w", err)
+	}
+	// соединение с LISTEN не возвращается в пул, иначе уведомления получит чужой запрос
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+AttendanceChannel); err != nil {
+		return fmt.Errorf("ошибка подписки на %s: %w", AttendanceChannel, err)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("ошибка ожидания уведомления: %w", err)
+		}
+		handle([]byte(notification.Payload))
+	}
+}
+
+// GetAttendanceStreamEvents возвращает события посещаемости после afterID по порядку —
+// для клиентов, переподключившихся с Last-Event-ID.
+func (r *Repository) GetAttendanceStreamEvents(ctx context.Context, afterID int64, limit int) ([]models.AttendanceStreamEvent, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT attendance_stream_event(e)
+		FROM outbox_events e
+		WHERE e.event_type = $1 AND e.event_id > $2
+		ORDER BY e.event_id
+		LIMIT $3
+	`, models.EventAttendanceRecorded, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения событий посещаемости: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.AttendanceStreamEvent
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования события: %w", err)
+		}
+		var event models.AttendanceStreamEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return nil, fmt.Errorf("ошибка разбора события: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации событий: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetCuratedGroupIDs возвращает группы, куратором которых назначен пользователь.
+func (r *Repository) GetCuratedGroupIDs(ctx context.Context, userID int) ([]int, error) {
+	rows, err := r.db.Query(ctx, `SELECT group_id FROM groups WHERE curator_user_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения курируемых групп: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования группы: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации групп: %w", err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hw_5_jwt/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *Repository) GetLessonRoster(ctx context.Context, scheduleID int, visitDay string) (*models.LessonRoster, error) {
+	visitDate, err := time.Parse("02.01.2006", visitDay)
+	if err != nil {
+		return nil, fmt.Errorf("неверный формат даты: %w", err)
+	}
+
+	roster := &models.LessonRoster{ScheduleID: scheduleID, VisitDay: visitDay}
+	err = r.db.QueryRow(ctx, `
+		SELECT group_id, COALESCE(lesson_name, '')
+		FROM schedule
+		WHERE schedule_id = $1
+	`, scheduleID).Scan(&roster.GroupID, &roster.LessonName)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("занятие с ID %d не найдено", scheduleID)
+		}
+		return nil, fmt.Errorf("ошибка получения занятия: %w", err)
+	}
+
+	query := `
+		SELECT
+			s.student_id,
+			s.name,
+			s.surname,
+			COALESCE(a.is_present, false) as visited,
+			COALESCE(a.status, 'absent') as status,
+			a.attendance_id IS NOT NULL as marked
+		FROM students s
+		LEFT JOIN attendance a
+			ON a.student_id = s.student_id
+			AND a.schedule_id = $1
+			AND a.attendance_date = $2
+		WHERE s.student_id IN (
+			SELECT e.student_id
+			FROM schedule sch
+			JOIN subject_offerings o
+				ON o.subject_id = sch.subject_id
+				AND o.group_id = sch.group_id
+				AND o.term = term_of($2)
+			JOIN enrollments e ON e.offering_id = o.offering_id AND e.status = 'enrolled'
+			WHERE sch.schedule_id = $1
+		)
+		ORDER BY s.surname, s.name
+	`
+
+	rows, err := r.db.Query(ctx, query, scheduleID, visitDate)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка группы: %w", err)
+	}
+	defer rows.Close()
+
+	roster.Students = []models.RosterEntry{}
+	for rows.Next() {
+		var entry models.RosterEntry
+		err := rows.Scan(
+			&entry.StudentID,
+			&entry.StudentName,
+			&entry.StudentSurname,
+			&entry.Visited,
+			&entry.Status,
+			&entry.Marked,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования списка группы: %w", err)
+		}
+		roster.Students = append(roster.Students, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации списка группы: %w", err)
+	}
+
+	return roster, nil
+}
+
+func (r *Repository) CreateAttendanceBulk(ctx context.Context, req models.BulkAttendanceRequest) error {
+	visitDate, err := time.Parse("02.01.2006", req.VisitDay)
+	if err != nil {
+		return fmt.Errorf("неверный формат даты: %w", err)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for _, student := range req.Students {
+		batch.Queue(upsertAttendanceQuery,
+			student.StudentID, visitDate, student.Visited, req.ScheduleID,
+			student.Status, student.MinutesLate, student.Note,
+		)
+	}
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("ошибка записи посещаемости группы: %w", err)
+	}
+
+	for _, student := range req.Students {
+		event := attendanceRecordedEvent(student.StudentID, req.ScheduleID, models.AttendanceRequest{
+			VisitDay:    req.VisitDay,
+			Status:      student.Status,
+			Visited:     student.Visited,
+			MinutesLate: student.MinutesLate,
+		})
+		if err := insertOutboxEvent(ctx, tx, models.EventAttendanceRecorded, event); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,386 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"hw_5_jwt/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RoomWeeklyHours — доступное время аудитории в неделю (6 дней по 12 часов),
+// от него считается загрузка в отчёте.
+const RoomWeeklyHours = 72.0
+
+// querier — общий интерфейс пула и транзакции для запросов на чтение.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+const roomColumns = `room_id, name, building, capacity, equipment, active, created_at`
+
+func scanRoom(row pgx.Row) (*models.Room, error) {
+	var room models.Room
+	err := row.Scan(
+		&room.RoomID,
+		&room.Name,
+		&room.Building,
+		&room.Capacity,
+		&room.Equipment,
+		&room.Active,
+		&room.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (r *Repository) CreateRoom(ctx context.Context, req models.RoomRequest) (*models.Room, error) {
+	if req.Equipment == nil {
+		req.Equipment = []string{}
+	}
+
+	room, err := scanRoom(r.db.QueryRow(ctx, `
+		INSERT INTO rooms (name, building, capacity, equipment)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING `+roomColumns,
+		req.Name, req.Building, req.Capacity, req.Equipment,
+	))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("аудитория %s уже существует", req.Name)
+		}
+		return nil, fmt.Errorf("ошибка создания аудитории: %w", err)
+	}
+
+	return room, nil
+}
+
+func (r *Repository) queryRooms(ctx context.Context, where string, args ...any) ([]models.Room, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+roomColumns+` FROM rooms `+where+` ORDER BY building, name`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения аудиторий: %w", err)
+	}
+	defer rows.Close()
+
+	rooms := []models.Room{}
+	for rows.Next() {
+		room, err := scanRoom(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования аудитории: %w", err)
+		}
+		rooms = append(rooms, *room)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации аудиторий: %w", err)
+	}
+
+	return rooms, nil
+}
+
+func (r *Repository) ListRooms(ctx context.Context, f models.RoomFilter) ([]models.Room, error) {
+	if f.Equipment == nil {
+		f.Equipment = []string{}
+	}
+	return r.queryRooms(ctx, `
+		WHERE active
+			AND ($1 = '' OR building = $1)
+			AND capacity >= $2
+			AND equipment @> $3
+	`, f.Building, f.MinCapacity, f.Equipment)
+}
+
+// FindFreeRooms ищет активные аудитории без занятий в указанный день и интервал времени.
+func (r *Repository) FindFreeRooms(ctx context.Context, dayOfWeek int, start, end string, f models.RoomFilter) ([]models.Room, error) {
+	if f.Equipment == nil {
+		f.Equipment = []string{}
+	}
+	return r.queryRooms(ctx, `
+		WHERE active
+			AND ($1 = '' OR building = $1)
+			AND capacity >= $2
+			AND equipment @> $3
+			AND NOT EXISTS (
+				SELECT 1 FROM schedule s
+				WHERE s.room_id = rooms.room_id
+					AND s.day_of_week = $4
+					AND s.start_time < $6::time
+					AND s.end_time > $5::time
+			)
+	`, f.Building, f.MinCapacity, f.Equipment, dayOfWeek, start, end)
+}
+
+// checkRoom блокирует аудиторию до конца транзакции и проверяет, что она вмещает
+// группу и свободна в это время. excludeID — занятие, которое переносится.
+func checkRoom(ctx context.Context, tx pgx.Tx, roomID, groupID, dayOfWeek int, start, end string, excludeID int) error {
+	var capacity int
+	var active bool
+	err := tx.QueryRow(ctx, `
+		SELECT capacity, active FROM rooms WHERE room_id = $1 FOR UPDATE
+	`, roomID).Scan(&capacity, &active)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("аудитория с ID %d не найдена", roomID)
+		}
+		return fmt.Errorf("ошибка получения аудитории: %w", err)
+	}
+	if !active {
+		return fmt.Errorf("аудитория с ID %d не найдена", roomID)
+	}
+
+	var groupSize int
+	err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM students WHERE group_id = $1`, groupID).Scan(&groupSize)
+	if err != nil {
+		return fmt.Errorf("ошибка подсчёта студентов группы: %w", err)
+	}
+	if groupSize > capacity {
+		return fmt.Errorf("превышена вместимость аудитории: %d студентов при %d местах", groupSize, capacity)
+	}
+
+	var conflictID int
+	var conflictName string
+	err = tx.QueryRow(ctx, `
+		SELECT schedule_id, lesson_name
+		FROM schedule
+		WHERE room_id = $1
+			AND day_of_week = $2
+			AND start_time < $4::time
+			AND end_time > $3::time
+			AND schedule_id <> $5
+		ORDER BY start_time
+		LIMIT 1
+	`, roomID, dayOfWeek, start, end, excludeID).Scan(&conflictID, &conflictName)
+	if err == nil {
+		return fmt.Errorf("аудитория уже занята: занятие %d «%s»", conflictID, conflictName)
+	}
+	if err != pgx.ErrNoRows {
+		return fmt.Errorf("ошибка проверки занятости аудитории: %w", err)
+	}
+
+	return nil
+}
+
+const scheduleEntryQuery = `
+	SELECT
+		s.schedule_id,
+		COALESCE(s.group_id, 0),
+		s.subject_id,
+		sub.subject_name,
+		COALESCE(s.lesson_name, sub.subject_name),
+		COALESCE(s.day_of_week, 0),
+		COALESCE(TO_CHAR(s.start_time, 'HH24:MI'), ''),
+		COALESCE(TO_CHAR(s.end_time, 'HH24:MI'), ''),
+		s.room_id,
+		rm.name
+	FROM schedule s
+	JOIN subjects sub ON sub.subject_id = s.subject_id
+	LEFT JOIN rooms rm ON rm.room_id = s.room_id
+`
+
+func (r *Repository) queryScheduleEntries(ctx context.Context, q querier, where string, args ...any) ([]models.ScheduleEntry, error) {
+	rows, err := q.Query(ctx, scheduleEntryQuery+where+` ORDER BY s.day_of_week, s.start_time, s.group_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения расписания: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.ScheduleEntry{}
+	for rows.Next() {
+		var e models.ScheduleEntry
+		err := rows.Scan(
+			&e.ScheduleID,
+			&e.GroupID,
+			&e.SubjectID,
+			&e.SubjectName,
+			&e.LessonName,
+			&e.DayOfWeek,
+			&e.StartTime,
+			&e.EndTime,
+			&e.RoomID,
+			&e.RoomName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования расписания: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации расписания: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *Repository) getScheduleEntry(ctx context.Context, q querier, scheduleID int) (*models.ScheduleEntry, error) {
+	entries, err := r.queryScheduleEntries(ctx, q, `WHERE s.schedule_id = $1`, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("занятие с ID %d не найдено", scheduleID)
+	}
+	return &entries[0], nil
+}
+
+func (r *Repository) GetScheduleEntry(ctx context.Context, scheduleID int) (*models.ScheduleEntry, error) {
+	return r.getScheduleEntry(ctx, r.db, scheduleID)
+}
+
+func (r *Repository) GetRoomSchedule(ctx context.Context, roomID int) ([]models.ScheduleEntry, error) {
+	return r.queryScheduleEntries(ctx, r.db, `WHERE s.room_id = $1`, roomID)
+}
+
+// CreateScheduleEntry добавляет занятие в расписание; аудитория, если указана,
+// проверяется на вместимость и двойное бронирование.
+func (r *Repository) CreateScheduleEntry(ctx context.Context, req models.ScheduleEntryRequest) (*models.ScheduleEntry, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM groups WHERE group_id = $1)
+			AND EXISTS (SELECT 1 FROM subjects WHERE subject_id = $2)
+	`, req.GroupID, req.SubjectID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки группы и предмета: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("группа %d или предмет %d не найдены", req.GroupID, req.SubjectID)
+	}
+
+	if req.RoomID != nil {
+		if err := checkRoom(ctx, tx, *req.RoomID, req.GroupID, req.DayOfWeek, req.StartTime, req.EndTime, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	var id int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO schedule (group_id, subject_id, lesson_name, day_of_week, start_time, end_time, room_id)
+		VALUES ($1, $2, $3, $4, $5::time, $6::time, $7)
+		RETURNING schedule_id
+	`, req.GroupID, req.SubjectID, req.LessonName, req.DayOfWeek, req.StartTime, req.EndTime, req.RoomID).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания занятия: %w", err)
+	}
+
+	entry, err := r.getScheduleEntry(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertOutboxEvent(ctx, tx, models.EventScheduleChanged, models.ScheduleChangedEvent{
+		Action:        "created",
+		ScheduleEntry: *entry,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return entry, nil
+}
+
+// SetScheduleRoom назначает занятию аудиторию или снимает её (roomID == nil).
+func (r *Repository) SetScheduleRoom(ctx context.Context, scheduleID int, roomID *int) (*models.ScheduleEntry, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var groupID, dayOfWeek int
+	var start, end string
+	err = tx.QueryRow(ctx, `
+		SELECT group_id, day_of_week, TO_CHAR(start_time, 'HH24:MI'), TO_CHAR(end_time, 'HH24:MI')
+		FROM schedule
+		WHERE schedule_id = $1
+		FOR UPDATE
+	`, scheduleID).Scan(&groupID, &dayOfWeek, &start, &end)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("занятие с ID %d не найдено", scheduleID)
+		}
+		return nil, fmt.Errorf("ошибка получения занятия: %w", err)
+	}
+
+	if roomID != nil {
+		if err := checkRoom(ctx, tx, *roomID, groupID, dayOfWeek, start, end, scheduleID); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE schedule SET room_id = $1 WHERE schedule_id = $2`, roomID, scheduleID); err != nil {
+		return nil, fmt.Errorf("ошибка обновления аудитории занятия: %w", err)
+	}
+
+	entry, err := r.getScheduleEntry(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertOutboxEvent(ctx, tx, models.EventScheduleChanged, models.ScheduleChangedEvent{
+		Action:        "room_changed",
+		ScheduleEntry: *entry,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return entry, nil
+}
+
+// GetRoomOccupancy считает недельную загрузку аудиторий по расписанию.
+func (r *Repository) GetRoomOccupancy(ctx context.Context, building string) ([]models.RoomOccupancy, error) {
+	query := `
+		SELECT
+			rm.room_id,
+			rm.name,
+			rm.building,
+			rm.capacity,
+			COUNT(s.schedule_id),
+			COALESCE(SUM(EXTRACT(EPOCH FROM (s.end_time - s.start_time)) / 3600), 0)::float8 AS hours
+		FROM rooms rm
+		LEFT JOIN schedule s ON s.room_id = rm.room_id
+		WHERE rm.active AND ($1 = '' OR rm.building = $1)
+		GROUP BY rm.room_id
+		ORDER BY hours DESC, rm.building, rm.name
+	`
+
+	rows, err := r.db.Query(ctx, query, building)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расчёта загрузки аудиторий: %w", err)
+	}
+	defer rows.Close()
+
+	report := []models.RoomOccupancy{}
+	for rows.Next() {
+		var o models.RoomOccupancy
+		err := rows.Scan(&o.RoomID, &o.Name, &o.Building, &o.Capacity, &o.Lessons, &o.WeeklyHours)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования загрузки аудитории: %w", err)
+		}
+		o.Utilization = math.Round(o.WeeklyHours/RoomWeeklyHours*1000) / 1000
+		report = append(report, o)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации загрузки аудиторий: %w", err)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,246 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"hw_5_jwt/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Посещённым считается present, late и remote; уважительные пропуски не входят в знаменатель.
+const attendanceCountsColumns = `
+	COUNT(*),
+	COUNT(*) FILTER (WHERE a.status = 'present'),
+	COUNT(*) FILTER (WHERE a.status = 'late'),
+	COUNT(*) FILTER (WHERE a.status = 'absent'),
+	COUNT(*) FILTER (WHERE a.status = 'excused'),
+	COUNT(*) FILTER (WHERE a.status = 'remote'),
+	ROUND(
+		COUNT(*) FILTER (WHERE a.status IN ('present', 'late', 'remote'))::numeric
+		/ NULLIF(COUNT(*) FILTER (WHERE a.status <> 'excused'), 0),
+		4
+	)::float8
+`
+
+const attendanceRateExpr = `
+	COUNT(*) FILTER (WHERE a.status IN ('present', 'late', 'remote'))::numeric
+	/ NULLIF(COUNT(*) FILTER (WHERE a.status <> 'excused'), 0)
+`
+
+const statsFromClause = `
+	FROM attendance a
+	JOIN students s ON s.student_id = a.student_id
+	JOIN groups g ON g.group_id = s.group_id
+	JOIN schedule sch ON sch.schedule_id = a.schedule_id
+	LEFT JOIN subjects subj ON subj.subject_id = sch.subject_id
+	WHERE a.attendance_date BETWEEN $1 AND $2
+		AND ($3 = 0 OR s.group_id = $3)
+		AND ($4 = 0 OR sch.subject_id = $4)
+		AND ($5 = 0 OR a.student_id = $5)
+		AND ($6 = '' OR g.faculty = $6)
+`
+
+func countsDest(counts *models.AttendanceCounts) []any {
+	return []any{
+		&counts.Total,
+		&counts.Present,
+		&counts.Late,
+		&counts.Absent,
+		&counts.Excused,
+		&counts.Remote,
+		&counts.Rate,
+	}
+}
+
+func (r *Repository) queryStats(ctx context.Context, columns, tail string, f models.StatsFilter, args ...any) (pgx.Rows, error) {
+	query := `SELECT ` + columns + `, ` + attendanceCountsColumns + statsFromClause + tail
+	params := append([]any{f.From, f.To, f.GroupID, f.SubjectID, f.StudentID, f.Faculty}, args...)
+	return r.db.Query(ctx, query, params...)
+}
+
+func (r *Repository) GetStudentAttendanceStats(ctx context.Context, f models.StatsFilter) ([]models.StudentAttendanceStats, error) {
+	rows, err := r.queryStats(ctx,
+		`s.student_id, s.name, s.surname, g.group_name`,
+		`GROUP BY s.student_id, s.name, s.surname, g.group_name
+		ORDER BY g.group_name, s.surname, s.name`,
+		f,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения статистики по студентам: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []models.StudentAttendanceStats{}
+	for rows.Next() {
+		var item models.StudentAttendanceStats
+		dest := append([]any{&item.StudentID, &item.StudentName, &item.StudentSurname, &item.GroupName}, countsDest(&item.AttendanceCounts)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования статистики: %w", err)
+		}
+		stats = append(stats, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации статистики: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (r *Repository) GetSubjectAttendanceStats(ctx context.Context, f models.StatsFilter) ([]models.SubjectAttendanceStats, error) {
+	rows, err := r.queryStats(ctx,
+		`COALESCE(sch.subject_id, 0), COALESCE(subj.subject_name, sch.lesson_name, '')`,
+		`GROUP BY 1, 2
+		ORDER BY 2`,
+		f,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения статистики по предметам: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []models.SubjectAttendanceStats{}
+	for rows.Next() {
+		var item models.SubjectAttendanceStats
+		dest := append([]any{&item.SubjectID, &item.SubjectName}, countsDest(&item.AttendanceCounts)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования статистики: %w", err)
+		}
+		stats = append(stats, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации статистики: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (r *Repository) GetGroupAttendanceStats(ctx context.Context, f models.StatsFilter) ([]models.GroupAttendanceStats, error) {
+	rows, err := r.queryStats(ctx,
+		`g.group_id, g.group_name, COALESCE(g.faculty, '')`,
+		`GROUP BY g.group_id, g.group_name, g.faculty
+		ORDER BY g.group_name`,
+		f,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения статистики по группам: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []models.GroupAttendanceStats{}
+	for rows.Next() {
+		var item models.GroupAttendanceStats
+		dest := append([]any{&item.GroupID, &item.GroupName, &item.Faculty}, countsDest(&item.AttendanceCounts)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования статистики: %w", err)
+		}
+		stats = append(stats, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации статистики: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (r *Repository) GetFacultyAttendanceStats(ctx context.Context, f models.StatsFilter) ([]models.FacultyAttendanceStats, error) {
+	rows, err := r.queryStats(ctx,
+		`COALESCE(g.faculty, '')`,
+		`GROUP BY 1
+		ORDER BY 1`,
+		f,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения статистики по факультетам: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []models.FacultyAttendanceStats{}
+	for rows.Next() {
+		var item models.FacultyAttendanceStats
+		dest := append([]any{&item.Faculty}, countsDest(&item.AttendanceCounts)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования статистики: %w", err)
+		}
+		stats = append(stats, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации статистики: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (r *Repository) GetWeeklyAttendanceStats(ctx context.Context, f models.StatsFilter) ([]models.WeeklyAttendanceStats, error) {
+	rows, err := r.queryStats(ctx,
+		`TO_CHAR(DATE_TRUNC('week', a.attendance_date), 'DD.MM.YYYY')`,
+		`GROUP BY DATE_TRUNC('week', a.attendance_date)
+		ORDER BY DATE_TRUNC('week', a.attendance_date)`,
+		f,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения недельной статистики: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []models.WeeklyAttendanceStats{}
+	for rows.Next() {
+		var item models.WeeklyAttendanceStats
+		dest := append([]any{&item.WeekStart}, countsDest(&item.AttendanceCounts)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования статистики: %w", err)
+		}
+		stats = append(stats, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации статистики: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetAtRiskStudents возвращает пары студент–предмет с посещаемостью ниже threshold
+// среди тех, у кого набралось хотя бы minLessons занятий.
+func (r *Repository) GetAtRiskStudents(ctx context.Context, f models.StatsFilter, threshold float64, minLessons int) ([]models.AtRiskStudent, error) {
+	rows, err := r.queryStats(ctx,
+		`s.student_id, s.name, s.surname, g.group_name,
+		COALESCE(sch.subject_id, 0), COALESCE(subj.subject_name, sch.lesson_name, '')`,
+		`GROUP BY 1, 2, 3, 4, 5, 6
+		HAVING COUNT(*) >= $8 AND `+attendanceRateExpr+` < $7
+		ORDER BY `+attendanceRateExpr+`, g.group_name, s.surname`,
+		f, threshold, minLessons,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения студентов группы риска: %w", err)
+	}
+	defer rows.Close()
+
+	students := []models.AtRiskStudent{}
+	for rows.Next() {
+		var item models.AtRiskStudent
+		dest := append([]any{
+			&item.StudentID,
+			&item.StudentName,
+			&item.StudentSurname,
+			&item.GroupName,
+			&item.SubjectID,
+			&item.SubjectName,
+		}, countsDest(&item.AttendanceCounts)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования студентов группы риска: %w", err)
+		}
+		students = append(students, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации студентов группы риска: %w", err)
+	}
+
+	return students, nil
+}
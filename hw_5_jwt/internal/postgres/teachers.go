@@ -0,0 +1,344 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"hw_5_jwt/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// weeklyHoursExpr — часы в неделю по расписанию предмета в группе; ta — псевдоним назначения.
+const weeklyHoursExpr = `
+	COALESCE((
+		SELECT SUM(EXTRACT(EPOCH FROM (sch.end_time - sch.start_time)) / 3600)
+		FROM schedule sch
+		WHERE sch.subject_id = ta.subject_id AND sch.group_id = ta.group_id
+	), 0)::float8
+`
+
+// teacherLoadQuery считает нагрузку без двойного учёта: лектор и ассистент
+// одного предмета в одной группе ведут одни и те же занятия.
+const teacherLoadQuery = `
+	SELECT COALESCE(SUM(hours), 0)::float8
+	FROM (
+		SELECT DISTINCT ON (ta.subject_id, ta.group_id) ` + weeklyHoursExpr + ` AS hours
+		FROM teacher_assignments ta
+		WHERE ta.teacher_id = $1 AND ta.term = $2
+	) loads
+`
+
+func (r *Repository) assign(ctx context.Context, tx pgx.Tx, teacherID int, req models.TeacherAssignmentRequest) (int, error) {
+	var maxHours float64
+	err := tx.QueryRow(ctx, `
+		SELECT max_weekly_hours::float8 FROM teachers WHERE id = $1 FOR UPDATE
+	`, teacherID).Scan(&maxHours)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, fmt.Errorf("учитель с ID %d не найден", teacherID)
+		}
+		return 0, fmt.Errorf("ошибка получения учителя: %w", err)
+	}
+
+	var current float64
+	if err := tx.QueryRow(ctx, teacherLoadQuery, teacherID, req.Term).Scan(&current); err != nil {
+		return 0, fmt.Errorf("ошибка расчёта нагрузки: %w", err)
+	}
+
+	// вторая роль в той же группе не добавляет часов
+	var extra float64
+	err = tx.QueryRow(ctx, `
+		SELECT CASE
+			WHEN EXISTS (
+				SELECT 1 FROM teacher_assignments
+				WHERE teacher_id = $1 AND subject_id = $2 AND group_id = $3 AND term = $4
+			) THEN 0
+			ELSE COALESCE((
+				SELECT SUM(EXTRACT(EPOCH FROM (end_time - start_time)) / 3600)
+				FROM schedule
+				WHERE subject_id = $2 AND group_id = $3
+			), 0)
+		END::float8
+	`, teacherID, req.SubjectID, req.GroupID, req.Term).Scan(&extra)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка расчёта нагрузки: %w", err)
+	}
+
+	if current+extra > maxHours {
+		return 0, fmt.Errorf("превышена максимальная нагрузка: %.1f из %.1f ч в неделю", current+extra, maxHours)
+	}
+
+	var id int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO teacher_assignments (teacher_id, subject_id, group_id, term, role)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (teacher_id, subject_id, group_id, term, role) DO NOTHING
+		RETURNING assignment_id
+	`, teacherID, req.SubjectID, req.GroupID, req.Term, req.Role).Scan(&id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, fmt.Errorf("учитель %d уже назначен на предмет %d в группе %d", teacherID, req.SubjectID, req.GroupID)
+		}
+		return 0, fmt.Errorf("ошибка создания назначения: %w", err)
+	}
+
+	return id, nil
+}
+
+// AssignTeacher назначает учителя на предмет в группе с проверкой максимальной нагрузки.
+func (r *Repository) AssignTeacher(ctx context.Context, teacherID int, req models.TeacherAssignmentRequest) (*models.TeacherAssignment, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	id, err := r.assign(ctx, tx, teacherID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	assignments, err := r.queryAssignments(ctx, `WHERE ta.assignment_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &assignments[0], nil
+}
+
+// SetInfoToTeacher назначает учителя лектором предмета во всех группах, где предмет есть в расписании.
+// Прежние назначения не затираются.
+func (r *Repository) SetInfoToTeacher(ctx context.Context, teacherID, subjectID int, term string) ([]models.TeacherAssignment, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT group_id FROM schedule
+		WHERE subject_id = $1 AND group_id IS NOT NULL
+		ORDER BY group_id
+	`, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения групп предмета: %w", err)
+	}
+
+	groupIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сканирования групп предмета: %w", err)
+	}
+	if len(groupIDs) == 0 {
+		return nil, fmt.Errorf("предмет %d не найден в расписании", subjectID)
+	}
+
+	var ids []int
+	for _, groupID := range groupIDs {
+		id, err := r.assign(ctx, tx, teacherID, models.TeacherAssignmentRequest{
+			SubjectID: subjectID,
+			GroupID:   groupID,
+			Term:      term,
+			Role:      models.AssignmentLecturer,
+		})
+		if err != nil {
+			if strings.Contains(err.Error(), "уже назначен") {
+				continue
+			}
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return []models.TeacherAssignment{}, nil
+	}
+	return r.queryAssignments(ctx, `WHERE ta.assignment_id = ANY($1)`, ids)
+}
+
+func (r *Repository) queryAssignments(ctx context.Context, where string, args ...any) ([]models.TeacherAssignment, error) {
+	query := `
+		SELECT
+			ta.assignment_id,
+			ta.teacher_id,
+			ta.subject_id,
+			sub.subject_name,
+			ta.group_id,
+			g.group_name,
+			ta.term,
+			ta.role,
+			` + weeklyHoursExpr + `,
+			ta.created_at
+		FROM teacher_assignments ta
+		JOIN subjects sub ON sub.subject_id = ta.subject_id
+		JOIN groups g ON g.group_id = ta.group_id
+		` + where + `
+		ORDER BY ta.term DESC, sub.subject_name, g.group_name, ta.role
+	`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения назначений: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := []models.TeacherAssignment{}
+	for rows.Next() {
+		var a models.TeacherAssignment
+		err := rows.Scan(
+			&a.AssignmentID,
+			&a.TeacherID,
+			&a.SubjectID,
+			&a.SubjectName,
+			&a.GroupID,
+			&a.GroupName,
+			&a.Term,
+			&a.Role,
+			&a.WeeklyHours,
+			&a.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования назначения: %w", err)
+		}
+		assignments = append(assignments, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации назначений: %w", err)
+	}
+
+	return assignments, nil
+}
+
+func (r *Repository) GetTeacherAssignments(ctx context.Context, teacherID int, term string) ([]models.TeacherAssignment, error) {
+	return r.queryAssignments(ctx, `WHERE ta.teacher_id = $1 AND ($2 = '' OR ta.term = $2)`, teacherID, term)
+}
+
+func (r *Repository) DeleteTeacherAssignment(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM teacher_assignments WHERE assignment_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления назначения: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("назначение с ID %d не найдено", id)
+	}
+	return nil
+}
+
+func (r *Repository) SetTeacherMaxLoad(ctx context.Context, teacherID int, hours float64) error {
+	tag, err := r.db.Exec(ctx, `UPDATE teachers SET max_weekly_hours = $1 WHERE id = $2`, hours, teacherID)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления максимальной нагрузки: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("учитель с ID %d не найден", teacherID)
+	}
+	return nil
+}
+
+// GetTeachingLoad возвращает недельную нагрузку всех учителей за семестр.
+func (r *Repository) GetTeachingLoad(ctx context.Context, term string) ([]models.TeacherLoad, error) {
+	query := `
+		WITH loads AS (
+			SELECT DISTINCT ON (ta.teacher_id, ta.subject_id, ta.group_id)
+				ta.teacher_id,
+				` + weeklyHoursExpr + ` AS hours
+			FROM teacher_assignments ta
+			WHERE ta.term = $1
+		),
+		counts AS (
+			SELECT teacher_id, COUNT(*) AS assignments
+			FROM teacher_assignments
+			WHERE term = $1
+			GROUP BY teacher_id
+		)
+		SELECT
+			t.id,
+			t.name,
+			t.surname,
+			COALESCE(c.assignments, 0),
+			COALESCE((SELECT SUM(l.hours) FROM loads l WHERE l.teacher_id = t.id), 0)::float8 AS weekly_hours,
+			t.max_weekly_hours::float8
+		FROM teachers t
+		LEFT JOIN counts c ON c.teacher_id = t.id
+		ORDER BY weekly_hours DESC, t.surname, t.name
+	`
+
+	rows, err := r.db.Query(ctx, query, term)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расчёта нагрузки: %w", err)
+	}
+	defer rows.Close()
+
+	loads := []models.TeacherLoad{}
+	for rows.Next() {
+		load := models.TeacherLoad{Term: term}
+		err := rows.Scan(
+			&load.TeacherID,
+			&load.Name,
+			&load.Surname,
+			&load.Assignments,
+			&load.WeeklyHours,
+			&load.MaxWeeklyHours,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования нагрузки: %w", err)
+		}
+		load.Overloaded = load.WeeklyHours > load.MaxWeeklyHours
+		loads = append(loads, load)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации нагрузки: %w", err)
+	}
+
+	return loads, nil
+}
+
+// IsAssignedTeacher проверяет, что пользователь назначен на предмет в группе в семестре.
+func (r *Repository) IsAssignedTeacher(ctx context.Context, userID, subjectID, groupID int, term string) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM teacher_assignments ta
+			JOIN teachers t ON t.id = ta.teacher_id
+			WHERE t.user_id = $1 AND ta.subject_id = $2 AND ta.group_id = $3 AND ta.term = $4
+		)
+	`, userID, subjectID, groupID, term).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки назначения учителя: %w", err)
+	}
+	return ok, nil
+}
+
+// IsLessonTeacher проверяет, что пользователь назначен на предмет и группу занятия
+// в семестре даты visitDate.
+func (r *Repository) IsLessonTeacher(ctx context.Context, scheduleID int, visitDate time.Time, userID int) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM schedule sch
+			JOIN teacher_assignments ta
+				ON ta.subject_id = sch.subject_id
+				AND ta.group_id = sch.group_id
+				AND ta.term = term_of($2::date)
+			JOIN teachers t ON t.id = ta.teacher_id
+			WHERE sch.schedule_id = $1 AND t.user_id = $3
+		)
+	`, scheduleID, visitDate, userID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки учителя занятия: %w", err)
+	}
+	return ok, nil
+}
@@ -0,0 +1,479 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/timetable"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *Repository) GetTeacherAvailability(ctx context.Context, teacherID int) ([]models.TeacherAvailability, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT day_of_week, TO_CHAR(start_time, 'HH24:MI'), TO_CHAR(end_time, 'HH24:MI'), preferred
+		FROM teacher_availability
+		WHERE teacher_id = $1
+		ORDER BY day_of_week, start_time
+	`, teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения доступности учителя: %w", err)
+	}
+
+	windows, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.TeacherAvailability])
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сканирования доступности учителя: %w", err)
+	}
+	return windows, nil
+}
+
+// SetTeacherAvailability заменяет окна доступности учителя целиком.
+func (r *Repository) SetTeacherAvailability(ctx context.Context, teacherID int, windows []models.TeacherAvailability) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM teachers WHERE id = $1)`, teacherID).Scan(&exists); err != nil {
+		return fmt.Errorf("ошибка получения учителя: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("учитель с ID %d не найден", teacherID)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM teacher_availability WHERE teacher_id = $1`, teacherID); err != nil {
+		return fmt.Errorf("ошибка удаления доступности учителя: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for _, w := range windows {
+		batch.Queue(`
+			INSERT INTO teacher_availability (teacher_id, day_of_week, start_time, end_time, preferred)
+			VALUES ($1, $2, $3::time, $4::time, $5)
+		`, teacherID, w.DayOfWeek, w.StartTime, w.EndTime, w.Preferred)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("ошибка сохранения доступности учителя: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+// LoadTimetableProblem собирает данные для генератора: учителей (по умолчанию — лектор
+// из назначений семестра), размеры групп, доступность, аудитории и занятия остальных групп.
+func (r *Repository) LoadTimetableProblem(ctx context.Context, term string, reqs []models.TimetableRequirement, useRooms bool) (timetable.Problem, error) {
+	p := timetable.Problem{Availability: map[int][]timetable.Window{}}
+
+	groupIDs := []int{}
+	teacherIDs := []int{}
+	seenGroup := map[int]bool{}
+	seenTeacher := map[int]bool{}
+
+	for _, req := range reqs {
+		var groupSize int
+		var subjectName string
+		err := r.db.QueryRow(ctx, `
+			SELECT
+				(SELECT COUNT(*) FROM students WHERE group_id = g.group_id),
+				sub.subject_name
+			FROM groups g, subjects sub
+			WHERE g.group_id = $1 AND sub.subject_id = $2
+		`, req.GroupID, req.SubjectID).Scan(&groupSize, &subjectName)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return p, fmt.Errorf("группа %d или предмет %d не найдены", req.GroupID, req.SubjectID)
+			}
+			return p, fmt.Errorf("ошибка получения группы и предмета: %w", err)
+		}
+
+		teacherID := req.TeacherID
+		if teacherID == 0 {
+			err := r.db.QueryRow(ctx, `
+				SELECT teacher_id FROM teacher_assignments
+				WHERE subject_id = $1 AND group_id = $2 AND term = $3 AND role = $4
+				ORDER BY teacher_id
+				LIMIT 1
+			`, req.SubjectID, req.GroupID, term, models.AssignmentLecturer).Scan(&teacherID)
+			if err != nil {
+				if err == pgx.ErrNoRows {
+					return p, fmt.Errorf("для предмета %d в группе %d не назначен лектор в семестре %s", req.SubjectID, req.GroupID, term)
+				}
+				return p, fmt.Errorf("ошибка получения лектора: %w", err)
+			}
+		}
+
+		lessonName := req.LessonName
+		if lessonName == "" {
+			lessonName = subjectName
+		}
+
+		p.Requirements = append(p.Requirements, timetable.Requirement{
+			GroupID:        req.GroupID,
+			SubjectID:      req.SubjectID,
+			TeacherID:      teacherID,
+			LessonName:     lessonName,
+			LessonsPerWeek: req.LessonsPerWeek,
+			GroupSize:      groupSize,
+		})
+
+		if !seenGroup[req.GroupID] {
+			seenGroup[req.GroupID] = true
+			groupIDs = append(groupIDs, req.GroupID)
+		}
+		if !seenTeacher[teacherID] {
+			seenTeacher[teacherID] = true
+			teacherIDs = append(teacherIDs, teacherID)
+		}
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT teacher_id, day_of_week, TO_CHAR(start_time, 'HH24:MI'), TO_CHAR(end_time, 'HH24:MI'), preferred
+		FROM teacher_availability
+		WHERE teacher_id = ANY($1)
+		ORDER BY teacher_id, day_of_week, start_time
+	`, teacherIDs)
+	if err != nil {
+		return p, fmt.Errorf("ошибка получения доступности учителей: %w", err)
+	}
+	for rows.Next() {
+		var teacherID int
+		var w timetable.Window
+		if err := rows.Scan(&teacherID, &w.Day, &w.Start, &w.End, &w.Preferred); err != nil {
+			rows.Close()
+			return p, fmt.Errorf("ошибка сканирования доступности учителей: %w", err)
+		}
+		p.Availability[teacherID] = append(p.Availability[teacherID], w)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return p, fmt.Errorf("ошибка итерации доступности учителей: %w", err)
+	}
+
+	if useRooms {
+		rooms, err := r.queryRooms(ctx, `WHERE active`)
+		if err != nil {
+			return p, err
+		}
+		for _, room := range rooms {
+			p.Rooms = append(p.Rooms, timetable.Room{ID: room.RoomID, Capacity: room.Capacity})
+		}
+	}
+
+	// занятия остальных групп остаются как есть и занимают своих учителей и аудитории
+	rows, err = r.db.Query(ctx, `
+		SELECT
+			s.day_of_week,
+			TO_CHAR(s.start_time, 'HH24:MI'),
+			TO_CHAR(s.end_time, 'HH24:MI'),
+			COALESCE(ta.teacher_id, 0),
+			COALESCE(s.room_id, 0)
+		FROM schedule s
+		LEFT JOIN teacher_assignments ta
+			ON ta.subject_id = s.subject_id AND ta.group_id = s.group_id AND ta.term = $2
+		WHERE (s.group_id IS NULL OR NOT s.group_id = ANY($1))
+			AND s.day_of_week IS NOT NULL
+			AND s.start_time IS NOT NULL
+			AND s.end_time IS NOT NULL
+		ORDER BY s.schedule_id
+	`, groupIDs, term)
+	if err != nil {
+		return p, fmt.Errorf("ошибка получения текущего расписания: %w", err)
+	}
+	for rows.Next() {
+		var b timetable.Busy
+		if err := rows.Scan(&b.Day, &b.Start, &b.End, &b.TeacherID, &b.RoomID); err != nil {
+			rows.Close()
+			return p, fmt.Errorf("ошибка сканирования текущего расписания: %w", err)
+		}
+		p.Busy = append(p.Busy, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return p, fmt.Errorf("ошибка итерации текущего расписания: %w", err)
+	}
+
+	return p, nil
+}
+
+func (r *Repository) CreateTimetableDraft(ctx context.Context, draft *models.TimetableDraft) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO timetable_drafts (term, seed, penalty, steps, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING draft_id, status, created_at
+	`, draft.Term, draft.Seed, draft.Penalty, draft.Steps, draft.CreatedBy).Scan(
+		&draft.DraftID,
+		&draft.Status,
+		&draft.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка создания черновика расписания: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for _, l := range draft.Lessons {
+		batch.Queue(`
+			INSERT INTO timetable_draft_lessons
+				(draft_id, group_id, subject_id, teacher_id, lesson_name, day_of_week, start_time, end_time, room_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7::time, $8::time, $9)
+		`, draft.DraftID, l.GroupID, l.SubjectID, l.TeacherID, l.LessonName, l.DayOfWeek, l.StartTime, l.EndTime, l.RoomID)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("ошибка сохранения занятий черновика: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+const draftColumns = `draft_id, term, seed, status, penalty, steps, COALESCE(created_by, 0), created_at, published_at`
+
+func scanDraft(row pgx.Row) (*models.TimetableDraft, error) {
+	var d models.TimetableDraft
+	err := row.Scan(
+		&d.DraftID,
+		&d.Term,
+		&d.Seed,
+		&d.Status,
+		&d.Penalty,
+		&d.Steps,
+		&d.CreatedBy,
+		&d.CreatedAt,
+		&d.PublishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *Repository) ListTimetableDrafts(ctx context.Context, status string) ([]models.TimetableDraft, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+draftColumns+`
+		FROM timetable_drafts
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC
+	`, status)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения черновиков расписания: %w", err)
+	}
+	defer rows.Close()
+
+	drafts := []models.TimetableDraft{}
+	for rows.Next() {
+		d, err := scanDraft(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования черновика расписания: %w", err)
+		}
+		drafts = append(drafts, *d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации черновиков расписания: %w", err)
+	}
+
+	return drafts, nil
+}
+
+func (r *Repository) draftLessons(ctx context.Context, q querier, draftID int) ([]models.TimetableDraftLesson, error) {
+	rows, err := q.Query(ctx, `
+		SELECT
+			l.group_id,
+			l.subject_id,
+			sub.subject_name,
+			l.teacher_id,
+			l.lesson_name,
+			l.day_of_week,
+			TO_CHAR(l.start_time, 'HH24:MI'),
+			TO_CHAR(l.end_time, 'HH24:MI'),
+			l.room_id
+		FROM timetable_draft_lessons l
+		JOIN subjects sub ON sub.subject_id = l.subject_id
+		WHERE l.draft_id = $1
+		ORDER BY l.group_id, l.day_of_week, l.start_time
+	`, draftID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения занятий черновика: %w", err)
+	}
+
+	lessons, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.TimetableDraftLesson])
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сканирования занятий черновика: %w", err)
+	}
+	return lessons, nil
+}
+
+func (r *Repository) GetTimetableDraft(ctx context.Context, draftID int) (*models.TimetableDraft, error) {
+	d, err := scanDraft(r.db.QueryRow(ctx, `SELECT `+draftColumns+` FROM timetable_drafts WHERE draft_id = $1`, draftID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("черновик расписания с ID %d не найден", draftID)
+		}
+		return nil, fmt.Errorf("ошибка получения черновика расписания: %w", err)
+	}
+
+	d.Lessons, err = r.draftLessons(ctx, r.db, draftID)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// lockDraft блокирует черновик и проверяет, что он ещё не опубликован и не отклонён.
+func lockDraft(ctx context.Context, tx pgx.Tx, draftID int) error {
+	var status string
+	err := tx.QueryRow(ctx, `SELECT status FROM timetable_drafts WHERE draft_id = $1 FOR UPDATE`, draftID).Scan(&status)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("черновик расписания с ID %d не найден", draftID)
+		}
+		return fmt.Errorf("ошибка получения черновика расписания: %w", err)
+	}
+	if status != models.DraftStatusDraft {
+		return fmt.Errorf("черновик расписания %d уже в статусе %s", draftID, status)
+	}
+	return nil
+}
+
+// PublishTimetableDraft заменяет расписание групп черновика. Занятия, по которым уже
+// отмечалась посещаемость, не удаляются — такая публикация отклоняется.
+func (r *Repository) PublishTimetableDraft(ctx context.Context, draftID int) ([]models.ScheduleEntry, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockDraft(ctx, tx, draftID); err != nil {
+		return nil, err
+	}
+
+	lessons, err := r.draftLessons(ctx, tx, draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupIDs := []int{}
+	seen := map[int]bool{}
+	for _, l := range lessons {
+		if !seen[l.GroupID] {
+			seen[l.GroupID] = true
+			groupIDs = append(groupIDs, l.GroupID)
+		}
+	}
+
+	var hasAttendance bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM attendance a
+			JOIN schedule s ON s.schedule_id = a.schedule_id
+			WHERE s.group_id = ANY($1)
+		)
+	`, groupIDs).Scan(&hasAttendance)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки посещаемости: %w", err)
+	}
+	if hasAttendance {
+		return nil, fmt.Errorf("по текущему расписанию групп уже есть отметки посещаемости")
+	}
+
+	removed, err := r.queryScheduleEntries(ctx, tx, `WHERE s.group_id = ANY($1)`, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM schedule WHERE group_id = ANY($1)`, groupIDs); err != nil {
+		return nil, fmt.Errorf("ошибка удаления прежнего расписания: %w", err)
+	}
+	for _, entry := range removed {
+		if err := insertOutboxEvent(ctx, tx, models.EventScheduleChanged, models.ScheduleChangedEvent{
+			Action:        "deleted",
+			ScheduleEntry: entry,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]int, 0, len(lessons))
+	for _, l := range lessons {
+		// аудитории могли занять после генерации черновика
+		if l.RoomID != nil {
+			if err := checkRoom(ctx, tx, *l.RoomID, l.GroupID, l.DayOfWeek, l.StartTime, l.EndTime, 0); err != nil {
+				return nil, err
+			}
+		}
+
+		var id int
+		err := tx.QueryRow(ctx, `
+			INSERT INTO schedule (group_id, subject_id, lesson_name, day_of_week, start_time, end_time, room_id)
+			VALUES ($1, $2, $3, $4, $5::time, $6::time, $7)
+			RETURNING schedule_id
+		`, l.GroupID, l.SubjectID, l.LessonName, l.DayOfWeek, l.StartTime, l.EndTime, l.RoomID).Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка создания занятия: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	entries, err := r.queryScheduleEntries(ctx, tx, `WHERE s.schedule_id = ANY($1)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if err := insertOutboxEvent(ctx, tx, models.EventScheduleChanged, models.ScheduleChangedEvent{
+			Action:        "created",
+			ScheduleEntry: entry,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE timetable_drafts
+		SET status = $1, published_at = CURRENT_TIMESTAMP
+		WHERE draft_id = $2
+	`, models.DraftStatusPublished, draftID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка обновления черновика расписания: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *Repository) DiscardTimetableDraft(ctx context.Context, draftID int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockDraft(ctx, tx, draftID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE timetable_drafts SET status = $1 WHERE draft_id = $2`, models.DraftStatusDiscarded, draftID)
+	if err != nil {
+		return fmt.Errorf("ошибка отклонения черновика расписания: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
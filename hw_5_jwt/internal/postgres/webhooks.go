@@ -0,0 +1,288 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"hw_5_jwt/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// insertOutboxEvent пишет событие в outbox в той же транзакции, что и само изменение,
+// поэтому событие не теряется при падении процесса до отправки вебхуков.
+func insertOutboxEvent(ctx context.Context, tx pgx.Tx, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации события %s: %w", eventType, err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO outbox_events (event_type, payload)
+		VALUES ($1, $2)
+	`, eventType, data)
+	if err != nil {
+		return fmt.Errorf("ошибка записи события %s: %w", eventType, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) CreateWebhookSubscription(ctx context.Context, sub *models.WebhookSubscription, createdBy int) error {
+	query := `
+		INSERT INTO webhook_subscriptions (url, secret, events, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING subscription_id, active, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query, sub.URL, sub.Secret, sub.Events, createdBy).Scan(
+		&sub.SubscriptionID,
+		&sub.Active,
+		&sub.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка создания подписки: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	query := `
+		SELECT subscription_id, url, events, active, created_at
+		FROM webhook_subscriptions
+		ORDER BY subscription_id
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения подписок: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []models.WebhookSubscription{}
+	for rows.Next() {
+		var sub models.WebhookSubscription
+		if err := rows.Scan(&sub.SubscriptionID, &sub.URL, &sub.Events, &sub.Active, &sub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования подписки: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации подписок: %w", err)
+	}
+
+	return subs, nil
+}
+
+func (r *Repository) DeactivateWebhookSubscription(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, `UPDATE webhook_subscriptions SET active = false WHERE subscription_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка отключения подписки: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("подписка с ID %d не найдена", id)
+	}
+	return nil
+}
+
+func (r *Repository) GetWebhookDeliveries(ctx context.Context, subscriptionID int, status string) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT
+			d.delivery_id,
+			d.event_id,
+			d.subscription_id,
+			e.event_type,
+			d.status,
+			d.attempts,
+			d.next_attempt_at,
+			d.last_status_code,
+			d.last_error,
+			d.created_at,
+			d.updated_at
+		FROM webhook_deliveries d
+		JOIN outbox_events e ON e.event_id = d.event_id
+		WHERE d.subscription_id = $1 AND ($2 = '' OR d.status = $2)
+		ORDER BY d.delivery_id DESC
+		LIMIT 500
+	`
+
+	rows, err := r.db.Query(ctx, query, subscriptionID, status)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения доставок: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		err := rows.Scan(
+			&d.DeliveryID,
+			&d.EventID,
+			&d.SubscriptionID,
+			&d.EventType,
+			&d.Status,
+			&d.Attempts,
+			&d.NextAttemptAt,
+			&d.LastStatusCode,
+			&d.LastError,
+			&d.CreatedAt,
+			&d.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования доставки: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации доставок: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RedeliverWebhook ставит доставку в очередь заново с обнулённым счётчиком попыток.
+func (r *Repository) RedeliverWebhook(ctx context.Context, deliveryID int64) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE delivery_id = $1
+	`, deliveryID)
+	if err != nil {
+		return fmt.Errorf("ошибка повторной доставки: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("доставка с ID %d не найдена", deliveryID)
+	}
+	return nil
+}
+
+// FanOutOutboxEvents создаёт доставки для новых событий outbox по активным подпискам
+// и помечает события разобранными. Выполняется одним запросом, поэтому атомарно.
+func (r *Repository) FanOutOutboxEvents(ctx context.Context, limit int) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		WITH batch AS (
+			SELECT event_id, event_type
+			FROM outbox_events
+			WHERE dispatched_at IS NULL
+			ORDER BY event_id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		),
+		deliveries AS (
+			INSERT INTO webhook_deliveries (event_id, subscription_id)
+			SELECT b.event_id, s.subscription_id
+			FROM batch b
+			JOIN webhook_subscriptions s ON s.active AND b.event_type = ANY(s.events)
+			ON CONFLICT (event_id, subscription_id) DO NOTHING
+		)
+		UPDATE outbox_events
+		SET dispatched_at = CURRENT_TIMESTAMP
+		WHERE event_id IN (SELECT event_id FROM batch)
+	`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка разбора outbox: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ClaimDueWebhooks захватывает готовые к отправке доставки, сдвигая next_attempt_at на lease:
+// если процесс упадёт во время отправки, доставка вернётся в очередь после истечения lease.
+func (r *Repository) ClaimDueWebhooks(ctx context.Context, limit int, lease time.Duration) ([]models.OutboundWebhook, error) {
+	query := `
+		WITH due AS (
+			SELECT d.delivery_id
+			FROM webhook_deliveries d
+			JOIN webhook_subscriptions s ON s.subscription_id = d.subscription_id AND s.active
+			WHERE d.status = 'pending' AND d.next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY d.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2),
+			attempts = d.attempts + 1,
+			updated_at = CURRENT_TIMESTAMP
+		FROM due, webhook_subscriptions s, outbox_events e
+		WHERE d.delivery_id = due.delivery_id
+			AND s.subscription_id = d.subscription_id
+			AND e.event_id = d.event_id
+		RETURNING d.delivery_id, d.event_id, d.attempts, s.url, s.secret, e.event_type, e.payload, e.created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка захвата доставок: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []models.OutboundWebhook
+	for rows.Next() {
+		var w models.OutboundWebhook
+		err := rows.Scan(
+			&w.DeliveryID,
+			&w.EventID,
+			&w.Attempts,
+			&w.URL,
+			&w.Secret,
+			&w.EventType,
+			&w.Payload,
+			&w.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования доставки: %w", err)
+		}
+		webhooks = append(webhooks, w)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации доставок: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// RecordWebhookAttempt сохраняет результат попытки в журнал и обновляет состояние доставки.
+func (r *Repository) RecordWebhookAttempt(ctx context.Context, deliveryID int64, status string, statusCode int, errMsg string, duration time.Duration, nextAttemptAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var code *int
+	if statusCode > 0 {
+		code = &statusCode
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, status_code, error, duration_ms)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+	`, deliveryID, code, errMsg, duration.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("ошибка записи попытки доставки: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2,
+			last_status_code = $3,
+			last_error = NULLIF($4, ''),
+			next_attempt_at = $5,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE delivery_id = $1
+	`, deliveryID, status, code, errMsg, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления доставки: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return nil
+}
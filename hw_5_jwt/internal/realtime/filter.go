@@ -0,0 +1,61 @@
+package realtime
+
+import "hw_5_jwt/internal/models"
+
+// Filter ограничивает поток предметом, группой и занятием (schedule_id и дата);
+// нулевые поля не ограничивают.
+type Filter struct {
+	SubjectID  int
+	GroupID    int
+	ScheduleID int
+	VisitDay   string
+}
+
+func (f Filter) Match(event models.AttendanceStreamEvent) bool {
+	return (f.SubjectID == 0 || f.SubjectID == event.SubjectID) &&
+		(f.GroupID == 0 || f.GroupID == event.GroupID) &&
+		(f.ScheduleID == 0 || f.ScheduleID == event.ScheduleID) &&
+		(f.VisitDay == "" || f.VisitDay == event.VisitDay)
+}
+
+// Lesson — предмет в группе, на который назначен учитель.
+type Lesson struct {
+	SubjectID int
+	GroupID   int
+}
+
+// Scope — события, которые пользователь вправе видеть: администратор — все, учитель —
+// назначенные ему предметы в группах и курируемые группы, студент — только свои отметки.
+type Scope struct {
+	All       bool
+	StudentID int
+	Groups    map[int]bool
+	Lessons   map[Lesson]bool
+}
+
+func (s Scope) Allows(event models.AttendanceStreamEvent) bool {
+	switch {
+	case s.All:
+		return true
+	case s.StudentID != 0:
+		return event.StudentID == s.StudentID
+	}
+	return s.Groups[event.GroupID] || s.Lessons[Lesson{SubjectID: event.SubjectID, GroupID: event.GroupID}]
+}
+
+// Covers сообщает, может ли под фильтр попасть хоть одно видимое событие. Подписка
+// на чужой предмет или группу отклоняется сразу, а не превращается в пустой поток.
+func (s Scope) Covers(f Filter) bool {
+	if s.All || s.StudentID != 0 {
+		return true
+	}
+	if f.GroupID == 0 && len(s.Groups) > 0 || s.Groups[f.GroupID] {
+		return true
+	}
+	for lesson := range s.Lessons {
+		if (f.SubjectID == 0 || f.SubjectID == lesson.SubjectID) && (f.GroupID == 0 || f.GroupID == lesson.GroupID) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/postgres"
+)
+
+const (
+	// bufferSize — сколько событий ждут отправки медленному клиенту; при переполнении
+	// поток закрывается, и клиент догоняет пропущенное по Last-Event-ID
+	bufferSize  = 256
+	ReplayLimit = 500
+	minRetry    = time.Second
+	maxRetry    = 30 * time.Second
+)
+
+// Hub раздаёт события посещаемости подписчикам этого экземпляра API. События
+// приходят из Postgres через LISTEN, поэтому отметка, сделанная на любом
+// экземпляре, доходит до клиентов всех экземпляров.
+type Hub struct {
+	repo   *postgres.Repository
+	logger *slog.Logger
+
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+// Subscription — поток событий одного клиента. Канал Events закрывается, если
+// клиент не успевает читать или подписка отменена.
+type Subscription struct {
+	filter Filter
+	scope  Scope
+	events chan models.AttendanceStreamEvent
+}
+
+func (s *Subscription) Events() <-chan models.AttendanceStreamEvent {
+	return s.events
+}
+
+func NewHub(repo *postgres.Repository, logger *slog.Logger) *Hub {
+	return &Hub{repo: repo, logger: logger, subs: make(map[*Subscription]struct{})}
+}
+
+// Run слушает канал Postgres и переподключается с растущей задержкой, пока не отменён ctx.
+// Во время переподключения события не теряются для клиентов с Last-Event-ID.
+func (h *Hub) Run(ctx context.Context) {
+	delay := minRetry
+	for {
+		started := time.Now()
+		err := h.repo.ListenAttendanceEvents(ctx, h.handle)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > maxRetry {
+			delay = minRetry
+		}
+		h.logger.Error("обрыв подписки на события посещаемости", "error", err, "retry_in", delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRetry)
+	}
+}
+
+func (h *Hub) handle(payload []byte) {
+	var event models.AttendanceStreamEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		h.logger.Error("ошибка разбора события посещаемости", "error", err)
+		return
+	}
+	h.Publish(event)
+}
+
+func (h *Hub) Subscribe(filter Filter, scope Scope) *Subscription {
+	sub := &Subscription{filter: filter, scope: scope, events: make(chan models.AttendanceStreamEvent, bufferSize)}
+
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// Unsubscribe отменяет подписку; повторный вызов ничего не делает.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(sub)
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.events)
+	}
+}
+
+// Close закрывает все подписки, чтобы открытые потоки завершились до остановки сервера.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		h.remove(sub)
+	}
+}
+
+// Publish отправляет событие подписчикам, которым оно видно и подходит под фильтр.
+// Отправка не блокируется: переполненная подписка закрывается.
+func (h *Hub) Publish(event models.AttendanceStreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs {
+		if !sub.scope.Allows(event) || !sub.filter.Match(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			h.logger.Warn("подписчик не успевает за событиями посещаемости", "event_id", event.EventID)
+			h.remove(sub)
+		}
+	}
+}
+
+// Replay возвращает видимые подписке события после afterID, не больше ReplayLimit.
+func (h *Hub) Replay(ctx context.Context, sub *Subscription, afterID int64) ([]models.AttendanceStreamEvent, error) {
+	events, err := h.repo.GetAttendanceStreamEvents(ctx, afterID, ReplayLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := events[:0]
+	for _, event := range events {
+		if sub.scope.Allows(event) && sub.filter.Match(event) {
+			visible = append(visible, event)
+		}
+	}
+	return visible, nil
+}
@@ -0,0 +1,53 @@
+package terms
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bounds возвращает границы семестра по ключу вида "2025-fall" (1 сентября — 31 января)
+// или "2026-spring" (1 февраля — 31 августа). Границы совпадают с For и term_of в
+// schema.sql: летние месяцы входят в весенний семестр, иначе отметки за них выпадали
+// бы из статистики и оповещений по семестру.
+func Bounds(term string) (time.Time, time.Time, error) {
+	yearStr, season, ok := strings.Cut(term, "-")
+	year, err := strconv.Atoi(yearStr)
+	if !ok || err != nil || year < 2000 || year > 2100 {
+		return time.Time{}, time.Time{}, fmt.Errorf("неверный формат семестра: %s", term)
+	}
+
+	switch season {
+	case "fall":
+		return time.Date(year, time.September, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(year+1, time.January, 31, 0, 0, 0, 0, time.UTC), nil
+	case "spring":
+		return time.Date(year, time.February, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(year, time.August, 31, 0, 0, 0, 0, time.UTC), nil
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("неверный формат семестра: %s", term)
+}
+
+// Teaching возвращает период занятий по расписанию: весной — до 30 июня, летние
+// месяцы семестра в календарь не попадают.
+func Teaching(term string) (time.Time, time.Time, error) {
+	from, to, err := Bounds(term)
+	if err == nil && strings.HasSuffix(term, "-spring") {
+		to = time.Date(from.Year(), time.June, 30, 0, 0, 0, 0, time.UTC)
+	}
+	return from, to, err
+}
+
+// For возвращает ключ семестра, в который попадает дата; летние месяцы относятся к весеннему.
+func For(t time.Time) string {
+	switch {
+	case t.Month() >= time.September:
+		return fmt.Sprintf("%d-fall", t.Year())
+	case t.Month() == time.January:
+		return fmt.Sprintf("%d-fall", t.Year()-1)
+	default:
+		return fmt.Sprintf("%d-spring", t.Year())
+	}
+}
@@ -0,0 +1,682 @@
+// Package timetable строит недельное расписание по требованиям к занятиям.
+// Решатель работает полностью офлайн и при одном и том же seed даёт один и тот же результат.
+package timetable
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Штрафы мягких ограничений.
+const (
+	penaltyGap          = 3 // окно между занятиями группы
+	penaltyNotPreferred = 2 // занятие не в предпочтительный день учителя
+	penaltySameSubject  = 2 // второе занятие по предмету в тот же день
+)
+
+// DefaultMaxSteps ограничивает перебор, чтобы генерация не зависала на больших задачах.
+const DefaultMaxSteps = 200000
+
+type Period struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// DefaultPeriods — пары по 90 минут с перерывом 10 минут.
+var DefaultPeriods = []Period{
+	{"08:30", "10:00"},
+	{"10:10", "11:40"},
+	{"11:50", "13:20"},
+	{"14:00", "15:30"},
+	{"15:40", "17:10"},
+	{"17:20", "18:50"},
+}
+
+type Requirement struct {
+	GroupID        int    `json:"group_id"`
+	SubjectID      int    `json:"subject_id"`
+	TeacherID      int    `json:"teacher_id"`
+	LessonName     string `json:"lesson_name"`
+	LessonsPerWeek int    `json:"lessons_per_week"`
+	GroupSize      int    `json:"group_size"`
+}
+
+// Window — интервал, когда учитель может вести занятия.
+type Window struct {
+	Day       int    `json:"day_of_week"`
+	Start     string `json:"start_time"`
+	End       string `json:"end_time"`
+	Preferred bool   `json:"preferred"`
+}
+
+type Room struct {
+	ID       int
+	Capacity int
+}
+
+// Busy — уже существующее занятие вне генерируемых групп, которое занимает учителя или аудиторию.
+type Busy struct {
+	Day       int
+	Start     string
+	End       string
+	TeacherID int
+	RoomID    int
+}
+
+type Problem struct {
+	Days             []int
+	Periods          []Period
+	MaxLessonsPerDay int
+	Requirements     []Requirement
+	// Availability: учитель без окон доступен всегда.
+	Availability map[int][]Window
+	// Rooms: если пусто, аудитории не назначаются.
+	Rooms    []Room
+	Busy     []Busy
+	Seed     int64
+	MaxSteps int
+}
+
+type Lesson struct {
+	Requirement
+	Day    int    `json:"day_of_week"`
+	Start  string `json:"start_time"`
+	End    string `json:"end_time"`
+	RoomID int    `json:"room_id,omitempty"`
+}
+
+// Violation описывает требование, которое не удалось выполнить.
+type Violation struct {
+	GroupID   int    `json:"group_id,omitempty"`
+	SubjectID int    `json:"subject_id,omitempty"`
+	TeacherID int    `json:"teacher_id,omitempty"`
+	Missing   int    `json:"missing"`
+	Reason    string `json:"reason"`
+}
+
+type Result struct {
+	Lessons   []Lesson    `json:"lessons"`
+	Unplaced  []Violation `json:"unplaced"`
+	Penalty   int         `json:"penalty"`
+	Steps     int         `json:"steps"`
+	Exhausted bool        `json:"exhausted"`
+}
+
+func (r Result) Feasible() bool {
+	return len(r.Unplaced) == 0
+}
+
+func minutes(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("неверное время %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+type span struct{ start, end int }
+
+func (s span) overlaps(o span) bool {
+	return s.start < o.end && o.start < s.end
+}
+
+type unit struct {
+	req   int // индекс требования
+	first bool
+}
+
+type solver struct {
+	p       Problem
+	rng     *rand.Rand
+	periods []span
+	nSlots  int
+
+	allowed   map[int][]bool // учитель → доступность слота
+	preferred map[int][]bool // учитель → предпочтительный день (по слоту)
+	hasPref   map[int]bool
+	roomBusy  [][]bool // индекс аудитории → слот
+	fixedT    map[int][]bool
+
+	units  []unit
+	group  map[int][]int // группа → слот → индекс требования+1
+	tBusy  map[int][]bool
+	perDay map[int][]int // группа → день → число занятий
+	placed []placement
+
+	steps     int
+	exhausted bool
+	best      []placement
+	bestCount int
+	bestPen   int
+}
+
+type placement struct {
+	slot int
+	room int // индекс в p.Rooms, -1 без аудитории
+}
+
+func (s *solver) day(slot int) int    { return slot / len(s.periods) }
+func (s *solver) period(slot int) int { return slot % len(s.periods) }
+
+// Solve ищет расписание перебором с возвратом: сначала самые ограниченные требования,
+// варианты упорядочены по штрафу мягких ограничений, равные — по seed.
+func Solve(p Problem) (Result, error) {
+	if p.MaxSteps <= 0 {
+		p.MaxSteps = DefaultMaxSteps
+	}
+	if len(p.Periods) == 0 {
+		p.Periods = DefaultPeriods
+	}
+	if len(p.Days) == 0 {
+		p.Days = []int{1, 2, 3, 4, 5}
+	}
+	if p.MaxLessonsPerDay <= 0 {
+		p.MaxLessonsPerDay = len(p.Periods)
+	}
+
+	s := &solver{
+		p:         p,
+		rng:       rand.New(rand.NewSource(p.Seed)),
+		allowed:   map[int][]bool{},
+		preferred: map[int][]bool{},
+		hasPref:   map[int]bool{},
+		fixedT:    map[int][]bool{},
+		group:     map[int][]int{},
+		tBusy:     map[int][]bool{},
+		perDay:    map[int][]int{},
+		bestCount: -1,
+	}
+	if err := s.prepare(); err != nil {
+		return Result{}, err
+	}
+
+	if violations := s.precheck(); len(violations) > 0 {
+		return Result{Lessons: []Lesson{}, Unplaced: violations}, nil
+	}
+
+	s.orderUnits()
+	s.placed = make([]placement, 0, len(s.units))
+	s.search(0, 0)
+
+	return s.result(), nil
+}
+
+func (s *solver) prepare() error {
+	for _, per := range s.p.Periods {
+		start, err := minutes(per.Start)
+		if err != nil {
+			return err
+		}
+		end, err := minutes(per.End)
+		if err != nil {
+			return err
+		}
+		if start >= end {
+			return fmt.Errorf("пара %s-%s: начало позже конца", per.Start, per.End)
+		}
+		s.periods = append(s.periods, span{start, end})
+	}
+	s.nSlots = len(s.p.Days) * len(s.periods)
+
+	for i, req := range s.p.Requirements {
+		if req.LessonsPerWeek <= 0 {
+			return fmt.Errorf("требование %d: lessons_per_week должен быть больше 0", i+1)
+		}
+		if req.TeacherID <= 0 {
+			return fmt.Errorf("требование %d: не указан учитель", i+1)
+		}
+		if _, ok := s.group[req.GroupID]; !ok {
+			s.group[req.GroupID] = make([]int, s.nSlots)
+			s.perDay[req.GroupID] = make([]int, len(s.p.Days))
+		}
+		if _, ok := s.allowed[req.TeacherID]; ok {
+			continue
+		}
+		if err := s.prepareTeacher(req.TeacherID); err != nil {
+			return err
+		}
+	}
+
+	s.roomBusy = make([][]bool, len(s.p.Rooms))
+	for i := range s.roomBusy {
+		s.roomBusy[i] = make([]bool, s.nSlots)
+	}
+
+	for _, b := range s.p.Busy {
+		start, err := minutes(b.Start)
+		if err != nil {
+			return err
+		}
+		end, err := minutes(b.End)
+		if err != nil {
+			return err
+		}
+		for slot := 0; slot < s.nSlots; slot++ {
+			if s.p.Days[s.day(slot)] != b.Day || !s.periods[s.period(slot)].overlaps(span{start, end}) {
+				continue
+			}
+			if busy, ok := s.fixedT[b.TeacherID]; ok {
+				busy[slot] = true
+			}
+			for i, room := range s.p.Rooms {
+				if room.ID == b.RoomID {
+					s.roomBusy[i][slot] = true
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *solver) prepareTeacher(teacherID int) error {
+	allowed := make([]bool, s.nSlots)
+	preferred := make([]bool, s.nSlots)
+	windows := s.p.Availability[teacherID]
+
+	for slot := range allowed {
+		if len(windows) == 0 {
+			allowed[slot] = true
+			continue
+		}
+		per := s.periods[s.period(slot)]
+		for _, w := range windows {
+			if w.Day != s.p.Days[s.day(slot)] {
+				continue
+			}
+			start, err := minutes(w.Start)
+			if err != nil {
+				return err
+			}
+			end, err := minutes(w.End)
+			if err != nil {
+				return err
+			}
+			if start <= per.start && per.end <= end {
+				allowed[slot] = true
+				preferred[slot] = preferred[slot] || w.Preferred
+			}
+			if w.Preferred {
+				s.hasPref[teacherID] = true
+			}
+		}
+	}
+
+	s.allowed[teacherID] = allowed
+	s.preferred[teacherID] = preferred
+	s.fixedT[teacherID] = make([]bool, s.nSlots)
+	s.tBusy[teacherID] = make([]bool, s.nSlots)
+	return nil
+}
+
+// precheck находит заведомо невыполнимые требования до перебора, чтобы отчёт
+// называл конкретную причину, а не просто «решение не найдено».
+func (s *solver) precheck() []Violation {
+	var violations []Violation
+
+	groupTotal := map[int]int{}
+	teacherTotal := map[int]int{}
+	for _, req := range s.p.Requirements {
+		groupTotal[req.GroupID] += req.LessonsPerWeek
+		teacherTotal[req.TeacherID] += req.LessonsPerWeek
+	}
+
+	capacity := len(s.p.Days) * min(s.p.MaxLessonsPerDay, len(s.periods))
+	for _, groupID := range sortedKeys(groupTotal) {
+		if groupTotal[groupID] > capacity {
+			violations = append(violations, Violation{
+				GroupID: groupID,
+				Missing: groupTotal[groupID] - capacity,
+				Reason: fmt.Sprintf("группе требуется %d занятий в неделю, а при %d днях и не более %d занятий в день доступно %d",
+					groupTotal[groupID], len(s.p.Days), s.p.MaxLessonsPerDay, capacity),
+			})
+		}
+	}
+
+	for _, teacherID := range sortedKeys(teacherTotal) {
+		free := 0
+		for slot := 0; slot < s.nSlots; slot++ {
+			if s.allowed[teacherID][slot] && !s.fixedT[teacherID][slot] {
+				free++
+			}
+		}
+		if teacherTotal[teacherID] > free {
+			violations = append(violations, Violation{
+				TeacherID: teacherID,
+				Missing:   teacherTotal[teacherID] - free,
+				Reason: fmt.Sprintf("учителю требуется провести %d занятий в неделю, а свободных доступных слотов %d",
+					teacherTotal[teacherID], free),
+			})
+		}
+	}
+
+	if len(s.p.Rooms) > 0 {
+		for _, req := range s.p.Requirements {
+			fits := false
+			for _, room := range s.p.Rooms {
+				fits = fits || room.Capacity >= req.GroupSize
+			}
+			if !fits {
+				violations = append(violations, Violation{
+					GroupID:   req.GroupID,
+					SubjectID: req.SubjectID,
+					TeacherID: req.TeacherID,
+					Missing:   req.LessonsPerWeek,
+					Reason:    fmt.Sprintf("нет аудитории на %d мест", req.GroupSize),
+				})
+			}
+		}
+	}
+
+	return violations
+}
+
+func sortedKeys(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// orderUnits раскладывает требования на отдельные занятия: сначала требования
+// с наименьшим числом допустимых слотов на занятие, равные перемешиваются по seed.
+func (s *solver) orderUnits() {
+	order := s.rng.Perm(len(s.p.Requirements))
+	tightness := make([]float64, len(s.p.Requirements))
+	for i, req := range s.p.Requirements {
+		free := 0
+		for slot := 0; slot < s.nSlots; slot++ {
+			if s.allowed[req.TeacherID][slot] && !s.fixedT[req.TeacherID][slot] {
+				free++
+			}
+		}
+		tightness[i] = float64(free) / float64(req.LessonsPerWeek)
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return tightness[order[a]] < tightness[order[b]]
+	})
+
+	for _, i := range order {
+		for k := 0; k < s.p.Requirements[i].LessonsPerWeek; k++ {
+			s.units = append(s.units, unit{req: i, first: k == 0})
+		}
+	}
+}
+
+type candidate struct {
+	placement
+	penalty int
+	tie     int
+}
+
+func (s *solver) candidates(idx int) []candidate {
+	u := s.units[idx]
+	req := s.p.Requirements[u.req]
+
+	// одинаковые занятия одного требования ставятся по возрастанию слота, чтобы не перебирать перестановки
+	from := 0
+	if !u.first {
+		from = s.placed[idx-1].slot + 1
+	}
+
+	var out []candidate
+	for slot := from; slot < s.nSlots; slot++ {
+		if s.reject(req, slot) != "" {
+			continue
+		}
+		room, ok := s.pickRoom(req, slot)
+		if !ok {
+			continue
+		}
+		out = append(out, candidate{
+			placement: placement{slot: slot, room: room},
+			penalty:   s.delta(u.req, slot),
+			tie:       s.rng.Int(),
+		})
+	}
+
+	sort.Slice(out, func(a, b int) bool {
+		if out[a].penalty != out[b].penalty {
+			return out[a].penalty < out[b].penalty
+		}
+		return out[a].tie < out[b].tie
+	})
+	return out
+}
+
+// reject возвращает жёсткое ограничение, нарушаемое слотом, или пустую строку.
+func (s *solver) reject(req Requirement, slot int) string {
+	switch {
+	case s.group[req.GroupID][slot] != 0:
+		return "у группы уже есть занятие"
+	case s.perDay[req.GroupID][s.day(slot)] >= s.p.MaxLessonsPerDay:
+		return "достигнут лимит занятий группы в день"
+	case !s.allowed[req.TeacherID][slot]:
+		return "учитель недоступен"
+	case s.fixedT[req.TeacherID][slot] || s.tBusy[req.TeacherID][slot]:
+		return "учитель занят"
+	}
+	return ""
+}
+
+// pickRoom выбирает самую маленькую свободную аудиторию, вмещающую группу.
+func (s *solver) pickRoom(req Requirement, slot int) (int, bool) {
+	if len(s.p.Rooms) == 0 {
+		return -1, true
+	}
+	best := -1
+	for i, room := range s.p.Rooms {
+		if room.Capacity < req.GroupSize || s.roomBusy[i][slot] {
+			continue
+		}
+		if best < 0 || room.Capacity < s.p.Rooms[best].Capacity ||
+			(room.Capacity == s.p.Rooms[best].Capacity && room.ID < s.p.Rooms[best].ID) {
+			best = i
+		}
+	}
+	return best, best >= 0
+}
+
+func (s *solver) gaps(groupID, day int) int {
+	slots := s.group[groupID]
+	first, last, count := -1, -1, 0
+	for per := range s.periods {
+		if slots[day*len(s.periods)+per] != 0 {
+			if first < 0 {
+				first = per
+			}
+			last = per
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return last - first + 1 - count
+}
+
+// delta — прирост штрафа мягких ограничений при постановке занятия в слот.
+func (s *solver) delta(reqIdx, slot int) int {
+	req := s.p.Requirements[reqIdx]
+	day := s.day(slot)
+	penalty := 0
+
+	before := s.gaps(req.GroupID, day)
+	s.group[req.GroupID][slot] = reqIdx + 1
+	after := s.gaps(req.GroupID, day)
+	s.group[req.GroupID][slot] = 0
+	penalty += (after - before) * penaltyGap
+
+	if s.hasPref[req.TeacherID] && !s.preferred[req.TeacherID][slot] {
+		penalty += penaltyNotPreferred
+	}
+
+	for per := range s.periods {
+		if s.group[req.GroupID][day*len(s.periods)+per] == reqIdx+1 {
+			penalty += penaltySameSubject
+			break
+		}
+	}
+
+	return penalty
+}
+
+func (s *solver) apply(idx int, c placement, on bool) {
+	req := s.p.Requirements[s.units[idx].req]
+	mark := 0
+	if on {
+		mark = s.units[idx].req + 1
+	}
+	s.group[req.GroupID][c.slot] = mark
+	s.tBusy[req.TeacherID][c.slot] = on
+	if c.room >= 0 {
+		s.roomBusy[c.room][c.slot] = on
+	}
+	if on {
+		s.perDay[req.GroupID][s.day(c.slot)]++
+	} else {
+		s.perDay[req.GroupID][s.day(c.slot)]--
+	}
+}
+
+// search обходит варианты в пределах лимита шагов: первое полное решение
+// не останавливает поиск, а дальше ищутся решения с меньшим штрафом.
+func (s *solver) search(idx, penalty int) {
+	if idx > s.bestCount || (idx == s.bestCount && penalty < s.bestPen) {
+		s.best = append(s.best[:0], s.placed...)
+		s.bestCount = idx
+		s.bestPen = penalty
+	}
+	if idx == len(s.units) {
+		return
+	}
+	// полное решение уже найдено, а эта ветвь не лучше
+	if s.bestCount == len(s.units) && penalty >= s.bestPen {
+		return
+	}
+
+	for _, c := range s.candidates(idx) {
+		if s.steps >= s.p.MaxSteps {
+			s.exhausted = true
+			return
+		}
+		s.steps++
+
+		s.apply(idx, c.placement, true)
+		s.placed = append(s.placed, c.placement)
+		s.search(idx+1, penalty+c.penalty)
+		s.placed = s.placed[:len(s.placed)-1]
+		s.apply(idx, c.placement, false)
+	}
+}
+
+func (s *solver) result() Result {
+	// после поиска состояние пустое, в него ставится лучшее найденное решение
+	for i, c := range s.best {
+		s.apply(i, c, true)
+	}
+
+	res := Result{Lessons: []Lesson{}, Unplaced: []Violation{}, Steps: s.steps, Exhausted: s.exhausted}
+	for i, c := range s.best {
+		req := s.p.Requirements[s.units[i].req]
+		per := s.p.Periods[s.period(c.slot)]
+		lesson := Lesson{Requirement: req, Day: s.p.Days[s.day(c.slot)], Start: per.Start, End: per.End}
+		if c.room >= 0 {
+			lesson.RoomID = s.p.Rooms[c.room].ID
+		}
+		res.Lessons = append(res.Lessons, lesson)
+	}
+	res.Penalty = s.penalty()
+
+	sort.Slice(res.Lessons, func(a, b int) bool {
+		x, y := res.Lessons[a], res.Lessons[b]
+		if x.GroupID != y.GroupID {
+			return x.GroupID < y.GroupID
+		}
+		if x.Day != y.Day {
+			return x.Day < y.Day
+		}
+		return x.Start < y.Start
+	})
+
+	missing := map[int]int{}
+	for i := len(s.best); i < len(s.units); i++ {
+		missing[s.units[i].req]++
+	}
+	for _, reqIdx := range sortedKeys(missing) {
+		req := s.p.Requirements[reqIdx]
+		res.Unplaced = append(res.Unplaced, Violation{
+			GroupID:   req.GroupID,
+			SubjectID: req.SubjectID,
+			TeacherID: req.TeacherID,
+			Missing:   missing[reqIdx],
+			Reason:    s.diagnose(req),
+		})
+	}
+
+	return res
+}
+
+// diagnose называет самое частое жёсткое ограничение, из-за которого слоты недоступны.
+func (s *solver) diagnose(req Requirement) string {
+	counts := map[string]int{}
+	var reasons []string
+	for slot := 0; slot < s.nSlots; slot++ {
+		reason := s.reject(req, slot)
+		if reason == "" {
+			if _, ok := s.pickRoom(req, slot); ok {
+				continue
+			}
+			reason = "нет свободной аудитории нужной вместимости"
+		}
+		if counts[reason] == 0 {
+			reasons = append(reasons, reason)
+		}
+		counts[reason]++
+	}
+
+	top := ""
+	for _, r := range reasons {
+		if top == "" || counts[r] > counts[top] {
+			top = r
+		}
+	}
+
+	suffix := "ограничения несовместимы"
+	if s.exhausted {
+		suffix = fmt.Sprintf("поиск остановлен после %d шагов, попробуйте другой seed или ослабьте ограничения", s.steps)
+	}
+	if top == "" {
+		return suffix
+	}
+	return fmt.Sprintf("%s (%d из %d слотов); %s", top, counts[top], s.nSlots, suffix)
+}
+
+func (s *solver) penalty() int {
+	total := 0
+	for groupID, slots := range s.group {
+		for day := range s.p.Days {
+			total += s.gaps(groupID, day) * penaltyGap
+			seen := map[int]bool{}
+			for per := range s.periods {
+				reqIdx := slots[day*len(s.periods)+per]
+				if reqIdx == 0 {
+					continue
+				}
+				if seen[reqIdx] {
+					total += penaltySameSubject
+				}
+				seen[reqIdx] = true
+				if teacherID := s.p.Requirements[reqIdx-1].TeacherID; s.hasPref[teacherID] &&
+					!s.preferred[teacherID][day*len(s.periods)+per] {
+					total += penaltyNotPreferred
+				}
+			}
+		}
+	}
+	return total
+}
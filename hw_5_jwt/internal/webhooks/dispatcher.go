@@ -0,0 +1,166 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"hw_5_jwt/internal/models"
+	"hw_5_jwt/internal/postgres"
+)
+
+const (
+	MaxAttempts  = 8
+	batchSize    = 50
+	claimLease   = 2 * time.Minute
+	baseBackoff  = 30 * time.Second
+	maxBackoff   = 6 * time.Hour
+	requestLimit = 10 * time.Second
+)
+
+// Dispatcher разбирает outbox в доставки по подпискам и отправляет их
+// с подписью HMAC-SHA256 и экспоненциальной задержкой между попытками.
+type Dispatcher struct {
+	repo   *postgres.Repository
+	client *http.Client
+	logger *slog.Logger
+}
+
+func NewDispatcher(repo *postgres.Repository, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		client: &http.Client{Timeout: requestLimit},
+		logger: logger,
+	}
+}
+
+// Sign возвращает подпись тела запроса: hex(HMAC-SHA256(secret, timestamp + "." + body)).
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff возвращает задержку перед следующей попыткой: 30s, 1m, 2m, ... но не больше 6h.
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := baseBackoff << (attempt - 1)
+	if delay <= 0 || delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
+
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Tick(ctx)
+		}
+	}
+}
+
+// Tick выполняет один проход: разбор outbox и отправку готовых доставок.
+func (d *Dispatcher) Tick(ctx context.Context) {
+	if _, err := d.repo.FanOutOutboxEvents(ctx, batchSize); err != nil {
+		d.logger.Error("ошибка разбора outbox", "error", err)
+		return
+	}
+
+	webhooks, err := d.repo.ClaimDueWebhooks(ctx, batchSize, claimLease)
+	if err != nil {
+		d.logger.Error("ошибка захвата доставок вебхуков", "error", err)
+		return
+	}
+
+	for _, w := range webhooks {
+		d.deliver(ctx, w)
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, w models.OutboundWebhook) {
+	started := time.Now()
+	statusCode, err := d.send(ctx, w)
+	duration := time.Since(started)
+
+	status, next, errMsg := "succeeded", time.Now(), ""
+	if err != nil {
+		errMsg = err.Error()
+		status = "pending"
+		next = time.Now().Add(Backoff(w.Attempts))
+		if w.Attempts >= MaxAttempts {
+			status = "dead"
+		}
+		d.logger.Warn("ошибка доставки вебхука",
+			"delivery_id", w.DeliveryID,
+			"event_type", w.EventType,
+			"attempt", w.Attempts,
+			"status", status,
+			"error", err,
+		)
+	} else {
+		d.logger.Info("вебхук доставлен",
+			"delivery_id", w.DeliveryID,
+			"event_type", w.EventType,
+			"attempt", w.Attempts,
+		)
+	}
+
+	if err := d.repo.RecordWebhookAttempt(ctx, w.DeliveryID, status, statusCode, errMsg, duration, next); err != nil {
+		d.logger.Error("ошибка записи результата доставки", "delivery_id", w.DeliveryID, "error", err)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, w models.OutboundWebhook) (int, error) {
+	body, err := json.Marshal(map[string]any{
+		"id":         w.EventID,
+		"type":       w.EventType,
+		"created_at": w.CreatedAt,
+		"data":       json.RawMessage(w.Payload),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("ошибка сериализации события: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", w.EventType)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(w.DeliveryID, 10))
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", "sha256="+Sign(w.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка отправки: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("получатель вернул статус %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}